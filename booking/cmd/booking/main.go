@@ -1,7 +1,10 @@
 package main
 
 import (
+	"Booking_System/common/auth"
+	commonMessaging "Booking_System/common/messaging"
 	"booking/internal/app"
+	"booking/internal/pricing"
 	"booking/internal/repository"
 	"booking/internal/service"
 	grpcTransport "booking/internal/transport/grpc"
@@ -10,11 +13,14 @@ import (
 	"booking/pkg/database"
 	customLogger "booking/pkg/logger"
 	pb "booking/proto"
+	"context"
 	"github.com/gorilla/mux"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	"log"
 	"net"
 	"net/http"
+	"time"
 )
 
 func main() {
@@ -23,32 +29,106 @@ func main() {
 	// Load configuration
 	cfg := app.LoadConfig()
 
+	// Access tokens are signed by clientManage with the shared secret
+	if cfg.JWTSecret == "" {
+		logger.Fatalf("JWT_SECRET must be set")
+	}
+	verifier := auth.NewVerifier(cfg.JWTSecret)
+
 	// Connect to the database
 	db, err := database.ConnectDB(cfg.DBUrl)
 	if err != nil {
 		logger.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	// Initialize repository
+	// Initialize repositories
 	bookingRepo := repository.NewBookingRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
 
-	// Initialize messaging
-	bookingMessaging, err := messaging.NewBookingMessaging(cfg.RabbitMQUrl)
+	// Initialize messaging. The connection is shared by the publisher and the
+	// consumers and reconnects on its own if the broker goes away.
+	rabbit, err := commonMessaging.NewRabbitMQ(cfg.RabbitMQUrl)
 	if err != nil {
 		logger.Fatalf("Failed to connect to RabbitMQ: %v", err)
 	}
+	defer rabbit.Close()
+
+	bookingMessaging, err := messaging.NewBookingMessaging(rabbit)
+	if err != nil {
+		logger.Fatalf("Failed to set up booking publisher: %v", err)
+	}
 	defer func() {
 		if err := bookingMessaging.Close(); err != nil {
-			logger.Fatalf("Failed to close RabbitMQ connection: %v", err)
+			logger.Printf("Failed to close booking publisher: %v", err)
+		}
+	}()
+
+	// Log how the broker answers what we publish
+	go bookingMessaging.LogStats(cfg.PublishStatsInterval)
+
+	// Relay booking events from the outbox to RabbitMQ
+	relay := service.NewOutboxRelay(outboxRepo, bookingMessaging, cfg.OutboxPollInterval, service.DefaultOutboxBatchSize)
+	go relay.Run(context.Background())
+
+	// Connect to clientManage and roomManage to validate booked clients and rooms
+	clientConn, err := grpc.NewClient(cfg.ClientGRPCAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		logger.Fatalf("Failed to create clientManage gRPC client: %v", err)
+	}
+	defer clientConn.Close()
+	roomConn, err := grpc.NewClient(cfg.RoomGRPCAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		logger.Fatalf("Failed to create roomManage gRPC client: %v", err)
+	}
+	defer roomConn.Close()
+	refs := grpcTransport.NewReferenceChecker(clientConn, roomConn, cfg.ReferenceCheckTimeout, cfg.JWTSecret)
+
+	// Load the rate plans stays are priced with
+	ratePlans := pricing.RatePlans{}
+	if cfg.RatePlansFile != "" {
+		ratePlans, err = pricing.LoadRatePlans(cfg.RatePlansFile)
+		if err != nil {
+			logger.Fatalf("Failed to load rate plans: %v", err)
+		}
+	}
+
+	// Initialize services
+	pricingService := service.NewPricingService(refs, ratePlans)
+	bookingService := service.NewBookingService(bookingRepo, refs, pricingService, cfg.IdempotencyKeyTTL, cfg.HoldTTL)
+
+	// Expired idempotency keys are ignored on lookup; clear them out now and then
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := bookingService.PurgeExpiredIdempotencyKeys(); err != nil {
+				logger.Printf("Failed to purge expired idempotency keys: %v", err)
+			}
 		}
 	}()
 
-	// Initialize service
-	bookingService := service.NewBookingService(bookingRepo, bookingMessaging)
+	// Expire holds that were not confirmed in time, releasing their rooms
+	go func() {
+		ticker := time.NewTicker(cfg.HoldSweepInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := bookingService.ExpireHolds(); err != nil {
+				logger.Printf("Failed to expire holds: %v", err)
+			}
+		}
+	}()
+
+	// Cancel upcoming bookings of deleted users and rooms
+	deletionConsumer := messaging.NewDeletionConsumer(rabbit, bookingService)
+	go func() {
+		if err := deletionConsumer.Run(); err != nil {
+			logger.Fatalf("Failed to consume deletion events: %v", err)
+		}
+	}()
 
 	// Initialize gRPC server
 	grpcServer := grpcTransport.NewBookingGRPCServer(bookingService)
-	grpcSrv := grpc.NewServer()
+	grpcSrv := grpc.NewServer(grpc.UnaryInterceptor(verifier.UnaryServerInterceptor()))
 	pb.RegisterBookingServiceServer(grpcSrv, grpcServer)
 
 	// Start gRPC server
@@ -71,13 +151,22 @@ func main() {
 	r.HandleFunc("/bookings", bookingHandler.ListBookings).Methods("GET")
 	r.HandleFunc("/bookings/{book_id}", bookingHandler.GetBooking).Methods("GET")
 	r.HandleFunc("/bookings", bookingHandler.CreateBooking).Methods("POST")
+	r.HandleFunc("/bookings/quote", bookingHandler.QuoteBooking).Methods("POST")
+	r.HandleFunc("/bookings/holds", bookingHandler.HoldRoom).Methods("POST")
 	r.HandleFunc("/bookings/{book_id}", bookingHandler.UpdateBooking).Methods("PUT")
+	r.HandleFunc("/bookings/{book_id}", bookingHandler.PatchBooking).Methods("PATCH")
 	r.HandleFunc("/bookings/{book_id}", bookingHandler.DeleteBooking).Methods("DELETE")
+	r.HandleFunc("/bookings/{book_id}/confirm", bookingHandler.ConfirmBooking).Methods("POST")
+	r.HandleFunc("/bookings/{book_id}/confirm-hold", bookingHandler.ConfirmHold).Methods("POST")
+	r.HandleFunc("/bookings/{book_id}/cancel", bookingHandler.CancelBooking).Methods("POST")
+	r.HandleFunc("/bookings/{book_id}/check-in", bookingHandler.CheckInBooking).Methods("POST")
+	r.HandleFunc("/bookings/{book_id}/check-out", bookingHandler.CheckOutBooking).Methods("POST")
+	r.HandleFunc("/bookings/{book_id}/no-show", bookingHandler.MarkNoShow).Methods("POST")
 
 	// Set up and start HTTP server
 	srv := &http.Server{
 		Addr:    ":" + cfg.Port,
-		Handler: r,
+		Handler: verifier.Middleware(r),
 	}
 
 	logger.Printf("HTTP server is running on port %s", cfg.Port)
@@ -0,0 +1,26 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDurationEnv(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", time.Minute},
+		{"30s", 30 * time.Second},
+		{"soon", time.Minute},
+		{"0s", time.Minute},
+		{"-5s", time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("TEST_INTERVAL", tt.value)
+		assert.Equal(t, tt.want, getDurationEnv("TEST_INTERVAL", time.Minute), tt.value)
+	}
+}
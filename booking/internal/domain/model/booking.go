@@ -9,4 +9,15 @@ type Booking struct {
 	StartDate time.Time `json:"start_date"`
 	EndDate   time.Time `json:"end_date"`
 	Status    string    `json:"status"`
+	// Version starts at 1 and grows with every change to the booking. Updates
+	// that name a version only apply if the booking is still at it.
+	Version int `json:"version"`
+	// TotalPrice is what the stay was quoted at when the booking was made or
+	// its room or dates last changed, in minor units of Currency. Both are
+	// empty if the room had no rate then.
+	TotalPrice int64  `json:"total_price"`
+	Currency   string `json:"currency"`
+	// HoldExpiresAt is set on holds: a held booking that is not confirmed by
+	// then expires and releases the room.
+	HoldExpiresAt *time.Time `json:"hold_expires_at,omitempty"`
 }
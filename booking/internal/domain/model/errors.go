@@ -0,0 +1,30 @@
+package model
+
+import "errors"
+
+var (
+	ErrBookingNotFound   = errors.New("booking not found")
+	ErrBookingOverlap    = errors.New("room is already booked for the requested dates")
+	ErrInvalidDateRange  = errors.New("end date must be after start date")
+	ErrInvalidTransition = errors.New("invalid booking status transition")
+	ErrEditConflict      = errors.New("booking was changed by someone else, please reload it and try again")
+	ErrReadOnlyField     = errors.New("field cannot be changed by an update")
+	ErrHoldExpired       = errors.New("hold has expired")
+
+	// Errors for bookings that reference a client or room which cannot be
+	// booked, and for when clientManage or roomManage could not be asked.
+	ErrClientNotFound       = errors.New("client does not exist")
+	ErrClientInactive       = errors.New("client account is not activated")
+	ErrRoomNotFound         = errors.New("room does not exist")
+	ErrRoomUnavailable      = errors.New("room is not available for booking")
+	ErrReferenceCheckFailed = errors.New("could not verify the booked client and room")
+
+	// Errors for idempotency keys that are malformed, were already used for
+	// a different request or belong to a request that has not finished yet.
+	// ErrIdempotencyKeyExists is the repository's way of saying that a
+	// concurrent request claimed the key first.
+	ErrInvalidIdempotencyKey    = errors.New("idempotency key must be at most 255 characters")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used for a different request")
+	ErrIdempotencyKeyExists     = errors.New("idempotency key already exists")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still in progress, retry it later")
+)
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// MaxIdempotencyKeyLength bounds the idempotency keys clients may send.
+const MaxIdempotencyKeyLength = 255
+
+// IdempotencyKey records the booking a request with an idempotency key
+// created, so that a retry of the request returns it instead of booking again.
+// Keys are scoped to the caller that sent them.
+type IdempotencyKey struct {
+	Owner       string
+	Key         string
+	RequestHash string
+	BookingID   int64
+	// Response is the created booking as JSON.
+	Response  []byte
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// HashBookingRequest fingerprints the fields a client chooses when creating a
+// booking, so that reusing a key for a different booking can be detected.
+func HashBookingRequest(booking *Booking) string {
+	h := sha256.New()
+	for _, field := range []string{
+		strconv.FormatInt(booking.ClientID, 10),
+		strconv.FormatInt(booking.RoomID, 10),
+		booking.StartDate.UTC().Format(time.RFC3339Nano),
+		booking.EndDate.UTC().Format(time.RFC3339Nano),
+	} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// OutboxMessage is an event waiting in the outbox table to be relayed to the
+// message broker.
+type OutboxMessage struct {
+	ID         int64
+	RoutingKey string
+	Payload    []byte
+	Attempts   int
+	CreatedAt  time.Time
+}
@@ -0,0 +1,10 @@
+package model
+
+// Room is what the booking service learns about a room from roomManage.
+type Room struct {
+	ID        int64
+	Type      string
+	BaseRate  int64
+	Currency  string
+	Available bool
+}
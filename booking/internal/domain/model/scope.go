@@ -0,0 +1,24 @@
+package model
+
+// Scope limits the bookings an operation may see or change. Staff get
+// AllBookings; clients get OwnBookings. The zero value matches no booking, so
+// a caller that forgets to set a scope fails closed.
+type Scope struct {
+	All      bool
+	ClientID int64
+}
+
+func AllBookings() Scope {
+	return Scope{All: true}
+}
+
+func OwnBookings(clientID int64) Scope {
+	return Scope{ClientID: clientID}
+}
+
+func (s Scope) Includes(booking *Booking) bool {
+	if s.All {
+		return true
+	}
+	return s.ClientID != 0 && booking.ClientID == s.ClientID
+}
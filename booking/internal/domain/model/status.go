@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+const (
+	StatusPending    = "pending"
+	StatusConfirmed  = "confirmed"
+	StatusCheckedIn  = "checked_in"
+	StatusCheckedOut = "checked_out"
+	StatusCancelled  = "cancelled"
+	StatusNoShow     = "no_show"
+
+	// A held booking reserves the room for a short while, e.g. during
+	// checkout. It becomes pending once confirmed or hold_expired once its
+	// HoldExpiresAt has passed.
+	StatusHeld        = "held"
+	StatusHoldExpired = "hold_expired"
+)
+
+// transitions lists, for every status, the statuses a booking may move to
+// next. Statuses without an entry are terminal.
+var transitions = map[string][]string{
+	StatusHeld:      {StatusPending, StatusCancelled, StatusHoldExpired},
+	StatusPending:   {StatusConfirmed, StatusCancelled},
+	StatusConfirmed: {StatusCheckedIn, StatusCancelled, StatusNoShow},
+	StatusCheckedIn: {StatusCheckedOut},
+}
+
+func CanTransition(from, to string) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusChange records a single lifecycle transition of a booking.
+type StatusChange struct {
+	BookingID  int64     `json:"booking_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  string    `json:"changed_by"`
+	ChangedAt  time.Time `json:"changed_at"`
+	Reason     string    `json:"reason,omitempty"`
+
+	// CorrelationID ties the booking.<status> event to the flow that caused
+	// the change. It is not part of the history.
+	CorrelationID string `json:"-"`
+}
@@ -0,0 +1,58 @@
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// RatePlans holds the rate plan of each room type.
+type RatePlans map[string]*RatePlan
+
+// NewRatePlans validates plans and indexes them by room type. At most one
+// plan may be given per type, including the default plan without one.
+func NewRatePlans(plans []RatePlan) (RatePlans, error) {
+	byType := make(RatePlans, len(plans))
+	for i := range plans {
+		plan := &plans[i]
+		if err := plan.Validate(); err != nil {
+			return nil, err
+		}
+		if _, exists := byType[plan.RoomType]; exists {
+			return nil, fmt.Errorf("%w: more than one plan for room type %q", ErrInvalidRatePlan, plan.RoomType)
+		}
+		byType[plan.RoomType] = plan
+	}
+	return byType, nil
+}
+
+// LoadRatePlans reads a JSON array of rate plans, e.g.
+//
+//	[{"name": "suites", "room_type": "suite", "currency": "EUR",
+//	  "weekday_rate": 18000, "weekend_rate": 21000,
+//	  "seasons": [{"name": "summer", "from": "06-15", "to": "08-31", "weekday_rate": 24000}],
+//	  "stay_discounts": [{"name": "weekly", "min_nights": 7, "percent": 10}],
+//	  "taxes": [{"name": "VAT", "percent": 7}, {"name": "city tax", "per_night": 300}]}]
+func LoadRatePlans(path string) (RatePlans, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var plans []RatePlan
+	if err := json.Unmarshal(data, &plans); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidRatePlan, path, err)
+	}
+	return NewRatePlans(plans)
+}
+
+// For returns the plan for the room type, the default plan if the type has
+// none, or an empty plan that charges the room's base rate.
+func (p RatePlans) For(roomType string) *RatePlan {
+	if plan, ok := p[roomType]; ok {
+		return plan
+	}
+	if plan, ok := p[""]; ok {
+		return plan
+	}
+	return &RatePlan{Name: "base"}
+}
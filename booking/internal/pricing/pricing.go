@@ -0,0 +1,274 @@
+// Package pricing works out what a stay costs. All amounts are in minor
+// units of the currency, e.g. cents, and rounding happens once per charge.
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"time"
+)
+
+// MaxNights is the longest stay Quote prices.
+const MaxNights = 366
+
+const dateLayout = "2006-01-02"
+
+var (
+	// ErrNoRate means there is neither a rate plan nor a base rate for the
+	// room.
+	ErrNoRate          = errors.New("no rate is set for the room")
+	ErrStayTooLong     = fmt.Errorf("stays can be quoted for at most %d nights", MaxNights)
+	ErrInvalidRatePlan = errors.New("invalid rate plan")
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// RatePlan prices the nights in rooms of one type. A zero WeekdayRate means
+// the room's own base rate and currency, so a plan may only add seasons,
+// discounts and taxes.
+type RatePlan struct {
+	Name string `json:"name"`
+	// RoomType is the type the plan applies to. The plan without a type is
+	// used for rooms whose type has no plan of its own.
+	RoomType    string `json:"room_type"`
+	Currency    string `json:"currency"`
+	WeekdayRate int64  `json:"weekday_rate"`
+	// WeekendRate applies to Friday and Saturday nights; zero means the
+	// weekday rate.
+	WeekendRate   int64          `json:"weekend_rate,omitempty"`
+	Seasons       []Season       `json:"seasons,omitempty"`
+	StayDiscounts []StayDiscount `json:"stay_discounts,omitempty"`
+	Taxes         []Tax          `json:"taxes,omitempty"`
+}
+
+// Season replaces the rates of the nights from From to To, both inclusive,
+// every year. Both are "MM-DD"; a season may run over the new year, e.g.
+// from 12-20 to 01-05. The first matching season wins.
+type Season struct {
+	Name        string `json:"name"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	WeekdayRate int64  `json:"weekday_rate"`
+	WeekendRate int64  `json:"weekend_rate,omitempty"`
+}
+
+// StayDiscount takes Percent off the nightly subtotal of stays of at least
+// MinNights nights. Only the largest discount a stay qualifies for is given.
+type StayDiscount struct {
+	Name      string  `json:"name"`
+	MinNights int     `json:"min_nights"`
+	Percent   float64 `json:"percent"`
+}
+
+// Tax is charged as Percent of the discounted subtotal, as a flat amount
+// PerNight such as a city tax, or both.
+type Tax struct {
+	Name     string  `json:"name"`
+	Percent  float64 `json:"percent,omitempty"`
+	PerNight int64   `json:"per_night,omitempty"`
+}
+
+// Night is the price of one night of a stay, named by the date it starts on.
+type Night struct {
+	Date    string `json:"date"`
+	Weekend bool   `json:"weekend"`
+	Season  string `json:"season,omitempty"`
+	Rate    int64  `json:"rate"`
+}
+
+// Charge is a named amount that is taken off (discounts) or added to (taxes)
+// the subtotal.
+type Charge struct {
+	Name   string `json:"name"`
+	Amount int64  `json:"amount"`
+}
+
+// Quote itemises the price of a stay: Total is Subtotal, the sum of the
+// nights, less the discounts plus the taxes.
+type Quote struct {
+	RoomID    int64     `json:"room_id"`
+	RoomType  string    `json:"room_type"`
+	RatePlan  string    `json:"rate_plan"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Currency  string    `json:"currency"`
+	Nights    []Night   `json:"nights"`
+	Subtotal  int64     `json:"subtotal"`
+	Discounts []Charge  `json:"discounts"`
+	Taxes     []Charge  `json:"taxes"`
+	Total     int64     `json:"total"`
+}
+
+// Validate checks the plan.
+func (p *RatePlan) Validate() error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w %q: %s", ErrInvalidRatePlan, p.Name, fmt.Sprintf(format, args...))
+	}
+
+	if p.Name == "" {
+		return fmt.Errorf("%w: name must be provided", ErrInvalidRatePlan)
+	}
+	if p.WeekdayRate < 0 || p.WeekendRate < 0 {
+		return invalid("rates must not be negative")
+	}
+	if p.WeekdayRate == 0 && p.WeekendRate > 0 {
+		return invalid("weekend_rate needs a weekday_rate")
+	}
+	if p.WeekdayRate > 0 && !currencyCode.MatchString(p.Currency) {
+		return invalid("currency must be an ISO 4217 code such as EUR")
+	}
+	for _, season := range p.Seasons {
+		if _, err := parseMonthDay(season.From); err != nil {
+			return invalid("season %q: from: %v", season.Name, err)
+		}
+		if _, err := parseMonthDay(season.To); err != nil {
+			return invalid("season %q: to: %v", season.Name, err)
+		}
+		if season.WeekdayRate <= 0 || season.WeekendRate < 0 {
+			return invalid("season %q: weekday_rate must be positive", season.Name)
+		}
+	}
+	for _, discount := range p.StayDiscounts {
+		if discount.MinNights < 1 || discount.Percent <= 0 || discount.Percent > 100 {
+			return invalid("discount %q: needs min_nights of at least 1 and a percent up to 100", discount.Name)
+		}
+	}
+	for _, tax := range p.Taxes {
+		if tax.Name == "" || tax.Percent < 0 || tax.PerNight < 0 || (tax.Percent == 0 && tax.PerNight == 0) {
+			return invalid("tax %q: needs a name and a positive percent or per_night", tax.Name)
+		}
+	}
+	return nil
+}
+
+// WithBaseRate returns the plan to price a room with the given base rate:
+// the plan itself if it has rates of its own, otherwise a copy that charges
+// the base rate. It fails with ErrNoRate if neither has a rate.
+func (p *RatePlan) WithBaseRate(baseRate int64, currency string) (*RatePlan, error) {
+	if p.WeekdayRate > 0 {
+		return p, nil
+	}
+	if baseRate <= 0 || currency == "" {
+		return nil, ErrNoRate
+	}
+	plan := *p
+	plan.WeekdayRate = baseRate
+	plan.Currency = currency
+	return &plan, nil
+}
+
+// Quote prices the nights from the day start falls on up to, but not
+// including, the day end falls on, in UTC. A stay that starts and ends on the
+// same day is charged as one night.
+func (p *RatePlan) Quote(start, end time.Time) (*Quote, error) {
+	if p.WeekdayRate <= 0 {
+		return nil, ErrNoRate
+	}
+
+	first := startOfDay(start)
+	nights := int(startOfDay(end).Sub(first).Hours() / 24)
+	if nights < 1 {
+		nights = 1
+	}
+	if nights > MaxNights {
+		return nil, ErrStayTooLong
+	}
+
+	quote := &Quote{
+		RatePlan:  p.Name,
+		StartDate: start,
+		EndDate:   end,
+		Currency:  p.Currency,
+		Nights:    make([]Night, nights),
+		Discounts: []Charge{},
+		Taxes:     []Charge{},
+	}
+	for i := range quote.Nights {
+		quote.Nights[i] = p.night(first.AddDate(0, 0, i))
+		quote.Subtotal += quote.Nights[i].Rate
+	}
+
+	discounted := quote.Subtotal
+	if discount := p.stayDiscount(nights); discount != nil {
+		amount := percentOf(quote.Subtotal, discount.Percent)
+		quote.Discounts = append(quote.Discounts, Charge{Name: discount.Name, Amount: amount})
+		discounted -= amount
+	}
+
+	quote.Total = discounted
+	for _, tax := range p.Taxes {
+		amount := percentOf(discounted, tax.Percent) + tax.PerNight*int64(nights)
+		quote.Taxes = append(quote.Taxes, Charge{Name: tax.Name, Amount: amount})
+		quote.Total += amount
+	}
+	return quote, nil
+}
+
+func (p *RatePlan) night(date time.Time) Night {
+	weekend := date.Weekday() == time.Friday || date.Weekday() == time.Saturday
+	night := Night{Date: date.Format(dateLayout), Weekend: weekend}
+
+	weekdayRate, weekendRate := p.WeekdayRate, p.WeekendRate
+	if season := p.season(date); season != nil {
+		night.Season = season.Name
+		weekdayRate, weekendRate = season.WeekdayRate, season.WeekendRate
+	}
+
+	night.Rate = weekdayRate
+	if weekend && weekendRate > 0 {
+		night.Rate = weekendRate
+	}
+	return night
+}
+
+func (p *RatePlan) season(date time.Time) *Season {
+	day := int(date.Month())*100 + date.Day()
+	for i := range p.Seasons {
+		season := &p.Seasons[i]
+		from, errFrom := parseMonthDay(season.From)
+		to, errTo := parseMonthDay(season.To)
+		if errFrom != nil || errTo != nil {
+			continue
+		}
+		if from <= to {
+			if day >= from && day <= to {
+				return season
+			}
+		} else if day >= from || day <= to {
+			return season
+		}
+	}
+	return nil
+}
+
+func (p *RatePlan) stayDiscount(nights int) *StayDiscount {
+	var best *StayDiscount
+	for i := range p.StayDiscounts {
+		discount := &p.StayDiscounts[i]
+		if nights >= discount.MinNights && (best == nil || discount.Percent > best.Percent) {
+			best = discount
+		}
+	}
+	return best
+}
+
+// parseMonthDay turns "MM-DD" into MM*100+DD.
+func parseMonthDay(value string) (int, error) {
+	// 2024 is a leap year, so 02-29 is accepted.
+	t, err := time.Parse(dateLayout, "2024-"+value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a MM-DD date", value)
+	}
+	return int(t.Month())*100 + t.Day(), nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func percentOf(amount int64, percent float64) int64 {
+	return int64(math.Round(float64(amount) * percent / 100))
+}
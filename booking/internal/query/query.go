@@ -0,0 +1,391 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// ErrInvalidQuery is wrapped by every error Parse returns, so transports can
+// answer with 400 / InvalidArgument.
+var ErrInvalidQuery = errors.New("invalid query")
+
+type Operator string
+
+const (
+	OpEq  Operator = "eq"
+	OpGt  Operator = "gt"
+	OpGte Operator = "gte"
+	OpLt  Operator = "lt"
+	OpLte Operator = "lte"
+	OpIn  Operator = "in"
+)
+
+var sqlOperators = map[Operator]string{
+	OpEq:  "=",
+	OpGt:  ">",
+	OpGte: ">=",
+	OpLt:  "<",
+	OpLte: "<=",
+}
+
+type fieldType int
+
+const (
+	typeInt fieldType = iota
+	typeTime
+	typeString
+)
+
+// fields is the safelist of columns that may be filtered and sorted on. Only
+// names from this map ever reach the SQL text; values are always bound as
+// parameters.
+var fields = map[string]fieldType{
+	"id":         typeInt,
+	"client_id":  typeInt,
+	"room_id":    typeInt,
+	"start_date": typeTime,
+	"end_date":   typeTime,
+	"status":     typeString,
+}
+
+// Filter is a raw key/value pair as sent by a client, e.g.
+// {"start_date[gte]", "2024-06-01"} or {"status[in]", "pending,confirmed"}.
+// A key without an operator means equality.
+type Filter struct {
+	Key   string
+	Value string
+}
+
+type Condition struct {
+	Field    string
+	Operator Operator
+	Values   []interface{}
+}
+
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// Query is a validated listing request.
+type Query struct {
+	Conditions []Condition
+	Sort       []Sort
+	Offset     int
+	Limit      int
+
+	// after holds the sort values of the last row of the previous page when
+	// the query continues from a cursor.
+	after []interface{}
+}
+
+// Metadata describes a page of results. NextCursor is empty on the last page.
+type Metadata struct {
+	TotalRecords int    `json:"total_records"`
+	PageSize     int    `json:"page_size"`
+	NextCursor   string `json:"next_cursor,omitempty"`
+}
+
+// cursor is the decoded form of the opaque next_cursor token. It remembers
+// the sort it was issued for, so it cannot be replayed against another order.
+type cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+// Parse validates filters against the safelist and turns them into a Query.
+// sort is a comma separated list of fields, each optionally prefixed with "-"
+// for descending order, e.g. "-start_date,id". Results are always ordered by
+// id last so paging is stable.
+func Parse(filters []Filter, sort string, offset, limit int) (*Query, error) {
+	q := &Query{Offset: offset, Limit: limit}
+
+	if q.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", ErrInvalidQuery)
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultLimit
+	}
+	if q.Limit < 0 || q.Limit > MaxLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxLimit)
+	}
+
+	for _, filter := range filters {
+		condition, err := parseCondition(filter)
+		if err != nil {
+			return nil, err
+		}
+		q.Conditions = append(q.Conditions, condition)
+	}
+
+	sorts, err := parseSort(sort)
+	if err != nil {
+		return nil, err
+	}
+	q.Sort = sorts
+
+	return q, nil
+}
+
+// LegacySort turns the older sort_by/sort_order pair into a sort spec for
+// Parse. A sort_by that already is a spec is returned unchanged.
+func LegacySort(sortBy, sortOrder string) (string, error) {
+	switch strings.ToLower(sortOrder) {
+	case "", "asc":
+		return sortBy, nil
+	case "desc":
+		if sortBy == "" || strings.ContainsAny(sortBy, ",-") {
+			return sortBy, nil
+		}
+		return "-" + sortBy, nil
+	default:
+		return "", fmt.Errorf("%w: sort_order must be asc or desc", ErrInvalidQuery)
+	}
+}
+
+func parseCondition(filter Filter) (Condition, error) {
+	field, op := filter.Key, OpEq
+	if i := strings.IndexByte(filter.Key, '['); i >= 0 {
+		if !strings.HasSuffix(filter.Key, "]") {
+			return Condition{}, fmt.Errorf("%w: malformed filter %q", ErrInvalidQuery, filter.Key)
+		}
+		field, op = filter.Key[:i], Operator(filter.Key[i+1:len(filter.Key)-1])
+	}
+
+	typ, ok := fields[field]
+	if !ok {
+		return Condition{}, fmt.Errorf("%w: unknown filter field %q", ErrInvalidQuery, field)
+	}
+	if _, ok := sqlOperators[op]; !ok && op != OpIn {
+		return Condition{}, fmt.Errorf("%w: unknown operator %q", ErrInvalidQuery, op)
+	}
+	if typ == typeString && op != OpEq && op != OpIn {
+		return Condition{}, fmt.Errorf("%w: %s only supports eq and in", ErrInvalidQuery, field)
+	}
+
+	raw := []string{filter.Value}
+	if op == OpIn {
+		raw = strings.Split(filter.Value, ",")
+	}
+
+	condition := Condition{Field: field, Operator: op}
+	for _, value := range raw {
+		parsed, err := parseValue(typ, strings.TrimSpace(value))
+		if err != nil {
+			return Condition{}, fmt.Errorf("%w: %s: %v", ErrInvalidQuery, filter.Key, err)
+		}
+		condition.Values = append(condition.Values, parsed)
+	}
+
+	return condition, nil
+}
+
+func parseValue(typ fieldType, value string) (interface{}, error) {
+	switch typ {
+	case typeInt:
+		return strconv.ParseInt(value, 10, 64)
+	case typeTime:
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t, nil
+		}
+		return time.Parse("2006-01-02", value)
+	default:
+		return value, nil
+	}
+}
+
+func parseSort(spec string) ([]Sort, error) {
+	var sorts []Sort
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		sort := Sort{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if _, ok := fields[sort.Field]; !ok {
+			return nil, fmt.Errorf("%w: unknown sort field %q", ErrInvalidQuery, sort.Field)
+		}
+		if seen[sort.Field] {
+			return nil, fmt.Errorf("%w: duplicate sort field %q", ErrInvalidQuery, sort.Field)
+		}
+		seen[sort.Field] = true
+		sorts = append(sorts, sort)
+	}
+
+	if !seen["id"] {
+		sorts = append(sorts, Sort{Field: "id"})
+	}
+	return sorts, nil
+}
+
+// FilterWhere returns the WHERE clause for the filters alone (without the
+// keyword, empty if there are none) with placeholders numbered from $1, and
+// the matching arguments. It is what total counts are computed over.
+func (q *Query) FilterWhere() (string, []interface{}) {
+	clauses, args := q.filterClauses()
+	return strings.Join(clauses, " AND "), args
+}
+
+// Where is FilterWhere plus, when the query continues from a cursor, the
+// keyset condition that skips everything up to and including the last row
+// of the previous page.
+func (q *Query) Where() (string, []interface{}) {
+	clauses, args := q.filterClauses()
+	if len(q.after) > 0 {
+		var keyset string
+		keyset, args = q.keysetClause(args)
+		clauses = append(clauses, keyset)
+	}
+	return strings.Join(clauses, " AND "), args
+}
+
+// keysetClause expands the row comparison for a sort with mixed directions:
+// (a > $1) OR (a = $1 AND b < $2) OR (a = $1 AND b = $2 AND id > $3).
+func (q *Query) keysetClause(args []interface{}) (string, []interface{}) {
+	placeholders := make([]string, len(q.Sort))
+	for i := range q.Sort {
+		args = append(args, q.after[i])
+		placeholders[i] = "$" + strconv.Itoa(len(args))
+	}
+
+	branches := make([]string, len(q.Sort))
+	for i, sort := range q.Sort {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, q.Sort[j].Field+" = "+placeholders[j])
+		}
+		op := " > "
+		if sort.Desc {
+			op = " < "
+		}
+		parts = append(parts, sort.Field+op+placeholders[i])
+		branches[i] = "(" + strings.Join(parts, " AND ") + ")"
+	}
+
+	return "(" + strings.Join(branches, " OR ") + ")", args
+}
+
+func (q *Query) filterClauses() ([]string, []interface{}) {
+	var clauses []string
+	var args []interface{}
+
+	for _, condition := range q.Conditions {
+		if condition.Operator == OpIn {
+			placeholders := make([]string, len(condition.Values))
+			for i, value := range condition.Values {
+				args = append(args, value)
+				placeholders[i] = "$" + strconv.Itoa(len(args))
+			}
+			clauses = append(clauses, condition.Field+" IN ("+strings.Join(placeholders, ", ")+")")
+			continue
+		}
+
+		args = append(args, condition.Values[0])
+		clauses = append(clauses, condition.Field+" "+sqlOperators[condition.Operator]+" $"+strconv.Itoa(len(args)))
+	}
+
+	return clauses, args
+}
+
+// OrderBy returns the ORDER BY list, without the keyword.
+func (q *Query) OrderBy() string {
+	parts := make([]string, len(q.Sort))
+	for i, sort := range q.Sort {
+		direction := "ASC"
+		if sort.Desc {
+			direction = "DESC"
+		}
+		parts[i] = sort.Field + " " + direction
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Restrict drops any condition on field and replaces it with field = value.
+// It is used to pin queries to the caller's scope.
+func (q *Query) Restrict(field string, value interface{}) {
+	conditions := q.Conditions[:0:0]
+	for _, condition := range q.Conditions {
+		if condition.Field != field {
+			conditions = append(conditions, condition)
+		}
+	}
+	q.Conditions = append(conditions, Condition{Field: field, Operator: OpEq, Values: []interface{}{value}})
+}
+
+// sortSpec renders the sort back into the form Parse accepts.
+func (q *Query) sortSpec() string {
+	parts := make([]string, len(q.Sort))
+	for i, sort := range q.Sort {
+		parts[i] = sort.Field
+		if sort.Desc {
+			parts[i] = "-" + sort.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// After makes the query continue from a next_cursor token returned with a
+// previous page. An empty token is ignored.
+func (q *Query) After(token string) error {
+	if token == "" {
+		return nil
+	}
+	if q.Offset != 0 {
+		return fmt.Errorf("%w: cursor and offset cannot be combined", ErrInvalidQuery)
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	if c.Sort != q.sortSpec() || len(c.Values) != len(q.Sort) {
+		return fmt.Errorf("%w: cursor does not match the requested sort", ErrInvalidQuery)
+	}
+
+	after := make([]interface{}, len(q.Sort))
+	for i, sort := range q.Sort {
+		value, err := parseValue(fields[sort.Field], c.Values[i])
+		if err != nil {
+			return fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+		}
+		after[i] = value
+	}
+	q.after = after
+	return nil
+}
+
+// Cursor returns the token for the page that starts after a row with the
+// given sort values, which must be in the same order as q.Sort.
+func (q *Query) Cursor(values []interface{}) string {
+	c := cursor{Sort: q.sortSpec(), Values: make([]string, len(values))}
+	for i, value := range values {
+		switch v := value.(type) {
+		case time.Time:
+			c.Values[i] = v.Format(time.RFC3339Nano)
+		case int64:
+			c.Values[i] = strconv.FormatInt(v, 10)
+		default:
+			c.Values[i] = fmt.Sprint(v)
+		}
+	}
+
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
@@ -2,17 +2,41 @@ package repository
 
 import (
 	"booking/internal/domain/model"
+	"booking/internal/query"
 	"database/sql"
+	"errors"
+	"github.com/lib/pq"
 	"strconv"
-	"strings"
+	"time"
 )
 
+const (
+	pqUniqueViolation    = "23505"
+	pqExclusionViolation = "23P01"
+	pqCheckViolation     = "23514"
+)
+
+const bookingColumns = `id, client_id, room_id, start_date, end_date, status, version, total_price, currency, hold_expires_at`
+
+// bookingFields returns the scan destinations for bookingColumns.
+func bookingFields(booking *model.Booking) []interface{} {
+	return []interface{}{
+		&booking.ID, &booking.ClientID, &booking.RoomID, &booking.StartDate, &booking.EndDate,
+		&booking.Status, &booking.Version, &booking.TotalPrice, &booking.Currency, &booking.HoldExpiresAt,
+	}
+}
+
 type BookingRepository interface {
 	CreateBooking(booking *model.Booking) error
+	CreateBookingWithKey(booking *model.Booking, key *model.IdempotencyKey) error
+	GetIdempotencyKey(owner, key string) (*model.IdempotencyKey, error)
+	DeleteExpiredIdempotencyKeys() (int64, error)
 	GetBookingByID(id int64) (*model.Booking, error)
 	UpdateBooking(booking *model.Booking) error
 	DeleteBooking(id int64) error
-	ListBookings(offset, limit int, filters map[string]interface{}, sortBy, sortOrder string) ([]*model.Booking, error)
+	TransitionStatus(change *model.StatusChange) error
+	ListBookings(q *query.Query) ([]*model.Booking, query.Metadata, error)
+	ListExpiredHolds(now time.Time, limit int) ([]*model.Booking, error)
 }
 
 type BookingRepositoryImpl struct {
@@ -23,15 +47,43 @@ func NewBookingRepository(db *sql.DB) *BookingRepositoryImpl {
 	return &BookingRepositoryImpl{DB: db}
 }
 
+// CreateBooking inserts the booking and queues its booking.created event in
+// the outbox within the same transaction.
 func (r *BookingRepositoryImpl) CreateBooking(booking *model.Booking) error {
-	query := `INSERT INTO bookings (client_id, room_id, start_date, end_date, status) VALUES ($1, $2, $3, $4, $5) RETURNING id`
-	return r.DB.QueryRow(query, booking.ClientID, booking.RoomID, booking.StartDate, booking.EndDate, booking.Status).Scan(&booking.ID)
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := createBooking(tx, booking); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// createBooking inserts the booking and queues its booking.created event, or
+// booking.held for a hold, as part of tx.
+func createBooking(tx *sql.Tx, booking *model.Booking) error {
+	query := `INSERT INTO bookings (client_id, room_id, start_date, end_date, status, total_price, currency, hold_expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, version`
+	err := tx.QueryRow(query, booking.ClientID, booking.RoomID, booking.StartDate, booking.EndDate, booking.Status, booking.TotalPrice, booking.Currency, booking.HoldExpiresAt).
+		Scan(&booking.ID, &booking.Version)
+	if err != nil {
+		return mapConstraintError(err)
+	}
+
+	routingKey := "booking.created"
+	if booking.Status == model.StatusHeld {
+		routingKey = "booking.held"
+	}
+	return enqueueBookingEvent(tx, routingKey, booking, "")
 }
 
 func (r *BookingRepositoryImpl) GetBookingByID(id int64) (*model.Booking, error) {
-	query := `SELECT id, client_id, room_id, start_date, end_date, status FROM bookings WHERE id = $1`
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE id = $1`
 	var booking model.Booking
-	err := r.DB.QueryRow(query, id).Scan(&booking.ID, &booking.ClientID, &booking.RoomID, &booking.StartDate, &booking.EndDate, &booking.Status)
+	err := r.DB.QueryRow(query, id).Scan(bookingFields(&booking)...)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -41,39 +93,167 @@ func (r *BookingRepositoryImpl) GetBookingByID(id int64) (*model.Booking, error)
 	return &booking, nil
 }
 
+// UpdateBooking changes the client, room, dates and price of a booking, bumps
+// its version and queues a booking.updated event in the outbox, all in one
+// transaction. The status is deliberately left alone; it only moves through
+// TransitionStatus, and a hold keeps its expiry. If booking.Version is set, the update only applies to
+// that version and model.ErrEditConflict is returned if the booking has moved
+// on since.
 func (r *BookingRepositoryImpl) UpdateBooking(booking *model.Booking) error {
-	query := `UPDATE bookings SET client_id = $1, room_id = $2, start_date = $3, end_date = $4, status = $5 WHERE id = $6`
-	_, err := r.DB.Exec(query, booking.ClientID, booking.RoomID, booking.StartDate, booking.EndDate, booking.Status, booking.ID)
-	return err
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE bookings SET client_id = $1, room_id = $2, start_date = $3, end_date = $4,
+			total_price = $5, currency = $6, version = version + 1
+		WHERE id = $7 AND ($8 = 0 OR version = $8)
+		RETURNING ` + bookingColumns
+	err = tx.QueryRow(query, booking.ClientID, booking.RoomID, booking.StartDate, booking.EndDate,
+		booking.TotalPrice, booking.Currency, booking.ID, booking.Version).
+		Scan(bookingFields(booking)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		if booking.Version == 0 {
+			return model.ErrBookingNotFound
+		}
+		var exists bool
+		if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM bookings WHERE id = $1)`, booking.ID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return model.ErrBookingNotFound
+		}
+		return model.ErrEditConflict
+	}
+	if err != nil {
+		return mapConstraintError(err)
+	}
+
+	if err := enqueueBookingEvent(tx, "booking.updated", booking, ""); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
+// DeleteBooking deletes the booking and queues a booking.deleted event with
+// its last state in the same transaction. Deleting a booking that does not
+// exist is not an error.
 func (r *BookingRepositoryImpl) DeleteBooking(id int64) error {
-	query := `DELETE FROM bookings WHERE id = $1`
-	_, err := r.DB.Exec(query, id)
-	return err
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var booking model.Booking
+	err = tx.QueryRow(`DELETE FROM bookings WHERE id = $1 RETURNING `+bookingColumns, id).Scan(bookingFields(&booking)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := enqueueBookingEvent(tx, "booking.deleted", &booking, ""); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *BookingRepositoryImpl) ListBookings(offset, limit int, filters map[string]interface{}, sortBy, sortOrder string) ([]*model.Booking, error) {
-	query := `SELECT id, client_id, room_id, start_date, end_date, status FROM bookings`
-	var whereClauses []string
-	var args []interface{}
-	i := 1
+// TransitionStatus moves a booking from change.FromStatus to change.ToStatus,
+// records the change in the status history and queues a booking.<status>
+// event in the outbox, all in one transaction. If the booking is no longer in
+// FromStatus the transition is rejected.
+func (r *BookingRepositoryImpl) TransitionStatus(change *model.StatusChange) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var booking model.Booking
+	update := `UPDATE bookings SET status = $1, version = version + 1 WHERE id = $2 AND status = $3 RETURNING ` + bookingColumns
+	err = tx.QueryRow(update, change.ToStatus, change.BookingID, change.FromStatus).
+		Scan(bookingFields(&booking)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.ErrInvalidTransition
+	}
+	if err != nil {
+		return mapConstraintError(err)
+	}
+
+	query := `INSERT INTO booking_status_history (booking_id, from_status, to_status, changed_by, reason, changed_at) VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err = tx.Exec(query, change.BookingID, change.FromStatus, change.ToStatus, change.ChangedBy, change.Reason, change.ChangedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := enqueueBookingEvent(tx, "booking."+booking.Status, &booking, change.CorrelationID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ListBookings runs a validated query and returns one page of bookings with
+// its metadata. Column names come from the query package's safelist; every
+// value is bound as a parameter. One extra row is fetched to find out whether
+// another page follows.
+func (r *BookingRepositoryImpl) ListBookings(q *query.Query) ([]*model.Booking, query.Metadata, error) {
+	metadata := query.Metadata{PageSize: q.Limit}
+
+	filterWhere, filterArgs := q.FilterWhere()
+	count := `SELECT count(*) FROM bookings`
+	if filterWhere != "" {
+		count += " WHERE " + filterWhere
+	}
+	if err := r.DB.QueryRow(count, filterArgs...).Scan(&metadata.TotalRecords); err != nil {
+		return nil, metadata, err
+	}
+
+	where, args := q.Where()
+	stmt := `SELECT ` + bookingColumns + ` FROM bookings`
+	if where != "" {
+		stmt += " WHERE " + where
+	}
+	stmt += " ORDER BY " + q.OrderBy()
+	stmt += " LIMIT $" + strconv.Itoa(len(args)+1) + " OFFSET $" + strconv.Itoa(len(args)+2)
+	args = append(args, q.Limit+1, q.Offset)
 
-	for key, value := range filters {
-		whereClauses = append(whereClauses, key+" = $"+strconv.Itoa(i))
-		args = append(args, value)
-		i++
+	rows, err := r.DB.Query(stmt, args...)
+	if err != nil {
+		return nil, metadata, err
 	}
+	defer rows.Close()
 
-	if len(whereClauses) > 0 {
-		query += " WHERE " + strings.Join(whereClauses, " AND ")
+	bookings := []*model.Booking{}
+	for rows.Next() {
+		var booking model.Booking
+		if err := rows.Scan(bookingFields(&booking)...); err != nil {
+			return nil, metadata, err
+		}
+		bookings = append(bookings, &booking)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, metadata, err
 	}
 
-	query += " ORDER BY " + sortBy + " " + sortOrder
-	query += " LIMIT $" + strconv.Itoa(i) + " OFFSET $" + strconv.Itoa(i+1)
-	args = append(args, limit, offset)
+	if len(bookings) > q.Limit {
+		bookings = bookings[:q.Limit]
+		metadata.NextCursor = q.Cursor(sortValues(bookings[len(bookings)-1], q.Sort))
+	}
+	return bookings, metadata, nil
+}
 
-	rows, err := r.DB.Query(query, args...)
+// ListExpiredHolds returns up to limit holds that expired at or before now,
+// the longest expired first.
+func (r *BookingRepositoryImpl) ListExpiredHolds(now time.Time, limit int) ([]*model.Booking, error) {
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE status = $1 AND hold_expires_at <= $2 ORDER BY hold_expires_at, id LIMIT $3`
+	rows, err := r.DB.Query(query, model.StatusHeld, now, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -82,13 +262,48 @@ func (r *BookingRepositoryImpl) ListBookings(offset, limit int, filters map[stri
 	var bookings []*model.Booking
 	for rows.Next() {
 		var booking model.Booking
-		if err := rows.Scan(&booking.ID, &booking.ClientID, &booking.RoomID, &booking.StartDate, &booking.EndDate, &booking.Status); err != nil {
+		if err := rows.Scan(bookingFields(&booking)...); err != nil {
 			return nil, err
 		}
 		bookings = append(bookings, &booking)
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+	return bookings, rows.Err()
+}
+
+// sortValues picks the values of the sort columns from a booking, in sort
+// order, to build the cursor for the next page.
+func sortValues(booking *model.Booking, sorts []query.Sort) []interface{} {
+	values := make([]interface{}, len(sorts))
+	for i, sort := range sorts {
+		switch sort.Field {
+		case "id":
+			values[i] = booking.ID
+		case "client_id":
+			values[i] = booking.ClientID
+		case "room_id":
+			values[i] = booking.RoomID
+		case "start_date":
+			values[i] = booking.StartDate
+		case "end_date":
+			values[i] = booking.EndDate
+		case "status":
+			values[i] = booking.Status
+		}
 	}
-	return bookings, nil
+	return values
+}
+
+// mapConstraintError translates violations of the bookings table constraints
+// into domain errors so callers don't need to know about Postgres error codes.
+func mapConstraintError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case pqExclusionViolation:
+			return model.ErrBookingOverlap
+		case pqCheckViolation:
+			return model.ErrInvalidDateRange
+		}
+	}
+	return err
 }
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"booking/internal/domain/model"
+	"booking/internal/query"
 	"github.com/stretchr/testify/mock"
+	"time"
 )
 
 type BookingRepositoryMock struct {
@@ -14,9 +16,26 @@ func (m *BookingRepositoryMock) CreateBooking(booking *model.Booking) error {
 	return args.Error(0)
 }
 
+func (m *BookingRepositoryMock) CreateBookingWithKey(booking *model.Booking, key *model.IdempotencyKey) error {
+	args := m.Called(booking, key)
+	return args.Error(0)
+}
+
+func (m *BookingRepositoryMock) GetIdempotencyKey(owner, key string) (*model.IdempotencyKey, error) {
+	args := m.Called(owner, key)
+	record, _ := args.Get(0).(*model.IdempotencyKey)
+	return record, args.Error(1)
+}
+
+func (m *BookingRepositoryMock) DeleteExpiredIdempotencyKeys() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (m *BookingRepositoryMock) GetBookingByID(id int64) (*model.Booking, error) {
 	args := m.Called(id)
-	return args.Get(0).(*model.Booking), args.Error(1)
+	booking, _ := args.Get(0).(*model.Booking)
+	return booking, args.Error(1)
 }
 
 func (m *BookingRepositoryMock) UpdateBooking(booking *model.Booking) error {
@@ -29,7 +48,20 @@ func (m *BookingRepositoryMock) DeleteBooking(id int64) error {
 	return args.Error(0)
 }
 
-func (m *BookingRepositoryMock) ListBookings(offset, limit int, filters map[string]interface{}, sortBy, sortOrder string) ([]*model.Booking, error) {
-	args := m.Called(offset, limit, filters, sortBy, sortOrder)
-	return args.Get(0).([]*model.Booking), args.Error(1)
+func (m *BookingRepositoryMock) TransitionStatus(change *model.StatusChange) error {
+	args := m.Called(change)
+	return args.Error(0)
+}
+
+func (m *BookingRepositoryMock) ListBookings(q *query.Query) ([]*model.Booking, query.Metadata, error) {
+	args := m.Called(q)
+	bookings, _ := args.Get(0).([]*model.Booking)
+	metadata, _ := args.Get(1).(query.Metadata)
+	return bookings, metadata, args.Error(2)
+}
+
+func (m *BookingRepositoryMock) ListExpiredHolds(now time.Time, limit int) ([]*model.Booking, error) {
+	args := m.Called(now, limit)
+	bookings, _ := args.Get(0).([]*model.Booking)
+	return bookings, args.Error(1)
 }
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"booking/internal/domain/model"
+	"database/sql"
+	"encoding/json"
+	"errors"
+)
+
+// CreateBookingWithKey creates the booking like CreateBooking and stores key,
+// with the created booking as its response, in the same transaction. The key
+// is claimed before the booking is inserted: a concurrent request with the
+// same key waits on the claim and, once this transaction commits, gets
+// model.ErrIdempotencyKeyExists rather than an overlap with the booking made
+// here. If the owner already holds an unexpired key of that name nothing is
+// created and model.ErrIdempotencyKeyExists is returned.
+func (r *BookingRepositoryImpl) CreateBookingWithKey(booking *model.Booking, key *model.IdempotencyKey) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// An expired key may be used again.
+	_, err = tx.Exec(`DELETE FROM idempotency_keys WHERE owner = $1 AND key = $2 AND expires_at <= NOW()`, key.Owner, key.Key)
+	if err != nil {
+		return err
+	}
+
+	claim := `
+		INSERT INTO idempotency_keys (owner, key, request_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (owner, key) DO NOTHING
+		RETURNING created_at`
+	err = tx.QueryRow(claim, key.Owner, key.Key, key.RequestHash, key.ExpiresAt).Scan(&key.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.ErrIdempotencyKeyExists
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := createBooking(tx, booking); err != nil {
+		return err
+	}
+
+	key.BookingID = booking.ID
+	key.Response, err = json.Marshal(booking)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE idempotency_keys SET booking_id = $3, response = $4 WHERE owner = $1 AND key = $2`,
+		key.Owner, key.Key, key.BookingID, key.Response)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetIdempotencyKey returns nil if the owner holds no unexpired key of that
+// name.
+func (r *BookingRepositoryImpl) GetIdempotencyKey(owner, key string) (*model.IdempotencyKey, error) {
+	query := `SELECT owner, key, request_hash, booking_id, response, created_at, expires_at FROM idempotency_keys WHERE owner = $1 AND key = $2 AND expires_at > NOW()`
+	var record model.IdempotencyKey
+	err := r.DB.QueryRow(query, owner, key).Scan(&record.Owner, &record.Key, &record.RequestHash, &record.BookingID, &record.Response, &record.CreatedAt, &record.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// DeleteExpiredIdempotencyKeys removes the keys past their expiry and returns
+// how many there were.
+func (r *BookingRepositoryImpl) DeleteExpiredIdempotencyKeys() (int64, error) {
+	result, err := r.DB.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
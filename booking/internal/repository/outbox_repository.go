@@ -0,0 +1,105 @@
+package repository
+
+import (
+	commonMessaging "Booking_System/common/messaging"
+	"booking/internal/domain/model"
+	"database/sql"
+)
+
+type OutboxRepository interface {
+	ProcessPending(limit int, handle func(msg *model.OutboxMessage) error) (int, error)
+}
+
+type OutboxRepositoryImpl struct {
+	DB *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepositoryImpl {
+	return &OutboxRepositoryImpl{DB: db}
+}
+
+// ProcessPending locks up to limit messages that are due, hands them to handle
+// in insertion order and marks each one as sent. Rows are locked with SKIP
+// LOCKED so several relays can run side by side without sending the same
+// message twice. On the first failure the message is rescheduled with an
+// exponential backoff and the rest of the batch is left for the next round,
+// which keeps events for the same booking in order. It returns the number of
+// messages sent.
+func (r *OutboxRepositoryImpl) ProcessPending(limit int, handle func(msg *model.OutboxMessage) error) (int, error) {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT id, routing_key, payload, attempts, created_at
+		FROM outbox
+		WHERE sent_at IS NULL AND next_attempt_at <= NOW()
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.Query(query, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var messages []*model.OutboxMessage
+	for rows.Next() {
+		var msg model.OutboxMessage
+		if err := rows.Scan(&msg.ID, &msg.RoutingKey, &msg.Payload, &msg.Attempts, &msg.CreatedAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		messages = append(messages, &msg)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, msg := range messages {
+		if handleErr := handle(msg); handleErr != nil {
+			retry := `
+				UPDATE outbox
+				SET attempts = attempts + 1,
+				    last_error = $2,
+				    next_attempt_at = NOW() + LEAST(interval '1 second' * power(2, attempts), interval '5 minutes')
+				WHERE id = $1`
+			if _, err := tx.Exec(retry, msg.ID, handleErr.Error()); err != nil {
+				return sent, err
+			}
+			break
+		}
+
+		if _, err := tx.Exec(`UPDATE outbox SET sent_at = NOW() WHERE id = $1`, msg.ID); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+
+	return sent, tx.Commit()
+}
+
+// BookingEventVersion is the schema version of the booking payload in booking
+// events.
+const BookingEventVersion = 1
+
+// eventProducer names the booking service in the events it publishes.
+const eventProducer = "booking"
+
+// enqueueBookingEvent writes an event for the booking to the outbox as part of
+// tx, so the event exists if and only if the booking change is committed. The
+// envelope, and with it the event ID, is built here, so relaying the event
+// again after a failure does not change its ID.
+func enqueueBookingEvent(tx *sql.Tx, routingKey string, booking *model.Booking, correlationID string) error {
+	event := commonMessaging.NewEvent(routingKey, BookingEventVersion, eventProducer, booking).CausedBy(correlationID)
+	payload, err := commonMessaging.Encode(event)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO outbox (routing_key, payload) VALUES ($1, $2)`, routingKey, payload)
+	return err
+}
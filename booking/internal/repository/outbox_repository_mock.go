@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"booking/internal/domain/model"
+	"github.com/stretchr/testify/mock"
+)
+
+type OutboxRepositoryMock struct {
+	mock.Mock
+}
+
+// ProcessPending hands the messages configured with On("ProcessPending") to
+// handle, stopping at the first error just like the real implementation.
+func (m *OutboxRepositoryMock) ProcessPending(limit int, handle func(msg *model.OutboxMessage) error) (int, error) {
+	args := m.Called(limit)
+	messages, _ := args.Get(0).([]*model.OutboxMessage)
+	sent := 0
+	for _, msg := range messages {
+		if err := handle(msg); err != nil {
+			break
+		}
+		sent++
+	}
+	return sent, args.Error(1)
+}
@@ -1,47 +1,336 @@
 package service
 
 import (
+	"Booking_System/common/auth"
 	"booking/internal/domain/model"
+	"booking/internal/pricing"
+	"booking/internal/query"
 	"booking/internal/repository"
-	"booking/internal/transport/messaging"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"strconv"
+	"time"
 )
 
+// SystemActor is recorded as the actor of status changes the service makes on
+// its own rather than on behalf of a user.
+const SystemActor = "system"
+
+// BookingService holds the booking business rules. It does not publish events
+// itself: the repository queues them in the outbox together with each change
+// and the OutboxRelay delivers them.
 type BookingService struct {
-	repo      repository.BookingRepository
-	messaging messaging.BookingMessaging
+	repo repository.BookingRepository
+	refs ReferenceChecker
+	// pricing quotes new bookings; without it bookings are made unpriced.
+	pricing *PricingService
+	// idempotencyKeyTTL is how long a retry with the same idempotency key
+	// returns the original booking.
+	idempotencyKeyTTL time.Duration
+	// holdTTL is how long a hold reserves its room.
+	holdTTL time.Duration
+}
+
+// DefaultIdempotencyKeyTTL and DefaultHoldTTL are used when NewBookingService
+// gets a non-positive TTL.
+const (
+	DefaultIdempotencyKeyTTL = 24 * time.Hour
+	DefaultHoldTTL           = 10 * time.Minute
+)
+
+// NewBookingService returns a service that prices new bookings with
+// pricingService, which may be nil where prices do not matter, e.g. in tests.
+func NewBookingService(repo repository.BookingRepository, refs ReferenceChecker, pricingService *PricingService, idempotencyKeyTTL, holdTTL time.Duration) *BookingService {
+	if idempotencyKeyTTL <= 0 {
+		idempotencyKeyTTL = DefaultIdempotencyKeyTTL
+	}
+	if holdTTL <= 0 {
+		holdTTL = DefaultHoldTTL
+	}
+	return &BookingService{repo: repo, refs: refs, pricing: pricingService, idempotencyKeyTTL: idempotencyKeyTTL, holdTTL: holdTTL}
 }
 
-func NewBookingService(repo repository.BookingRepository, messaging messaging.BookingMessaging) *BookingService {
-	return &BookingService{repo: repo, messaging: messaging}
+// ScopeFor returns the bookings an authenticated caller may access: staff see
+// everything, clients only the bookings made under their own user ID.
+func ScopeFor(identity *auth.Identity) model.Scope {
+	if identity.IsStaff() {
+		return model.AllBookings()
+	}
+	return model.OwnBookings(identity.UserID)
 }
 
-func (s *BookingService) CreateBooking(booking *model.Booking) error {
+// CreateBooking stores a new pending booking. Within a client scope the
+// booking is always made for that client, whatever client_id was sent.
+func (s *BookingService) CreateBooking(ctx context.Context, scope model.Scope, booking *model.Booking) error {
+	if err := s.prepareBooking(ctx, scope, booking); err != nil {
+		return err
+	}
+
 	err := s.repo.CreateBooking(booking)
 	if err != nil {
 		log.Printf("Error creating booking: %v", err)
 		return err
 	}
+	return nil
+}
+
+// HoldRoom reserves the room for the booking's dates until the hold TTL runs
+// out. The hold blocks overlapping bookings like a booking does and is priced
+// like one; ConfirmHold turns it into a pending booking. Holds that are not
+// confirmed in time are expired by ExpireHolds.
+func (s *BookingService) HoldRoom(ctx context.Context, scope model.Scope, booking *model.Booking) error {
+	if err := s.prepareBooking(ctx, scope, booking); err != nil {
+		return err
+	}
+	expiresAt := time.Now().UTC().Add(s.holdTTL)
+	booking.Status = model.StatusHeld
+	booking.HoldExpiresAt = &expiresAt
 
-	err = s.messaging.PublishBookingCreated(booking)
+	err := s.repo.CreateBooking(booking)
 	if err != nil {
-		log.Printf("Error publishing booking created message: %v", err)
+		log.Printf("Error creating hold: %v", err)
 		return err
 	}
+	return nil
+}
+
+// ConfirmHold turns a hold into a pending booking at the quoted price. A hold
+// past its expiry fails with model.ErrHoldExpired, even if ExpireHolds has not
+// got to it yet.
+func (s *BookingService) ConfirmHold(scope model.Scope, id int64, actor string) (*model.Booking, error) {
+	booking, err := s.repo.GetBookingByID(id)
+	if err != nil {
+		log.Printf("Error getting booking by ID: %v", err)
+		return nil, err
+	}
+	if booking == nil || !scope.Includes(booking) {
+		return nil, model.ErrBookingNotFound
+	}
+	if booking.Status == model.StatusHeld && booking.HoldExpiresAt != nil && !booking.HoldExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: at %s", model.ErrHoldExpired, booking.HoldExpiresAt.Format(time.RFC3339))
+	}
+	return s.transition(scope, id, model.StatusPending, actor, "", "")
+}
+
+// expireHoldsBatchSize is how many expired holds ExpireHolds loads at a time.
+const expireHoldsBatchSize = 100
+
+// ExpireHolds moves the holds whose expiry has passed to hold_expired, which
+// releases their rooms and publishes booking.hold_expired, and returns how
+// many it expired. Holds confirmed or released meanwhile are skipped.
+func (s *BookingService) ExpireHolds() (int, error) {
+	expired := 0
+	for {
+		holds, err := s.repo.ListExpiredHolds(time.Now().UTC(), expireHoldsBatchSize)
+		if err != nil {
+			log.Printf("Error listing expired holds: %v", err)
+			return expired, err
+		}
+
+		for _, hold := range holds {
+			_, err := s.transition(model.AllBookings(), hold.ID, model.StatusHoldExpired, SystemActor, "hold expired", "")
+			if errors.Is(err, model.ErrInvalidTransition) || errors.Is(err, model.ErrBookingNotFound) {
+				continue
+			}
+			if err != nil {
+				return expired, err
+			}
+			expired++
+		}
+
+		// Expired and skipped holds are no longer held, so a full batch means
+		// there may be more.
+		if len(holds) < expireHoldsBatchSize {
+			return expired, nil
+		}
+	}
+}
+
+// CreateBookingIdempotent is CreateBooking for requests that carry an
+// idempotency key. The first request with a key creates the booking; retries
+// with the same key and the same booking fields get that booking back, with
+// replayed set, until the key expires. Reusing the key for different fields
+// fails with model.ErrIdempotencyKeyReused. Keys are scoped to owner, the
+// caller that sent them. An empty key creates the booking unconditionally.
+func (s *BookingService) CreateBookingIdempotent(ctx context.Context, scope model.Scope, owner, key string, booking *model.Booking) (replayed bool, err error) {
+	if key == "" {
+		return false, s.CreateBooking(ctx, scope, booking)
+	}
+	if len(key) > model.MaxIdempotencyKeyLength {
+		return false, model.ErrInvalidIdempotencyKey
+	}
 
+	if !scope.All {
+		booking.ClientID = scope.ClientID
+	}
+	requestHash := model.HashBookingRequest(booking)
+
+	if replayed, err := s.replay(owner, key, requestHash, booking); replayed || err != nil {
+		return replayed, err
+	}
+
+	if err := s.prepareBooking(ctx, scope, booking); err != nil {
+		return false, err
+	}
+
+	record := &model.IdempotencyKey{
+		Owner:       owner,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   time.Now().UTC().Add(s.idempotencyKeyTTL),
+	}
+	err = s.repo.CreateBookingWithKey(booking, record)
+	if errors.Is(err, model.ErrIdempotencyKeyExists) {
+		// A concurrent request with the same key got there first. If it has
+		// not finished, the caller has to come back for its result.
+		if replayed, err := s.replay(owner, key, requestHash, booking); replayed || err != nil {
+			return replayed, err
+		}
+		return false, model.ErrIdempotencyKeyInProgress
+	}
+	if err != nil {
+		log.Printf("Error creating booking: %v", err)
+		return false, err
+	}
+	return false, nil
+}
+
+// replay fills booking with the result of an earlier request made with the
+// key, if there is one.
+func (s *BookingService) replay(owner, key, requestHash string, booking *model.Booking) (bool, error) {
+	record, err := s.repo.GetIdempotencyKey(owner, key)
+	if err != nil {
+		log.Printf("Error getting idempotency key: %v", err)
+		return false, err
+	}
+	if record == nil {
+		return false, nil
+	}
+	if record.RequestHash != requestHash {
+		return false, model.ErrIdempotencyKeyReused
+	}
+	if len(record.Response) == 0 {
+		return false, model.ErrIdempotencyKeyInProgress
+	}
+	if err := json.Unmarshal(record.Response, booking); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// PurgeExpiredIdempotencyKeys deletes the idempotency keys that no longer
+// protect against retries.
+func (s *BookingService) PurgeExpiredIdempotencyKeys() (int64, error) {
+	return s.repo.DeleteExpiredIdempotencyKeys()
+}
+
+// prepareBooking validates a new booking, prices it and makes it pending,
+// dropping any hold expiry that was sent with it. Within a client scope the
+// booking is always made for that client.
+func (s *BookingService) prepareBooking(ctx context.Context, scope model.Scope, booking *model.Booking) error {
+	if !booking.EndDate.After(booking.StartDate) {
+		return model.ErrInvalidDateRange
+	}
+	if !scope.All {
+		booking.ClientID = scope.ClientID
+	}
+	if err := s.checkAndPrice(ctx, booking); err != nil {
+		return err
+	}
+	booking.Status = model.StatusPending
+	booking.HoldExpiresAt = nil
 	return nil
 }
 
-func (s *BookingService) GetBookingByID(id int64) (*model.Booking, error) {
+// checkAndPrice checks the booked client and room like checkReferences and
+// stores the quoted total and currency on the booking. The room is looked up
+// once, for the check and the quote alike. A room without any rate is booked
+// unpriced rather than refused.
+func (s *BookingService) checkAndPrice(ctx context.Context, booking *model.Booking) error {
+	booking.TotalPrice, booking.Currency = 0, ""
+	if s.pricing == nil {
+		return s.checkReferences(ctx, booking)
+	}
+
+	if err := s.refs.CheckClient(ctx, booking.ClientID); err != nil {
+		log.Printf("Error checking client %d: %v", booking.ClientID, err)
+		return err
+	}
+	room, err := s.pricing.Room(ctx, booking.RoomID)
+	if err != nil {
+		return err
+	}
+	quote, err := s.pricing.QuoteRoom(room, booking.StartDate, booking.EndDate)
+	if errors.Is(err, pricing.ErrNoRate) {
+		log.Printf("Booking room %d without a price: %v", booking.RoomID, err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	booking.TotalPrice = quote.Total
+	booking.Currency = quote.Currency
+	return nil
+}
+
+// QuoteBooking prices the stay a booking asks for without making it. It
+// fails with pricing.ErrNoRate if bookings are not priced at all.
+func (s *BookingService) QuoteBooking(ctx context.Context, booking *model.Booking) (*pricing.Quote, error) {
+	if s.pricing == nil {
+		return nil, pricing.ErrNoRate
+	}
+	return s.pricing.Quote(ctx, booking.RoomID, booking.StartDate, booking.EndDate)
+}
+
+// GetBookingByID returns nil if the booking does not exist or lies outside
+// scope, so clients cannot probe for other clients' bookings.
+func (s *BookingService) GetBookingByID(scope model.Scope, id int64) (*model.Booking, error) {
 	booking, err := s.repo.GetBookingByID(id)
 	if err != nil {
 		log.Printf("Error getting booking by ID: %v", err)
 		return nil, err
 	}
+	if booking == nil || !scope.Includes(booking) {
+		return nil, nil
+	}
 	return booking, nil
 }
 
-func (s *BookingService) UpdateBooking(booking *model.Booking) error {
+// UpdateBooking applies only if the booking is still at booking.Version, unless
+// the version is zero. A stale version fails with model.ErrEditConflict.
+func (s *BookingService) UpdateBooking(ctx context.Context, booking *model.Booking) error {
+	if !booking.EndDate.After(booking.StartDate) {
+		return model.ErrInvalidDateRange
+	}
+	current, err := s.repo.GetBookingByID(booking.ID)
+	if err != nil {
+		log.Printf("Error getting booking by ID: %v", err)
+		return err
+	}
+	if current == nil {
+		return model.ErrBookingNotFound
+	}
+	return s.update(ctx, current, booking)
+}
+
+// update saves the changes made to current as booking. The stay is quoted
+// again if the room or the dates changed; otherwise the booking keeps the
+// price it was quoted.
+func (s *BookingService) update(ctx context.Context, current, booking *model.Booking) error {
+	sameStay := booking.RoomID == current.RoomID &&
+		booking.StartDate.Equal(current.StartDate) && booking.EndDate.Equal(current.EndDate)
+	if sameStay {
+		if err := s.checkReferences(ctx, booking); err != nil {
+			return err
+		}
+		booking.TotalPrice, booking.Currency = current.TotalPrice, current.Currency
+	} else if err := s.checkAndPrice(ctx, booking); err != nil {
+		return err
+	}
+
 	err := s.repo.UpdateBooking(booking)
 	if err != nil {
 		log.Printf("Error updating booking: %v", err)
@@ -50,6 +339,65 @@ func (s *BookingService) UpdateBooking(booking *model.Booking) error {
 	return nil
 }
 
+// PatchBooking loads the booking, lets apply change it and saves it like
+// UpdateBooking. The ID, status, version and price are read-only: the status
+// only moves through the lifecycle, the version is given separately and the
+// price is quoted. version is the one the caller last read; zero means the
+// one loaded here, so a concurrent change between loading and saving is still
+// detected.
+func (s *BookingService) PatchBooking(ctx context.Context, id int64, version int, apply func(booking *model.Booking) error) (*model.Booking, error) {
+	current, err := s.repo.GetBookingByID(id)
+	if err != nil {
+		log.Printf("Error getting booking by ID: %v", err)
+		return nil, err
+	}
+	if current == nil {
+		return nil, model.ErrBookingNotFound
+	}
+
+	booking := *current
+	if err := apply(&booking); err != nil {
+		return nil, err
+	}
+	switch {
+	case booking.ID != current.ID:
+		return nil, fmt.Errorf("%w: id", model.ErrReadOnlyField)
+	case booking.Status != current.Status:
+		return nil, fmt.Errorf("%w: status", model.ErrReadOnlyField)
+	case booking.Version != current.Version:
+		return nil, fmt.Errorf("%w: version", model.ErrReadOnlyField)
+	case booking.TotalPrice != current.TotalPrice:
+		return nil, fmt.Errorf("%w: total_price", model.ErrReadOnlyField)
+	case booking.Currency != current.Currency:
+		return nil, fmt.Errorf("%w: currency", model.ErrReadOnlyField)
+	}
+	if !booking.EndDate.After(booking.StartDate) {
+		return nil, model.ErrInvalidDateRange
+	}
+
+	if version != 0 {
+		booking.Version = version
+	}
+	if err := s.update(ctx, current, &booking); err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
+// checkReferences makes sure the booked client and room exist and can be
+// booked before anything is written.
+func (s *BookingService) checkReferences(ctx context.Context, booking *model.Booking) error {
+	if err := s.refs.CheckClient(ctx, booking.ClientID); err != nil {
+		log.Printf("Error checking client %d: %v", booking.ClientID, err)
+		return err
+	}
+	if err := s.refs.CheckRoom(ctx, booking.RoomID); err != nil {
+		log.Printf("Error checking room %d: %v", booking.RoomID, err)
+		return err
+	}
+	return nil
+}
+
 func (s *BookingService) DeleteBooking(id int64) error {
 	err := s.repo.DeleteBooking(id)
 	if err != nil {
@@ -59,11 +407,135 @@ func (s *BookingService) DeleteBooking(id int64) error {
 	return nil
 }
 
-func (s *BookingService) ListBookings(offset, limit int, filters map[string]interface{}, sortBy, sortOrder string) ([]*model.Booking, error) {
-	bookings, err := s.repo.ListBookings(offset, limit, filters, sortBy, sortOrder)
+// ListBookings lists the bookings in scope. Within a client scope any
+// client_id condition from the caller is replaced by the client's own ID.
+func (s *BookingService) ListBookings(scope model.Scope, q *query.Query) ([]*model.Booking, query.Metadata, error) {
+	if !scope.All {
+		scoped := *q
+		scoped.Restrict("client_id", scope.ClientID)
+		q = &scoped
+	}
+
+	bookings, metadata, err := s.repo.ListBookings(q)
 	if err != nil {
 		log.Printf("Error listing bookings: %v", err)
+		return nil, query.Metadata{}, err
+	}
+	return bookings, metadata, nil
+}
+
+func (s *BookingService) ConfirmBooking(scope model.Scope, id int64, actor string) (*model.Booking, error) {
+	return s.transition(scope, id, model.StatusConfirmed, actor, "", "")
+}
+
+func (s *BookingService) CancelBooking(scope model.Scope, id int64, actor, reason string) (*model.Booking, error) {
+	return s.transition(scope, id, model.StatusCancelled, actor, reason, "")
+}
+
+func (s *BookingService) CheckInBooking(scope model.Scope, id int64, actor string) (*model.Booking, error) {
+	return s.transition(scope, id, model.StatusCheckedIn, actor, "", "")
+}
+
+func (s *BookingService) CheckOutBooking(scope model.Scope, id int64, actor string) (*model.Booking, error) {
+	return s.transition(scope, id, model.StatusCheckedOut, actor, "", "")
+}
+
+func (s *BookingService) MarkNoShow(scope model.Scope, id int64, actor string) (*model.Booking, error) {
+	return s.transition(scope, id, model.StatusNoShow, actor, "", "")
+}
+
+// CancelUpcomingForClient cancels the upcoming bookings of a client, e.g.
+// after the client was deleted, and returns how many were cancelled. The
+// booking.cancelled events carry correlationID, the ID of the flow that
+// caused the cancellation.
+func (s *BookingService) CancelUpcomingForClient(clientID int64, reason, correlationID string) (int, error) {
+	return s.cancelUpcoming(query.Filter{Key: "client_id", Value: strconv.FormatInt(clientID, 10)}, reason, correlationID)
+}
+
+// CancelUpcomingForRoom cancels the upcoming bookings of a room, e.g. after
+// the room was deleted, and returns how many were cancelled.
+func (s *BookingService) CancelUpcomingForRoom(roomID int64, reason, correlationID string) (int, error) {
+	return s.cancelUpcoming(query.Filter{Key: "room_id", Value: strconv.FormatInt(roomID, 10)}, reason, correlationID)
+}
+
+// cancelUpcoming cancels every hold and every pending or confirmed booking
+// matching owner that has not ended yet. Each cancellation goes through the
+// regular lifecycle, so it is recorded with reason and publishes
+// booking.cancelled. Bookings that change status while this runs are skipped.
+func (s *BookingService) cancelUpcoming(owner query.Filter, reason, correlationID string) (int, error) {
+	q, err := query.Parse([]query.Filter{
+		owner,
+		{Key: "status[in]", Value: model.StatusHeld + "," + model.StatusPending + "," + model.StatusConfirmed},
+		{Key: "end_date[gt]", Value: time.Now().UTC().Format(time.RFC3339)},
+	}, "id", 0, query.MaxLimit)
+	if err != nil {
+		return 0, err
+	}
+
+	cancelled := 0
+	for {
+		bookings, metadata, err := s.repo.ListBookings(q)
+		if err != nil {
+			log.Printf("Error listing bookings to cancel: %v", err)
+			return cancelled, err
+		}
+
+		for _, booking := range bookings {
+			_, err := s.transition(model.AllBookings(), booking.ID, model.StatusCancelled, SystemActor, reason, correlationID)
+			if errors.Is(err, model.ErrInvalidTransition) || errors.Is(err, model.ErrBookingNotFound) {
+				continue
+			}
+			if err != nil {
+				return cancelled, err
+			}
+			cancelled++
+		}
+
+		if metadata.NextCursor == "" {
+			return cancelled, nil
+		}
+		if err := q.After(metadata.NextCursor); err != nil {
+			return cancelled, err
+		}
+	}
+}
+
+// transition moves a booking to the given status if the lifecycle allows it,
+// and records who did it. The matching booking.<status> event is queued by the
+// repository in the same transaction, as part of the flow correlationID
+// identifies; an empty correlationID starts a new one.
+func (s *BookingService) transition(scope model.Scope, id int64, to, actor, reason, correlationID string) (*model.Booking, error) {
+	booking, err := s.repo.GetBookingByID(id)
+	if err != nil {
+		log.Printf("Error getting booking by ID: %v", err)
 		return nil, err
 	}
-	return bookings, nil
+	if booking == nil || !scope.Includes(booking) {
+		return nil, model.ErrBookingNotFound
+	}
+
+	if !model.CanTransition(booking.Status, to) {
+		return nil, fmt.Errorf("%w: %s -> %s", model.ErrInvalidTransition, booking.Status, to)
+	}
+
+	change := &model.StatusChange{
+		BookingID:  booking.ID,
+		FromStatus: booking.Status,
+		ToStatus:   to,
+		ChangedBy:  actor,
+		ChangedAt:  time.Now().UTC(),
+		Reason:     reason,
+
+		CorrelationID: correlationID,
+	}
+	err = s.repo.TransitionStatus(change)
+	if err != nil {
+		log.Printf("Error changing booking status: %v", err)
+		return nil, err
+	}
+	// The repository bumps the version together with the status.
+	booking.Status = to
+	booking.Version++
+
+	return booking, nil
 }
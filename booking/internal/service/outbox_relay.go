@@ -0,0 +1,67 @@
+package service
+
+import (
+	"booking/internal/domain/model"
+	"booking/internal/repository"
+	"booking/internal/transport/messaging"
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	DefaultOutboxPollInterval = time.Second
+	DefaultOutboxBatchSize    = 100
+)
+
+// OutboxRelay delivers the events queued in the outbox to RabbitMQ. A message
+// is only marked as sent after the broker accepted it, so every event is
+// delivered at least once; consumers must tolerate duplicates.
+type OutboxRelay struct {
+	outbox       repository.OutboxRepository
+	messaging    messaging.BookingMessaging
+	pollInterval time.Duration
+	batchSize    int
+}
+
+func NewOutboxRelay(outbox repository.OutboxRepository, messaging messaging.BookingMessaging, pollInterval time.Duration, batchSize int) *OutboxRelay {
+	if pollInterval <= 0 {
+		pollInterval = DefaultOutboxPollInterval
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultOutboxBatchSize
+	}
+	return &OutboxRelay{outbox: outbox, messaging: messaging, pollInterval: pollInterval, batchSize: batchSize}
+}
+
+// Run relays pending messages every poll interval until ctx is cancelled.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.RelayPending(); err != nil {
+				log.Printf("Error relaying outbox messages: %v", err)
+			}
+		}
+	}
+}
+
+// RelayPending publishes the messages that are currently due. A full batch
+// means more may be waiting, so it keeps going until a batch comes back short.
+func (r *OutboxRelay) RelayPending() (int, error) {
+	total := 0
+	for {
+		sent, err := r.outbox.ProcessPending(r.batchSize, func(msg *model.OutboxMessage) error {
+			return r.messaging.Publish(msg.RoutingKey, msg.Payload)
+		})
+		total += sent
+		if err != nil || sent < r.batchSize {
+			return total, err
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"booking/internal/domain/model"
+	"booking/internal/pricing"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// RoomCatalog looks up the rooms stays are priced for. Implementations return
+// model.ErrRoomNotFound for a room that does not exist and
+// model.ErrReferenceCheckFailed if roomManage could not be reached.
+type RoomCatalog interface {
+	GetRoom(ctx context.Context, roomID int64) (*model.Room, error)
+}
+
+// PricingService quotes stays from the rate plan of the room's type, falling
+// back to the base rate roomManage keeps for the room.
+type PricingService struct {
+	rooms RoomCatalog
+	plans pricing.RatePlans
+}
+
+func NewPricingService(rooms RoomCatalog, plans pricing.RatePlans) *PricingService {
+	return &PricingService{rooms: rooms, plans: plans}
+}
+
+// Quote prices a stay in the room from start to end. Rooms that cannot be
+// booked are not quoted.
+func (s *PricingService) Quote(ctx context.Context, roomID int64, start, end time.Time) (*pricing.Quote, error) {
+	if !end.After(start) {
+		return nil, model.ErrInvalidDateRange
+	}
+
+	room, err := s.Room(ctx, roomID)
+	if err != nil {
+		return nil, err
+	}
+	return s.QuoteRoom(room, start, end)
+}
+
+// Room looks the room up and requires it to be open for bookings, like
+// ReferenceChecker.CheckRoom does.
+func (s *PricingService) Room(ctx context.Context, roomID int64) (*model.Room, error) {
+	room, err := s.rooms.GetRoom(ctx, roomID)
+	if err != nil {
+		log.Printf("Error getting room %d: %v", roomID, err)
+		return nil, err
+	}
+	if !room.Available {
+		return nil, fmt.Errorf("%w: %d", model.ErrRoomUnavailable, roomID)
+	}
+	return room, nil
+}
+
+// QuoteRoom prices a stay in a room that was already looked up.
+func (s *PricingService) QuoteRoom(room *model.Room, start, end time.Time) (*pricing.Quote, error) {
+	plan, err := s.plans.For(room.Type).WithBaseRate(room.BaseRate, room.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %d", err, room.ID)
+	}
+	quote, err := plan.Quote(start, end)
+	if err != nil {
+		return nil, err
+	}
+	quote.RoomID = room.ID
+	quote.RoomType = room.Type
+	return quote, nil
+}
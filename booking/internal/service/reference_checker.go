@@ -0,0 +1,13 @@
+package service
+
+import "context"
+
+// ReferenceChecker confirms that the client and room a booking points at
+// exist and can be booked. Implementations return model.ErrClientNotFound,
+// model.ErrClientInactive, model.ErrRoomNotFound or model.ErrRoomUnavailable
+// for a bad reference, and model.ErrReferenceCheckFailed if the owning
+// service could not be reached.
+type ReferenceChecker interface {
+	CheckClient(ctx context.Context, clientID int64) error
+	CheckRoom(ctx context.Context, roomID int64) error
+}
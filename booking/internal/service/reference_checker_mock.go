@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type ReferenceCheckerMock struct {
+	mock.Mock
+}
+
+func (m *ReferenceCheckerMock) CheckClient(ctx context.Context, clientID int64) error {
+	args := m.Called(ctx, clientID)
+	return args.Error(0)
+}
+
+func (m *ReferenceCheckerMock) CheckRoom(ctx context.Context, roomID int64) error {
+	args := m.Called(ctx, roomID)
+	return args.Error(0)
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"booking/internal/domain/model"
+	"context"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type RoomCatalogMock struct {
+	mock.Mock
+}
+
+func (m *RoomCatalogMock) GetRoom(ctx context.Context, roomID int64) (*model.Room, error) {
+	args := m.Called(ctx, roomID)
+	room, _ := args.Get(0).(*model.Room)
+	return room, args.Error(1)
+}
@@ -1,10 +1,16 @@
 package grpc
 
 import (
+	"Booking_System/common/auth"
 	"booking/internal/domain/model"
+	"booking/internal/pricing"
+	"booking/internal/query"
 	"booking/internal/service"
 	pb "booking/proto"
 	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -18,7 +24,85 @@ func NewBookingGRPCServer(bookingService *service.BookingService) *BookingGRPCSe
 	return &BookingGRPCServer{bookingService: bookingService}
 }
 
+// toStatusError converts domain errors into gRPC status errors so clients can
+// branch on the code instead of parsing messages.
+func toStatusError(err error) error {
+	switch {
+	case errors.Is(err, model.ErrBookingNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, model.ErrBookingOverlap):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, model.ErrInvalidDateRange), errors.Is(err, query.ErrInvalidQuery),
+		errors.Is(err, model.ErrInvalidIdempotencyKey), errors.Is(err, model.ErrIdempotencyKeyReused),
+		errors.Is(err, model.ErrReadOnlyField), errors.Is(err, pricing.ErrStayTooLong):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, model.ErrInvalidTransition), errors.Is(err, model.ErrHoldExpired):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, model.ErrEditConflict), errors.Is(err, model.ErrIdempotencyKeyInProgress):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, model.ErrClientNotFound), errors.Is(err, model.ErrClientInactive),
+		errors.Is(err, model.ErrRoomNotFound), errors.Is(err, model.ErrRoomUnavailable),
+		errors.Is(err, pricing.ErrNoRate):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, model.ErrReferenceCheckFailed):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return err
+	}
+}
+
+func toBookingResponse(booking *model.Booking) *pb.BookingResponse {
+	resp := &pb.BookingResponse{
+		Id:         booking.ID,
+		ClientId:   booking.ClientID,
+		RoomId:     booking.RoomID,
+		StartDate:  timestamppb.New(booking.StartDate),
+		EndDate:    timestamppb.New(booking.EndDate),
+		Status:     booking.Status,
+		Version:    int32(booking.Version),
+		TotalPrice: booking.TotalPrice,
+		Currency:   booking.Currency,
+	}
+	if booking.HoldExpiresAt != nil {
+		resp.HoldExpiresAt = timestamppb.New(*booking.HoldExpiresAt)
+	}
+	return resp
+}
+
+func toQuote(quote *pricing.Quote) *pb.Quote {
+	resp := &pb.Quote{
+		RoomId:    quote.RoomID,
+		RoomType:  quote.RoomType,
+		RatePlan:  quote.RatePlan,
+		StartDate: timestamppb.New(quote.StartDate),
+		EndDate:   timestamppb.New(quote.EndDate),
+		Currency:  quote.Currency,
+		Subtotal:  quote.Subtotal,
+		Total:     quote.Total,
+	}
+	for _, night := range quote.Nights {
+		resp.Nights = append(resp.Nights, &pb.Night{
+			Date:    night.Date,
+			Weekend: night.Weekend,
+			Season:  night.Season,
+			Rate:    night.Rate,
+		})
+	}
+	for _, discount := range quote.Discounts {
+		resp.Discounts = append(resp.Discounts, &pb.Charge{Name: discount.Name, Amount: discount.Amount})
+	}
+	for _, tax := range quote.Taxes {
+		resp.Taxes = append(resp.Taxes, &pb.Charge{Name: tax.Name, Amount: tax.Amount})
+	}
+	return resp
+}
+
 func (s *BookingGRPCServer) CreateBooking(ctx context.Context, req *pb.CreateBookingRequest) (*pb.BookingResponse, error) {
+	identity, err := auth.Require(ctx, auth.RoleClient, auth.RoleAdmin, auth.RoleOperator)
+	if err != nil {
+		return nil, auth.StatusError(err)
+	}
+
 	booking := &model.Booking{
 		ClientID:  req.ClientId,
 		RoomID:    req.RoomId,
@@ -27,38 +111,91 @@ func (s *BookingGRPCServer) CreateBooking(ctx context.Context, req *pb.CreateBoo
 		Status:    req.Status,
 	}
 
-	err := s.bookingService.CreateBooking(booking)
+	_, err = s.bookingService.CreateBookingIdempotent(ctx, service.ScopeFor(identity), identity.Actor(), req.IdempotencyKey, booking)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
-	return &pb.BookingResponse{
-		Id:        booking.ID,
-		ClientId:  booking.ClientID,
-		RoomId:    booking.RoomID,
-		StartDate: timestamppb.New(booking.StartDate),
-		EndDate:   timestamppb.New(booking.EndDate),
-		Status:    booking.Status,
-	}, nil
+	return toBookingResponse(booking), nil
+}
+
+// HoldRoom reserves a room until the hold TTL runs out.
+func (s *BookingGRPCServer) HoldRoom(ctx context.Context, req *pb.HoldRoomRequest) (*pb.BookingResponse, error) {
+	identity, err := auth.Require(ctx, auth.RoleClient, auth.RoleAdmin, auth.RoleOperator)
+	if err != nil {
+		return nil, auth.StatusError(err)
+	}
+
+	booking := &model.Booking{
+		ClientID:  req.ClientId,
+		RoomID:    req.RoomId,
+		StartDate: req.StartDate.AsTime(),
+		EndDate:   req.EndDate.AsTime(),
+	}
+	if err := s.bookingService.HoldRoom(ctx, service.ScopeFor(identity), booking); err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toBookingResponse(booking), nil
+}
+
+func (s *BookingGRPCServer) ConfirmHold(ctx context.Context, req *pb.BookingTransitionRequest) (*pb.BookingResponse, error) {
+	identity, err := auth.Require(ctx, auth.RoleClient, auth.RoleAdmin, auth.RoleOperator)
+	if err != nil {
+		return nil, auth.StatusError(err)
+	}
+
+	booking, err := s.bookingService.ConfirmHold(service.ScopeFor(identity), req.Id, identity.Actor())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toBookingResponse(booking), nil
+}
+
+// QuoteBooking prices a stay without booking it.
+func (s *BookingGRPCServer) QuoteBooking(ctx context.Context, req *pb.QuoteBookingRequest) (*pb.Quote, error) {
+	if _, err := auth.Require(ctx, auth.RoleClient, auth.RoleAdmin, auth.RoleOperator); err != nil {
+		return nil, auth.StatusError(err)
+	}
+
+	quote, err := s.bookingService.QuoteBooking(ctx, &model.Booking{
+		RoomID:    req.RoomId,
+		StartDate: req.StartDate.AsTime(),
+		EndDate:   req.EndDate.AsTime(),
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toQuote(quote), nil
 }
 
 func (s *BookingGRPCServer) GetBooking(ctx context.Context, req *pb.GetBookingRequest) (*pb.BookingResponse, error) {
-	booking, err := s.bookingService.GetBookingByID(req.Id)
+	identity, err := auth.Require(ctx, auth.RoleClient, auth.RoleAdmin, auth.RoleOperator)
+	if err != nil {
+		return nil, auth.StatusError(err)
+	}
+
+	booking, err := s.bookingService.GetBookingByID(service.ScopeFor(identity), req.Id)
 	if err != nil {
 		return nil, err
 	}
+	if booking == nil {
+		return nil, toStatusError(model.ErrBookingNotFound)
+	}
 
-	return &pb.BookingResponse{
-		Id:        booking.ID,
-		ClientId:  booking.ClientID,
-		RoomId:    booking.RoomID,
-		StartDate: timestamppb.New(booking.StartDate),
-		EndDate:   timestamppb.New(booking.EndDate),
-		Status:    booking.Status,
-	}, nil
+	return toBookingResponse(booking), nil
 }
 
 func (s *BookingGRPCServer) UpdateBooking(ctx context.Context, req *pb.UpdateBookingRequest) (*pb.BookingResponse, error) {
+	if _, err := auth.Require(ctx, auth.RoleAdmin, auth.RoleOperator); err != nil {
+		return nil, auth.StatusError(err)
+	}
+
+	if len(req.GetUpdateMask().GetPaths()) > 0 {
+		return s.patchBooking(ctx, req)
+	}
+
 	booking := &model.Booking{
 		ID:        req.Id,
 		ClientID:  req.ClientId,
@@ -66,24 +203,64 @@ func (s *BookingGRPCServer) UpdateBooking(ctx context.Context, req *pb.UpdateBoo
 		StartDate: req.StartDate.AsTime(),
 		EndDate:   req.EndDate.AsTime(),
 		Status:    req.Status,
+		Version:   int(req.Version),
 	}
 
-	err := s.bookingService.UpdateBooking(booking)
+	err := s.bookingService.UpdateBooking(ctx, booking)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
-	return &pb.BookingResponse{
-		Id:        booking.ID,
-		ClientId:  booking.ClientID,
-		RoomId:    booking.RoomID,
-		StartDate: timestamppb.New(booking.StartDate),
-		EndDate:   timestamppb.New(booking.EndDate),
-		Status:    booking.Status,
-	}, nil
+	return toBookingResponse(booking), nil
+}
+
+// patchBooking changes only the fields named in the request's update mask.
+func (s *BookingGRPCServer) patchBooking(ctx context.Context, req *pb.UpdateBookingRequest) (*pb.BookingResponse, error) {
+	// A masked date left out of the request would otherwise become 1970-01-01.
+	paths := req.UpdateMask.Paths
+	for _, path := range paths {
+		switch path {
+		case "client_id", "room_id":
+		case "start_date":
+			if req.StartDate == nil {
+				return nil, status.Error(codes.InvalidArgument, "start_date is in update_mask but not set")
+			}
+		case "end_date":
+			if req.EndDate == nil {
+				return nil, status.Error(codes.InvalidArgument, "end_date is in update_mask but not set")
+			}
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "update_mask: %q cannot be updated", path)
+		}
+	}
+
+	booking, err := s.bookingService.PatchBooking(ctx, req.Id, int(req.Version), func(booking *model.Booking) error {
+		for _, path := range paths {
+			switch path {
+			case "client_id":
+				booking.ClientID = req.ClientId
+			case "room_id":
+				booking.RoomID = req.RoomId
+			case "start_date":
+				booking.StartDate = req.StartDate.AsTime()
+			case "end_date":
+				booking.EndDate = req.EndDate.AsTime()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return toBookingResponse(booking), nil
 }
 
 func (s *BookingGRPCServer) DeleteBooking(ctx context.Context, req *pb.DeleteBookingRequest) (*emptypb.Empty, error) {
+	if _, err := auth.Require(ctx, auth.RoleAdmin, auth.RoleOperator); err != nil {
+		return nil, auth.StatusError(err)
+	}
+
 	err := s.bookingService.DeleteBooking(req.Id)
 	if err != nil {
 		return nil, err
@@ -92,29 +269,108 @@ func (s *BookingGRPCServer) DeleteBooking(ctx context.Context, req *pb.DeleteBoo
 }
 
 func (s *BookingGRPCServer) ListBookings(ctx context.Context, req *pb.ListBookingsRequest) (*pb.ListBookingsResponse, error) {
-	filters := make(map[string]interface{})
+	identity, err := auth.Require(ctx, auth.RoleClient, auth.RoleAdmin, auth.RoleOperator)
+	if err != nil {
+		return nil, auth.StatusError(err)
+	}
+
+	filters := make([]query.Filter, 0, len(req.Filters))
 	for _, filter := range req.Filters {
-		filters[filter.Key] = filter.Value
+		filters = append(filters, query.Filter{Key: filter.Key, Value: filter.Value})
+	}
+	sortSpec, err := query.LegacySort(req.SortBy, req.SortOrder)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	q, err := query.Parse(filters, sortSpec, int(req.Offset), int(req.Limit))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	if err := q.After(req.Cursor); err != nil {
+		return nil, toStatusError(err)
 	}
 
-	bookings, err := s.bookingService.ListBookings(int(req.Offset), int(req.Limit), filters, req.SortBy, req.SortOrder)
+	bookings, metadata, err := s.bookingService.ListBookings(service.ScopeFor(identity), q)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
 	var bookingResponses []*pb.BookingResponse
 	for _, booking := range bookings {
-		bookingResponses = append(bookingResponses, &pb.BookingResponse{
-			Id:        booking.ID,
-			ClientId:  booking.ClientID,
-			RoomId:    booking.RoomID,
-			StartDate: timestamppb.New(booking.StartDate),
-			EndDate:   timestamppb.New(booking.EndDate),
-			Status:    booking.Status,
-		})
+		bookingResponses = append(bookingResponses, toBookingResponse(booking))
 	}
 
 	return &pb.ListBookingsResponse{
 		Bookings: bookingResponses,
+		Metadata: &pb.Metadata{
+			TotalRecords: int64(metadata.TotalRecords),
+			PageSize:     int64(metadata.PageSize),
+			NextCursor:   metadata.NextCursor,
+		},
 	}, nil
 }
+
+func (s *BookingGRPCServer) ConfirmBooking(ctx context.Context, req *pb.BookingTransitionRequest) (*pb.BookingResponse, error) {
+	identity, err := auth.Require(ctx, auth.RoleAdmin, auth.RoleOperator)
+	if err != nil {
+		return nil, auth.StatusError(err)
+	}
+
+	booking, err := s.bookingService.ConfirmBooking(service.ScopeFor(identity), req.Id, identity.Actor())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toBookingResponse(booking), nil
+}
+
+func (s *BookingGRPCServer) CancelBooking(ctx context.Context, req *pb.BookingTransitionRequest) (*pb.BookingResponse, error) {
+	identity, err := auth.Require(ctx, auth.RoleClient, auth.RoleAdmin, auth.RoleOperator)
+	if err != nil {
+		return nil, auth.StatusError(err)
+	}
+
+	booking, err := s.bookingService.CancelBooking(service.ScopeFor(identity), req.Id, identity.Actor(), req.Reason)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toBookingResponse(booking), nil
+}
+
+func (s *BookingGRPCServer) CheckInBooking(ctx context.Context, req *pb.BookingTransitionRequest) (*pb.BookingResponse, error) {
+	identity, err := auth.Require(ctx, auth.RoleAdmin, auth.RoleOperator)
+	if err != nil {
+		return nil, auth.StatusError(err)
+	}
+
+	booking, err := s.bookingService.CheckInBooking(service.ScopeFor(identity), req.Id, identity.Actor())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toBookingResponse(booking), nil
+}
+
+func (s *BookingGRPCServer) CheckOutBooking(ctx context.Context, req *pb.BookingTransitionRequest) (*pb.BookingResponse, error) {
+	identity, err := auth.Require(ctx, auth.RoleAdmin, auth.RoleOperator)
+	if err != nil {
+		return nil, auth.StatusError(err)
+	}
+
+	booking, err := s.bookingService.CheckOutBooking(service.ScopeFor(identity), req.Id, identity.Actor())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toBookingResponse(booking), nil
+}
+
+func (s *BookingGRPCServer) MarkNoShow(ctx context.Context, req *pb.BookingTransitionRequest) (*pb.BookingResponse, error) {
+	identity, err := auth.Require(ctx, auth.RoleAdmin, auth.RoleOperator)
+	if err != nil {
+		return nil, auth.StatusError(err)
+	}
+
+	booking, err := s.bookingService.MarkNoShow(service.ScopeFor(identity), req.Id, identity.Actor())
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+	return toBookingResponse(booking), nil
+}
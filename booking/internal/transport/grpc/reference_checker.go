@@ -0,0 +1,101 @@
+package grpc
+
+import (
+	"Booking_System/common/auth"
+	"booking/internal/domain/model"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	clientpb "clientManage/proto"
+	roompb "roomManage/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ReferenceChecker implements service.ReferenceChecker and
+// service.RoomCatalog by asking
+// clientManage and roomManage over gRPC. Each call is bounded by timeout so a
+// slow dependency cannot hold a booking request open. clientManage only
+// shows clients to staff and services, so the checker presents a service
+// token signed with secret.
+type ReferenceChecker struct {
+	clients clientpb.ClientManagementServiceClient
+	rooms   roompb.RoomServiceClient
+	timeout time.Duration
+	secret  string
+}
+
+func NewReferenceChecker(clientConn, roomConn grpc.ClientConnInterface, timeout time.Duration, secret string) *ReferenceChecker {
+	return &ReferenceChecker{
+		clients: clientpb.NewClientManagementServiceClient(clientConn),
+		rooms:   roompb.NewRoomServiceClient(roomConn),
+		timeout: timeout,
+		secret:  secret,
+	}
+}
+
+// CheckClient requires the client to exist and to have activated its account.
+func (c *ReferenceChecker) CheckClient(ctx context.Context, clientID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	ctx, err := auth.OutgoingContext(ctx, c.secret)
+	if err != nil {
+		return fmt.Errorf("%w: signing service token: %v", model.ErrReferenceCheckFailed, err)
+	}
+
+	resp, err := c.clients.GetClient(ctx, &clientpb.GetClientRequest{Id: clientID})
+	if status.Code(err) == codes.NotFound {
+		return fmt.Errorf("%w: %d", model.ErrClientNotFound, clientID)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: clientManage: %v", model.ErrReferenceCheckFailed, err)
+	}
+	if !resp.GetClient().GetActivated() {
+		return fmt.Errorf("%w: %d", model.ErrClientInactive, clientID)
+	}
+	return nil
+}
+
+// CheckRoom requires the room to exist and to be open for bookings.
+func (c *ReferenceChecker) CheckRoom(ctx context.Context, roomID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.rooms.GetRoomByID(ctx, &roompb.GetRoomByIDRequest{Id: strconv.FormatInt(roomID, 10)})
+	if status.Code(err) == codes.NotFound {
+		return fmt.Errorf("%w: %d", model.ErrRoomNotFound, roomID)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: roomManage: %v", model.ErrReferenceCheckFailed, err)
+	}
+	if !resp.GetRoom().GetAvailable() {
+		return fmt.Errorf("%w: %d", model.ErrRoomUnavailable, roomID)
+	}
+	return nil
+}
+
+// GetRoom returns what roomManage knows about the room.
+func (c *ReferenceChecker) GetRoom(ctx context.Context, roomID int64) (*model.Room, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.rooms.GetRoomByID(ctx, &roompb.GetRoomByIDRequest{Id: strconv.FormatInt(roomID, 10)})
+	if status.Code(err) == codes.NotFound {
+		return nil, fmt.Errorf("%w: %d", model.ErrRoomNotFound, roomID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: roomManage: %v", model.ErrReferenceCheckFailed, err)
+	}
+	room := resp.GetRoom()
+	return &model.Room{
+		ID:        roomID,
+		Type:      room.GetType(),
+		BaseRate:  room.GetBaseRate(),
+		Currency:  room.GetCurrency(),
+		Available: room.GetAvailable(),
+	}, nil
+}
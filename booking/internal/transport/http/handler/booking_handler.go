@@ -1,11 +1,20 @@
 package handler
 
 import (
+	"Booking_System/common/auth"
+	"Booking_System/common/patch"
 	"booking/internal/domain/model"
+	"booking/internal/pricing"
+	"booking/internal/query"
 	"booking/internal/service"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/gorilla/mux"
+	"io"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 )
 
@@ -17,38 +26,124 @@ func NewBookingHandler(service *service.BookingService) *BookingHandler {
 	return &BookingHandler{service: service}
 }
 
-func getUserRole(r *http.Request) string {
-	return r.Header.Get("Role")
+// serviceErrorStatus maps domain errors returned by the booking service to
+// HTTP status codes. Anything unrecognised is treated as a server error.
+func serviceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, model.ErrBookingNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, model.ErrBookingOverlap), errors.Is(err, model.ErrInvalidTransition),
+		errors.Is(err, model.ErrEditConflict), errors.Is(err, model.ErrHoldExpired),
+		errors.Is(err, model.ErrIdempotencyKeyInProgress):
+		return http.StatusConflict
+	case errors.Is(err, model.ErrInvalidDateRange), errors.Is(err, query.ErrInvalidQuery),
+		errors.Is(err, model.ErrInvalidIdempotencyKey), errors.Is(err, patch.ErrInvalidPatch),
+		errors.Is(err, pricing.ErrStayTooLong):
+		return http.StatusBadRequest
+	case errors.Is(err, model.ErrClientNotFound), errors.Is(err, model.ErrClientInactive),
+		errors.Is(err, model.ErrRoomNotFound), errors.Is(err, model.ErrRoomUnavailable),
+		errors.Is(err, model.ErrIdempotencyKeyReused), errors.Is(err, model.ErrReadOnlyField),
+		errors.Is(err, pricing.ErrNoRate):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, model.ErrReferenceCheckFailed):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
 }
 
+// CreateBooking honours the Idempotency-Key header: retries carrying the key
+// of an earlier successful request get its booking back, marked with
+// Idempotent-Replayed, instead of creating another one. A retry that arrives
+// while that request is still running gets 409 Conflict and should be sent
+// again later.
 func (h *BookingHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
-	role := getUserRole(r)
-	if role != "client" && role != "admin" {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+	identity, err := auth.Require(r.Context(), auth.RoleClient, auth.RoleAdmin, auth.RoleOperator)
+	if err != nil {
+		http.Error(w, err.Error(), auth.HTTPStatus(err))
 		return
 	}
 
 	var booking model.Booking
-	err := json.NewDecoder(r.Body).Decode(&booking)
+	err = json.NewDecoder(r.Body).Decode(&booking)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	err = h.service.CreateBooking(&booking)
+	key := r.Header.Get("Idempotency-Key")
+	replayed, err := h.service.CreateBookingIdempotent(r.Context(), service.ScopeFor(identity), identity.Actor(), key, &booking)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), serviceErrorStatus(err))
+		return
+	}
+
+	if replayed {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+	setETag(w, &booking)
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(booking)
+}
+
+// HoldRoom reserves a room for a few minutes, e.g. while the guest checks out.
+// The body is that of CreateBooking; the hold comes back with its
+// hold_expires_at and is turned into a booking with ConfirmHold.
+func (h *BookingHandler) HoldRoom(w http.ResponseWriter, r *http.Request) {
+	identity, err := auth.Require(r.Context(), auth.RoleClient, auth.RoleAdmin, auth.RoleOperator)
+	if err != nil {
+		http.Error(w, err.Error(), auth.HTTPStatus(err))
+		return
+	}
+
+	var booking model.Booking
+	err = json.NewDecoder(r.Body).Decode(&booking)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	err = h.service.HoldRoom(r.Context(), service.ScopeFor(identity), &booking)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err))
+		return
+	}
+
+	setETag(w, &booking)
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(booking)
 }
 
+// QuoteBooking prices a stay without booking it. The body names the room and
+// the dates like that of CreateBooking; the response itemises the nights,
+// discounts and taxes.
+func (h *BookingHandler) QuoteBooking(w http.ResponseWriter, r *http.Request) {
+	if _, err := auth.Require(r.Context(), auth.RoleClient, auth.RoleAdmin, auth.RoleOperator); err != nil {
+		http.Error(w, err.Error(), auth.HTTPStatus(err))
+		return
+	}
+
+	var booking model.Booking
+	err := json.NewDecoder(r.Body).Decode(&booking)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	quote, err := h.service.QuoteBooking(r.Context(), &booking)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(quote)
+}
+
 func (h *BookingHandler) GetBooking(w http.ResponseWriter, r *http.Request) {
-	role := getUserRole(r)
-	if role != "client" && role != "admin" {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+	identity, err := auth.Require(r.Context(), auth.RoleClient, auth.RoleAdmin, auth.RoleOperator)
+	if err != nil {
+		http.Error(w, err.Error(), auth.HTTPStatus(err))
 		return
 	}
 
@@ -59,7 +154,7 @@ func (h *BookingHandler) GetBooking(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	booking, err := h.service.GetBookingByID(id)
+	booking, err := h.service.GetBookingByID(service.ScopeFor(identity), id)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -70,14 +165,18 @@ func (h *BookingHandler) GetBooking(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, booking)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(booking)
 }
 
+// UpdateBooking rejects the update with 409 Conflict if the booking changed
+// since the client read it. The version the client read is taken from the
+// If-Match header, or else from the version field of the body; without
+// either the update applies unconditionally.
 func (h *BookingHandler) UpdateBooking(w http.ResponseWriter, r *http.Request) {
-	role := getUserRole(r)
-	if role != "admin" {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+	if _, err := auth.Require(r.Context(), auth.RoleAdmin, auth.RoleOperator); err != nil {
+		http.Error(w, err.Error(), auth.HTTPStatus(err))
 		return
 	}
 
@@ -96,20 +195,74 @@ func (h *BookingHandler) UpdateBooking(w http.ResponseWriter, r *http.Request) {
 	}
 	booking.ID = id
 
-	err = h.service.UpdateBooking(&booking)
+	version, err := ifMatchVersion(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if version != 0 {
+		booking.Version = version
+	}
+
+	err = h.service.UpdateBooking(r.Context(), &booking)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err))
 		return
 	}
 
+	setETag(w, &booking)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(booking)
+}
+
+// PatchBooking applies a JSON Merge Patch to the client, room and dates of a
+// booking; members that are left out keep their value. If-Match works as for
+// UpdateBooking.
+func (h *BookingHandler) PatchBooking(w http.ResponseWriter, r *http.Request) {
+	if _, err := auth.Require(r.Context(), auth.RoleAdmin, auth.RoleOperator); err != nil {
+		http.Error(w, err.Error(), auth.HTTPStatus(err))
+		return
+	}
+
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["book_id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := patch.CheckContentType(r); err != nil {
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	version, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	booking, err := h.service.PatchBooking(r.Context(), id, version, func(booking *model.Booking) error {
+		return patch.Apply(booking, body)
+	})
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err))
+		return
+	}
+
+	setETag(w, booking)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(booking)
 }
 
 func (h *BookingHandler) DeleteBooking(w http.ResponseWriter, r *http.Request) {
-	role := getUserRole(r)
-	if role != "admin" {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+	if _, err := auth.Require(r.Context(), auth.RoleAdmin, auth.RoleOperator); err != nil {
+		http.Error(w, err.Error(), auth.HTTPStatus(err))
 		return
 	}
 
@@ -130,36 +283,163 @@ func (h *BookingHandler) DeleteBooking(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *BookingHandler) ListBookings(w http.ResponseWriter, r *http.Request) {
-	role := getUserRole(r)
-	if role != "client" && role != "admin" {
-		http.Error(w, "Forbidden", http.StatusForbidden)
+	identity, err := auth.Require(r.Context(), auth.RoleClient, auth.RoleAdmin, auth.RoleOperator)
+	if err != nil {
+		http.Error(w, err.Error(), auth.HTTPStatus(err))
+		return
+	}
+
+	q, err := parseListQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	bookings, metadata, err := h.service.ListBookings(service.ScopeFor(identity), q)
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err))
 		return
 	}
 
-	query := r.URL.Query()
-	offset, _ := strconv.Atoi(query.Get("offset"))
-	limit, _ := strconv.Atoi(query.Get("limit"))
-	filters := make(map[string]interface{})
-	for key, values := range query {
-		if key != "offset" && key != "limit" && key != "sort_by" && key != "sort_order" {
-			filters[key] = values[0]
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(struct {
+		Bookings []*model.Booking `json:"bookings"`
+		Metadata query.Metadata   `json:"metadata"`
+	}{bookings, metadata})
+}
+
+// listParams are the query-string keys that control paging and sorting. Every
+// other key is a filter such as status=pending or start_date[gte]=2024-06-01.
+var listParams = map[string]bool{"offset": true, "limit": true, "cursor": true, "sort": true, "sort_by": true, "sort_order": true}
+
+// parseListQuery turns the query string of GET /bookings into a validated
+// query. Sorting uses sort=-start_date,id; the older sort_by/sort_order pair
+// is still accepted. cursor continues from the next_cursor of a previous page.
+func parseListQuery(values url.Values) (*query.Query, error) {
+	offset, err := intParam(values, "offset")
+	if err != nil {
+		return nil, err
+	}
+	limit, err := intParam(values, "limit")
+	if err != nil {
+		return nil, err
+	}
+
+	sortSpec := values.Get("sort")
+	if sortSpec == "" {
+		sortSpec, err = query.LegacySort(values.Get("sort_by"), values.Get("sort_order"))
+		if err != nil {
+			return nil, err
 		}
 	}
-	sortBy := query.Get("sort_by")
-	if sortBy == "" {
-		sortBy = "id"
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		if !listParams[key] {
+			keys = append(keys, key)
+		}
 	}
-	sortOrder := query.Get("sort_order")
-	if sortOrder == "" {
-		sortOrder = "asc"
+	sort.Strings(keys)
+
+	var filters []query.Filter
+	for _, key := range keys {
+		for _, value := range values[key] {
+			filters = append(filters, query.Filter{Key: key, Value: value})
+		}
 	}
 
-	bookings, err := h.service.ListBookings(offset, limit, filters, sortBy, sortOrder)
+	q, err := query.Parse(filters, sortSpec, offset, limit)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, err
+	}
+	if err := q.After(values.Get("cursor")); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func intParam(values url.Values, key string) (int, error) {
+	raw := values.Get(key)
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s must be an integer", query.ErrInvalidQuery, key)
+	}
+	return n, nil
+}
+
+func (h *BookingHandler) ConfirmBooking(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, []string{auth.RoleAdmin, auth.RoleOperator}, func(scope model.Scope, id int64, actor string) (*model.Booking, error) {
+		return h.service.ConfirmBooking(scope, id, actor)
+	})
+}
+
+func (h *BookingHandler) CancelBooking(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&input)
+	if err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	h.transition(w, r, []string{auth.RoleClient, auth.RoleAdmin, auth.RoleOperator}, func(scope model.Scope, id int64, actor string) (*model.Booking, error) {
+		return h.service.CancelBooking(scope, id, actor, input.Reason)
+	})
+}
+
+func (h *BookingHandler) CheckInBooking(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, []string{auth.RoleAdmin, auth.RoleOperator}, func(scope model.Scope, id int64, actor string) (*model.Booking, error) {
+		return h.service.CheckInBooking(scope, id, actor)
+	})
+}
+
+func (h *BookingHandler) CheckOutBooking(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, []string{auth.RoleAdmin, auth.RoleOperator}, func(scope model.Scope, id int64, actor string) (*model.Booking, error) {
+		return h.service.CheckOutBooking(scope, id, actor)
+	})
+}
+
+func (h *BookingHandler) MarkNoShow(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, []string{auth.RoleAdmin, auth.RoleOperator}, func(scope model.Scope, id int64, actor string) (*model.Booking, error) {
+		return h.service.MarkNoShow(scope, id, actor)
+	})
+}
+
+// ConfirmHold turns an unexpired hold into a pending booking. Clients may
+// confirm their own holds.
+func (h *BookingHandler) ConfirmHold(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, []string{auth.RoleClient, auth.RoleAdmin, auth.RoleOperator}, func(scope model.Scope, id int64, actor string) (*model.Booking, error) {
+		return h.service.ConfirmHold(scope, id, actor)
+	})
+}
+
+// transition holds the plumbing shared by the lifecycle endpoints: role check,
+// booking ID parsing, error mapping and writing the updated booking.
+func (h *BookingHandler) transition(w http.ResponseWriter, r *http.Request, roles []string, apply func(scope model.Scope, id int64, actor string) (*model.Booking, error)) {
+	identity, err := auth.Require(r.Context(), roles...)
+	if err != nil {
+		http.Error(w, err.Error(), auth.HTTPStatus(err))
+		return
+	}
+
+	vars := mux.Vars(r)
+	id, err := strconv.ParseInt(vars["book_id"], 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	booking, err := apply(service.ScopeFor(identity), id, identity.Actor())
+	if err != nil {
+		http.Error(w, err.Error(), serviceErrorStatus(err))
+		return
+	}
+
+	setETag(w, booking)
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(bookings)
+	json.NewEncoder(w).Encode(booking)
 }
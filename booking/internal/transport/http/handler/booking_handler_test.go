@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"booking/internal/domain/model"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{model.ErrBookingNotFound, http.StatusNotFound},
+		{model.ErrEditConflict, http.StatusConflict},
+		{model.ErrIdempotencyKeyInProgress, http.StatusConflict},
+		{fmt.Errorf("wrapped: %w", model.ErrIdempotencyKeyInProgress), http.StatusConflict},
+		{model.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity},
+		{model.ErrReferenceCheckFailed, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, serviceErrorStatus(tt.err), tt.err.Error())
+	}
+}
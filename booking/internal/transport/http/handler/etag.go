@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"booking/internal/domain/model"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var errInvalidIfMatch = errors.New(`If-Match must be a booking ETag such as "3"`)
+
+// setETag identifies the version of the booking being returned, so that the
+// client can send it back in If-Match when it updates the booking.
+func setETag(w http.ResponseWriter, booking *model.Booking) {
+	w.Header().Set("ETag", `"`+strconv.Itoa(booking.Version)+`"`)
+}
+
+// ifMatchVersion returns the booking version an If-Match header asks for, or
+// zero if the header is missing or "*". Weak ETags are accepted as well.
+func ifMatchVersion(r *http.Request) (int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	value = strings.TrimPrefix(value, "W/")
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, errInvalidIfMatch
+	}
+	version, err := strconv.Atoi(value[1 : len(value)-1])
+	if err != nil || version < 1 {
+		return 0, errInvalidIfMatch
+	}
+	return version, nil
+}
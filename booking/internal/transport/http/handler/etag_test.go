@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    int
+		wantErr bool
+	}{
+		{name: "missing", header: "", want: 0},
+		{name: "any", header: "*", want: 0},
+		{name: "quoted version", header: `"3"`, want: 3},
+		{name: "surrounding space", header: ` "3" `, want: 3},
+		{name: "weak", header: `W/"4"`, want: 4},
+		{name: "unquoted", header: "3", wantErr: true},
+		{name: "half quoted", header: `"3`, wantErr: true},
+		{name: "empty quotes", header: `""`, wantErr: true},
+		{name: "not a number", header: `"abc"`, wantErr: true},
+		{name: "zero", header: `"0"`, wantErr: true},
+		{name: "negative", header: `"-1"`, wantErr: true},
+		{name: "weak unquoted", header: "W/4", wantErr: true},
+		{name: "list", header: `"3", "4"`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("PUT", "/bookings/1", nil)
+			if tt.header != "" {
+				r.Header.Set("If-Match", tt.header)
+			}
+
+			version, err := ifMatchVersion(r)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errInvalidIfMatch)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, version)
+		})
+	}
+}
@@ -2,77 +2,81 @@ package messaging
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
+	"time"
 
-	"booking/internal/domain/model"
+	commonMessaging "Booking_System/common/messaging"
 	"github.com/streadway/amqp"
 )
 
+const bookingExchange = "booking_exchange"
+
+// BookingMessaging publishes already serialized booking events. Events are
+// produced by the outbox relay, never directly by the service layer.
 type BookingMessaging interface {
-	PublishBookingCreated(booking *model.Booking) error
+	Publish(routingKey string, body []byte) error
 	Close() error
 }
 
 type BookingMessagingImpl struct {
-	connection *amqp.Connection
-	channel    *amqp.Channel
+	publisher *commonMessaging.Publisher
 }
 
-func NewBookingMessaging(rabbitMQUrl string) (*BookingMessagingImpl, error) {
-	conn, err := amqp.Dial(rabbitMQUrl)
-	if err != nil {
-		return nil, err
-	}
-
-	ch, err := conn.Channel()
+// NewBookingMessaging publishes through rabbit, so it recovers when the broker
+// comes back after an outage. Publishing fails while the broker is away, and
+// also when the broker nacks or does not confirm in time; the outbox relay
+// keeps those events and retries later. Events are published as mandatory so
+// the publisher stats count the ones no queue is bound to receive.
+func NewBookingMessaging(rabbit *commonMessaging.RabbitMQ) (*BookingMessagingImpl, error) {
+	publisher, err := commonMessaging.NewPublisher(rabbit, commonMessaging.PublisherConfig{
+		Exchanges: []string{bookingExchange},
+		Mandatory: true,
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	return &BookingMessagingImpl{connection: conn, channel: ch}, nil
+	return &BookingMessagingImpl{publisher: publisher}, nil
 }
 
-func (m *BookingMessagingImpl) PublishBookingCreated(booking *model.Booking) error {
-	err := m.channel.ExchangeDeclare(
-		"booking_exchange",
-		"topic",
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
-	if err != nil {
-		return err
+// Publish sends an encoded event envelope. Its ID, type and correlation ID
+// are copied into the message properties as well.
+func (m *BookingMessagingImpl) Publish(routingKey string, body []byte) error {
+	msg := amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Body:         body,
 	}
-
-	body, err := json.Marshal(booking)
-	if err != nil {
-		return err
+	if event, err := commonMessaging.Decode[json.RawMessage](body); err == nil {
+		msg.MessageId = event.EventID
+		msg.CorrelationId = event.CorrelationID
+		msg.Type = event.Type
+		msg.AppId = event.Producer
+		msg.Timestamp = event.OccurredAt
 	}
 
-	err = m.channel.Publish(
-		"booking_exchange",
-		"booking.created",
-		false,
-		false,
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		},
-	)
+	err := m.publisher.Publish(bookingExchange, routingKey, msg)
+	if errors.Is(err, commonMessaging.ErrUnroutable) {
+		// Nobody subscribes to this event (yet). Retrying would not change
+		// that and would hold up every event queued after it.
+		log.Printf("No queue is bound for %s message, dropping it: %s", routingKey, body)
+		return nil
+	}
 	if err != nil {
-		log.Printf("Failed to publish booking created message: %v", err)
+		log.Printf("Failed to publish %s message: %v", routingKey, err)
 		return err
 	}
 
-	log.Printf("Booking created message published: %v", booking)
+	log.Printf("%s message published: %s", routingKey, body)
 	return nil
 }
 
+// LogStats logs how the broker answered the published events every interval
+// until the connection is closed.
+func (m *BookingMessagingImpl) LogStats(interval time.Duration) {
+	m.publisher.LogStats(interval)
+}
+
 func (m *BookingMessagingImpl) Close() error {
-	if err := m.channel.Close(); err != nil {
-		return err
-	}
-	return m.connection.Close()
+	return m.publisher.Close()
 }
@@ -1,7 +1,6 @@
 package messaging
 
 import (
-	"booking/internal/domain/model"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -9,8 +8,8 @@ type BookingMessagingMock struct {
 	mock.Mock
 }
 
-func (m *BookingMessagingMock) PublishBookingCreated(booking *model.Booking) error {
-	args := m.Called(booking)
+func (m *BookingMessagingMock) Publish(routingKey string, body []byte) error {
+	args := m.Called(routingKey, body)
 	return args.Error(0)
 }
 
@@ -0,0 +1,108 @@
+package messaging
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	commonMessaging "Booking_System/common/messaging"
+)
+
+const (
+	userExchange   = "user_exchange"
+	roomExchange   = "room_exchange"
+	deletionsQueue = "booking_deletions"
+
+	userDeletedRouting = "user.deleted"
+	roomDeletedRouting = "room.deleted"
+)
+
+// Newest schema versions of the deletion payloads this consumer understands.
+const (
+	userEventVersion = 1
+	roomEventVersion = 1
+)
+
+// userDeletedPayload is the part of clientManage's user payload that
+// user.deleted needs.
+type userDeletedPayload struct {
+	ID int64 `json:"id"`
+}
+
+// roomDeletedPayload is the payload roomManage publishes as room.deleted. Room
+// IDs are strings in roomManage but numeric in bookings.
+type roomDeletedPayload struct {
+	ID string `json:"id"`
+}
+
+// BookingCanceller cancels upcoming bookings; service.BookingService
+// implements it.
+type BookingCanceller interface {
+	CancelUpcomingForClient(clientID int64, reason, correlationID string) (int, error)
+	CancelUpcomingForRoom(roomID int64, reason, correlationID string) (int, error)
+}
+
+// DeletionConsumer cancels the upcoming bookings of users and rooms that were
+// deleted in clientManage or roomManage.
+type DeletionConsumer struct {
+	bookings BookingCanceller
+	consumer *commonMessaging.Consumer
+}
+
+func NewDeletionConsumer(rabbit *commonMessaging.RabbitMQ, bookings BookingCanceller) *DeletionConsumer {
+	c := &DeletionConsumer{bookings: bookings}
+	c.consumer = commonMessaging.NewConsumer(rabbit, commonMessaging.ConsumerConfig{
+		Queue: deletionsQueue,
+		Bindings: []commonMessaging.Binding{
+			{Exchange: userExchange, RoutingKey: userDeletedRouting},
+			{Exchange: roomExchange, RoutingKey: roomDeletedRouting},
+		},
+	}, commonMessaging.Deduplicate(commonMessaging.NewMemoryDeduplicator(0), c.handle))
+	return c
+}
+
+// Run consumes deletion events until the RabbitMQ connection is closed.
+// Failed cancellations are retried with backoff; malformed messages and
+// unknown schema versions go straight to the dead-letter queue. Redelivered
+// events are skipped by their event ID.
+func (c *DeletionConsumer) Run() error {
+	return c.consumer.Run()
+}
+
+func (c *DeletionConsumer) handle(d commonMessaging.Delivery) error {
+	cancelled, err := c.cancel(d.RoutingKey, d.Body)
+	if err != nil {
+		return err
+	}
+	log.Printf("%s: cancelled %d upcoming bookings", d.RoutingKey, cancelled)
+	return nil
+}
+
+func (c *DeletionConsumer) cancel(routingKey string, body []byte) (int, error) {
+	switch routingKey {
+	case userDeletedRouting:
+		event, err := commonMessaging.Decode[userDeletedPayload](body)
+		if err == nil {
+			err = event.CheckVersion(userEventVersion)
+		}
+		if err != nil {
+			return 0, commonMessaging.Permanent(err)
+		}
+		return c.bookings.CancelUpcomingForClient(event.Payload.ID, "client deleted", event.CorrelationID)
+	case roomDeletedRouting:
+		event, err := commonMessaging.Decode[roomDeletedPayload](body)
+		if err == nil {
+			err = event.CheckVersion(roomEventVersion)
+		}
+		if err != nil {
+			return 0, commonMessaging.Permanent(err)
+		}
+		roomID, err := strconv.ParseInt(event.Payload.ID, 10, 64)
+		if err != nil {
+			return 0, commonMessaging.Permanent(fmt.Errorf("room id %q: %w", event.Payload.ID, err))
+		}
+		return c.bookings.CancelUpcomingForRoom(roomID, "room deleted", event.CorrelationID)
+	default:
+		return 0, commonMessaging.Permanent(fmt.Errorf("unexpected routing key %q", routingKey))
+	}
+}
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS bookings (
+                                        id bigserial PRIMARY KEY,
+                                        client_id bigint NOT NULL,
+                                        room_id bigint NOT NULL,
+                                        start_date timestamp(0) with time zone NOT NULL,
+                                        end_date timestamp(0) with time zone NOT NULL,
+                                        status varchar(50) NOT NULL
+);
@@ -0,0 +1,12 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_date_range_check CHECK (end_date > start_date);
+
+-- Two active bookings for the same room may never share a night. The range is
+-- half-open so a check-out and the next check-in can fall on the same day.
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_no_overlap EXCLUDE USING gist (
+        room_id WITH =,
+        tstzrange(start_date, end_date, '[)') WITH &&
+    ) WHERE (status <> 'cancelled');
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS booking_status_history;
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_overlap;
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_no_overlap EXCLUDE USING gist (
+        room_id WITH =,
+        tstzrange(start_date, end_date, '[)') WITH &&
+    ) WHERE (status <> 'cancelled');
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_status_check;
+ALTER TABLE bookings ALTER COLUMN status DROP DEFAULT;
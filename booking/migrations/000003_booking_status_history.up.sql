@@ -0,0 +1,26 @@
+UPDATE bookings
+SET status = 'pending'
+WHERE status NOT IN ('pending', 'confirmed', 'checked_in', 'checked_out', 'cancelled', 'no_show');
+
+ALTER TABLE bookings ALTER COLUMN status SET DEFAULT 'pending';
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_status_check
+        CHECK (status IN ('pending', 'confirmed', 'checked_in', 'checked_out', 'cancelled', 'no_show'));
+
+-- A no-show releases the room just like a cancellation does.
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_overlap;
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_no_overlap EXCLUDE USING gist (
+        room_id WITH =,
+        tstzrange(start_date, end_date, '[)') WITH &&
+    ) WHERE (status NOT IN ('cancelled', 'no_show'));
+
+CREATE TABLE IF NOT EXISTS booking_status_history (
+                                                      id bigserial PRIMARY KEY,
+                                                      booking_id bigint NOT NULL REFERENCES bookings ON DELETE CASCADE,
+                                                      from_status varchar(50) NOT NULL,
+                                                      to_status varchar(50) NOT NULL,
+                                                      changed_by text NOT NULL,
+                                                      reason text NOT NULL DEFAULT '',
+                                                      changed_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
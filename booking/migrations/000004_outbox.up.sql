@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS outbox (
+                                      id bigserial PRIMARY KEY,
+                                      routing_key text NOT NULL,
+                                      payload jsonb NOT NULL,
+                                      attempts integer NOT NULL DEFAULT 0,
+                                      last_error text NOT NULL DEFAULT '',
+                                      created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+                                      next_attempt_at timestamp with time zone NOT NULL DEFAULT NOW(),
+                                      sent_at timestamp with time zone
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (next_attempt_at) WHERE sent_at IS NULL;
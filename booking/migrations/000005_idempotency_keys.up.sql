@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+                                                owner text NOT NULL,
+                                                key text NOT NULL,
+                                                request_hash text NOT NULL,
+                                                booking_id bigint NOT NULL REFERENCES bookings ON DELETE CASCADE,
+                                                response jsonb NOT NULL,
+                                                created_at timestamp with time zone NOT NULL DEFAULT NOW(),
+                                                expires_at timestamp with time zone NOT NULL,
+                                                PRIMARY KEY (owner, key)
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
@@ -0,0 +1,3 @@
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS total_price,
+    DROP COLUMN IF EXISTS currency;
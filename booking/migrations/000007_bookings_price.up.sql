@@ -0,0 +1,3 @@
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS total_price bigint NOT NULL DEFAULT 0 CHECK (total_price >= 0),
+    ADD COLUMN IF NOT EXISTS currency text NOT NULL DEFAULT '';
@@ -0,0 +1,19 @@
+DROP INDEX IF EXISTS bookings_held_idx;
+
+-- Holds have no status to go back to; release them.
+UPDATE bookings SET status = 'cancelled' WHERE status IN ('held', 'hold_expired');
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_overlap;
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_no_overlap EXCLUDE USING gist (
+        room_id WITH =,
+        tstzrange(start_date, end_date, '[)') WITH &&
+    ) WHERE (status NOT IN ('cancelled', 'no_show'));
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_hold_expiry_check;
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_status_check;
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_status_check
+        CHECK (status IN ('pending', 'confirmed', 'checked_in', 'checked_out', 'cancelled', 'no_show'));
+
+ALTER TABLE bookings DROP COLUMN IF EXISTS hold_expires_at;
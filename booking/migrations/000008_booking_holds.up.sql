@@ -0,0 +1,19 @@
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS hold_expires_at timestamp with time zone;
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_status_check;
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_status_check
+        CHECK (status IN ('held', 'hold_expired', 'pending', 'confirmed', 'checked_in', 'checked_out', 'cancelled', 'no_show'));
+
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_hold_expiry_check CHECK (status <> 'held' OR hold_expires_at IS NOT NULL);
+
+-- A hold blocks the room like a booking until it expires.
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_no_overlap;
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_no_overlap EXCLUDE USING gist (
+        room_id WITH =,
+        tstzrange(start_date, end_date, '[)') WITH &&
+    ) WHERE (status NOT IN ('cancelled', 'no_show', 'hold_expired'));
+
+CREATE INDEX IF NOT EXISTS bookings_held_idx ON bookings (hold_expires_at) WHERE status = 'held';
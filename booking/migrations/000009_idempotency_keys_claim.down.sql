@@ -0,0 +1,3 @@
+DELETE FROM idempotency_keys WHERE booking_id IS NULL OR response IS NULL;
+ALTER TABLE idempotency_keys ALTER COLUMN booking_id SET NOT NULL;
+ALTER TABLE idempotency_keys ALTER COLUMN response SET NOT NULL;
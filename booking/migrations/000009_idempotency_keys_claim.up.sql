@@ -0,0 +1,4 @@
+-- A key is claimed before its booking exists, so both stay empty until the
+-- booking is created in the same transaction. Nobody else ever sees them empty.
+ALTER TABLE idempotency_keys ALTER COLUMN booking_id DROP NOT NULL;
+ALTER TABLE idempotency_keys ALTER COLUMN response DROP NOT NULL;
@@ -20,11 +20,19 @@ import (
 const _ = grpc.SupportPackageIsVersion8
 
 const (
-	BookingService_CreateBooking_FullMethodName = "/booking.BookingService/CreateBooking"
-	BookingService_GetBooking_FullMethodName    = "/booking.BookingService/GetBooking"
-	BookingService_UpdateBooking_FullMethodName = "/booking.BookingService/UpdateBooking"
-	BookingService_DeleteBooking_FullMethodName = "/booking.BookingService/DeleteBooking"
-	BookingService_ListBookings_FullMethodName  = "/booking.BookingService/ListBookings"
+	BookingService_CreateBooking_FullMethodName   = "/booking.BookingService/CreateBooking"
+	BookingService_GetBooking_FullMethodName      = "/booking.BookingService/GetBooking"
+	BookingService_UpdateBooking_FullMethodName   = "/booking.BookingService/UpdateBooking"
+	BookingService_DeleteBooking_FullMethodName   = "/booking.BookingService/DeleteBooking"
+	BookingService_ListBookings_FullMethodName    = "/booking.BookingService/ListBookings"
+	BookingService_ConfirmBooking_FullMethodName  = "/booking.BookingService/ConfirmBooking"
+	BookingService_CancelBooking_FullMethodName   = "/booking.BookingService/CancelBooking"
+	BookingService_CheckInBooking_FullMethodName  = "/booking.BookingService/CheckInBooking"
+	BookingService_CheckOutBooking_FullMethodName = "/booking.BookingService/CheckOutBooking"
+	BookingService_MarkNoShow_FullMethodName      = "/booking.BookingService/MarkNoShow"
+	BookingService_QuoteBooking_FullMethodName    = "/booking.BookingService/QuoteBooking"
+	BookingService_HoldRoom_FullMethodName        = "/booking.BookingService/HoldRoom"
+	BookingService_ConfirmHold_FullMethodName     = "/booking.BookingService/ConfirmHold"
 )
 
 // BookingServiceClient is the client API for BookingService service.
@@ -36,6 +44,16 @@ type BookingServiceClient interface {
 	UpdateBooking(ctx context.Context, in *UpdateBookingRequest, opts ...grpc.CallOption) (*BookingResponse, error)
 	DeleteBooking(ctx context.Context, in *DeleteBookingRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	ListBookings(ctx context.Context, in *ListBookingsRequest, opts ...grpc.CallOption) (*ListBookingsResponse, error)
+	ConfirmBooking(ctx context.Context, in *BookingTransitionRequest, opts ...grpc.CallOption) (*BookingResponse, error)
+	CancelBooking(ctx context.Context, in *BookingTransitionRequest, opts ...grpc.CallOption) (*BookingResponse, error)
+	CheckInBooking(ctx context.Context, in *BookingTransitionRequest, opts ...grpc.CallOption) (*BookingResponse, error)
+	CheckOutBooking(ctx context.Context, in *BookingTransitionRequest, opts ...grpc.CallOption) (*BookingResponse, error)
+	MarkNoShow(ctx context.Context, in *BookingTransitionRequest, opts ...grpc.CallOption) (*BookingResponse, error)
+	QuoteBooking(ctx context.Context, in *QuoteBookingRequest, opts ...grpc.CallOption) (*Quote, error)
+	// HoldRoom reserves a room for a few minutes; ConfirmHold turns the hold
+	// into a pending booking before it expires.
+	HoldRoom(ctx context.Context, in *HoldRoomRequest, opts ...grpc.CallOption) (*BookingResponse, error)
+	ConfirmHold(ctx context.Context, in *BookingTransitionRequest, opts ...grpc.CallOption) (*BookingResponse, error)
 }
 
 type bookingServiceClient struct {
@@ -96,6 +114,86 @@ func (c *bookingServiceClient) ListBookings(ctx context.Context, in *ListBooking
 	return out, nil
 }
 
+func (c *bookingServiceClient) ConfirmBooking(ctx context.Context, in *BookingTransitionRequest, opts ...grpc.CallOption) (*BookingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BookingResponse)
+	err := c.cc.Invoke(ctx, BookingService_ConfirmBooking_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookingServiceClient) CancelBooking(ctx context.Context, in *BookingTransitionRequest, opts ...grpc.CallOption) (*BookingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BookingResponse)
+	err := c.cc.Invoke(ctx, BookingService_CancelBooking_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookingServiceClient) CheckInBooking(ctx context.Context, in *BookingTransitionRequest, opts ...grpc.CallOption) (*BookingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BookingResponse)
+	err := c.cc.Invoke(ctx, BookingService_CheckInBooking_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookingServiceClient) CheckOutBooking(ctx context.Context, in *BookingTransitionRequest, opts ...grpc.CallOption) (*BookingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BookingResponse)
+	err := c.cc.Invoke(ctx, BookingService_CheckOutBooking_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookingServiceClient) MarkNoShow(ctx context.Context, in *BookingTransitionRequest, opts ...grpc.CallOption) (*BookingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BookingResponse)
+	err := c.cc.Invoke(ctx, BookingService_MarkNoShow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookingServiceClient) QuoteBooking(ctx context.Context, in *QuoteBookingRequest, opts ...grpc.CallOption) (*Quote, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Quote)
+	err := c.cc.Invoke(ctx, BookingService_QuoteBooking_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookingServiceClient) HoldRoom(ctx context.Context, in *HoldRoomRequest, opts ...grpc.CallOption) (*BookingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BookingResponse)
+	err := c.cc.Invoke(ctx, BookingService_HoldRoom_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookingServiceClient) ConfirmHold(ctx context.Context, in *BookingTransitionRequest, opts ...grpc.CallOption) (*BookingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BookingResponse)
+	err := c.cc.Invoke(ctx, BookingService_ConfirmHold_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BookingServiceServer is the server API for BookingService service.
 // All implementations must embed UnimplementedBookingServiceServer
 // for forward compatibility
@@ -105,6 +203,16 @@ type BookingServiceServer interface {
 	UpdateBooking(context.Context, *UpdateBookingRequest) (*BookingResponse, error)
 	DeleteBooking(context.Context, *DeleteBookingRequest) (*emptypb.Empty, error)
 	ListBookings(context.Context, *ListBookingsRequest) (*ListBookingsResponse, error)
+	ConfirmBooking(context.Context, *BookingTransitionRequest) (*BookingResponse, error)
+	CancelBooking(context.Context, *BookingTransitionRequest) (*BookingResponse, error)
+	CheckInBooking(context.Context, *BookingTransitionRequest) (*BookingResponse, error)
+	CheckOutBooking(context.Context, *BookingTransitionRequest) (*BookingResponse, error)
+	MarkNoShow(context.Context, *BookingTransitionRequest) (*BookingResponse, error)
+	QuoteBooking(context.Context, *QuoteBookingRequest) (*Quote, error)
+	// HoldRoom reserves a room for a few minutes; ConfirmHold turns the hold
+	// into a pending booking before it expires.
+	HoldRoom(context.Context, *HoldRoomRequest) (*BookingResponse, error)
+	ConfirmHold(context.Context, *BookingTransitionRequest) (*BookingResponse, error)
 	mustEmbedUnimplementedBookingServiceServer()
 }
 
@@ -127,6 +235,30 @@ func (UnimplementedBookingServiceServer) DeleteBooking(context.Context, *DeleteB
 func (UnimplementedBookingServiceServer) ListBookings(context.Context, *ListBookingsRequest) (*ListBookingsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListBookings not implemented")
 }
+func (UnimplementedBookingServiceServer) ConfirmBooking(context.Context, *BookingTransitionRequest) (*BookingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmBooking not implemented")
+}
+func (UnimplementedBookingServiceServer) CancelBooking(context.Context, *BookingTransitionRequest) (*BookingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelBooking not implemented")
+}
+func (UnimplementedBookingServiceServer) CheckInBooking(context.Context, *BookingTransitionRequest) (*BookingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckInBooking not implemented")
+}
+func (UnimplementedBookingServiceServer) CheckOutBooking(context.Context, *BookingTransitionRequest) (*BookingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckOutBooking not implemented")
+}
+func (UnimplementedBookingServiceServer) MarkNoShow(context.Context, *BookingTransitionRequest) (*BookingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkNoShow not implemented")
+}
+func (UnimplementedBookingServiceServer) QuoteBooking(context.Context, *QuoteBookingRequest) (*Quote, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QuoteBooking not implemented")
+}
+func (UnimplementedBookingServiceServer) HoldRoom(context.Context, *HoldRoomRequest) (*BookingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method HoldRoom not implemented")
+}
+func (UnimplementedBookingServiceServer) ConfirmHold(context.Context, *BookingTransitionRequest) (*BookingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmHold not implemented")
+}
 func (UnimplementedBookingServiceServer) mustEmbedUnimplementedBookingServiceServer() {}
 
 // UnsafeBookingServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -230,6 +362,150 @@ func _BookingService_ListBookings_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BookingService_ConfirmBooking_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BookingTransitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookingServiceServer).ConfirmBooking(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookingService_ConfirmBooking_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookingServiceServer).ConfirmBooking(ctx, req.(*BookingTransitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookingService_CancelBooking_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BookingTransitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookingServiceServer).CancelBooking(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookingService_CancelBooking_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookingServiceServer).CancelBooking(ctx, req.(*BookingTransitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookingService_CheckInBooking_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BookingTransitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookingServiceServer).CheckInBooking(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookingService_CheckInBooking_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookingServiceServer).CheckInBooking(ctx, req.(*BookingTransitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookingService_CheckOutBooking_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BookingTransitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookingServiceServer).CheckOutBooking(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookingService_CheckOutBooking_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookingServiceServer).CheckOutBooking(ctx, req.(*BookingTransitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookingService_MarkNoShow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BookingTransitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookingServiceServer).MarkNoShow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookingService_MarkNoShow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookingServiceServer).MarkNoShow(ctx, req.(*BookingTransitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookingService_QuoteBooking_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QuoteBookingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookingServiceServer).QuoteBooking(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookingService_QuoteBooking_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookingServiceServer).QuoteBooking(ctx, req.(*QuoteBookingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookingService_HoldRoom_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HoldRoomRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookingServiceServer).HoldRoom(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookingService_HoldRoom_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookingServiceServer).HoldRoom(ctx, req.(*HoldRoomRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookingService_ConfirmHold_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BookingTransitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookingServiceServer).ConfirmHold(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookingService_ConfirmHold_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookingServiceServer).ConfirmHold(ctx, req.(*BookingTransitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BookingService_ServiceDesc is the grpc.ServiceDesc for BookingService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -257,6 +533,38 @@ var BookingService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListBookings",
 			Handler:    _BookingService_ListBookings_Handler,
 		},
+		{
+			MethodName: "ConfirmBooking",
+			Handler:    _BookingService_ConfirmBooking_Handler,
+		},
+		{
+			MethodName: "CancelBooking",
+			Handler:    _BookingService_CancelBooking_Handler,
+		},
+		{
+			MethodName: "CheckInBooking",
+			Handler:    _BookingService_CheckInBooking_Handler,
+		},
+		{
+			MethodName: "CheckOutBooking",
+			Handler:    _BookingService_CheckOutBooking_Handler,
+		},
+		{
+			MethodName: "MarkNoShow",
+			Handler:    _BookingService_MarkNoShow_Handler,
+		},
+		{
+			MethodName: "QuoteBooking",
+			Handler:    _BookingService_QuoteBooking_Handler,
+		},
+		{
+			MethodName: "HoldRoom",
+			Handler:    _BookingService_HoldRoom_Handler,
+		},
+		{
+			MethodName: "ConfirmHold",
+			Handler:    _BookingService_ConfirmHold_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "booking_system/booking/proto/booking.proto",
@@ -2,6 +2,7 @@ package integration_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
@@ -11,23 +12,59 @@ import (
 	"testing"
 	"time"
 
+	"Booking_System/common/auth"
 	"booking/internal/app"
 	"booking/internal/domain/model"
+	"booking/internal/query"
 	"booking/internal/repository"
 	"booking/internal/service"
 	"booking/internal/transport/http/handler"
-	"booking/internal/transport/messaging"
 	"booking/pkg/database"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 )
 
+const testJWTSecret = "integration-test-secret"
+
 var (
-	server           *httptest.Server
-	bookingRepo      repository.BookingRepository
-	bookingMessaging messaging.BookingMessaging
+	server      *httptest.Server
+	bookingRepo repository.BookingRepository
 )
 
+// bearerTransport signs every request made by the default client as an admin,
+// the way clientManage would.
+type bearerTransport struct {
+	token string
+}
+
+func (t *bearerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+t.token)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// acceptAllReferences stands in for clientManage and roomManage, which are
+// not running during these tests.
+type acceptAllReferences struct{}
+
+func (acceptAllReferences) CheckClient(ctx context.Context, clientID int64) error { return nil }
+func (acceptAllReferences) CheckRoom(ctx context.Context, roomID int64) error     { return nil }
+
+func adminToken() string {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss":  auth.Issuer,
+		"sub":  "1",
+		"role": auth.RoleAdmin,
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString([]byte(testJWTSecret))
+	if err != nil {
+		log.Fatalf("Failed to sign test token: %v", err)
+	}
+	return signed
+}
+
 func setup() {
 	cfg := app.LoadConfig()
 
@@ -36,28 +73,33 @@ func setup() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	// Events only go to the outbox here, so the HTTP API works without RabbitMQ.
 	bookingRepo = repository.NewBookingRepository(db)
-	bookingMessaging, err = messaging.NewBookingMessaging(cfg.RabbitMQUrl)
-	if err != nil {
-		log.Fatalf("Failed to connect to RabbitMQ: %v", err)
-	}
-
-	bookingService := service.NewBookingService(bookingRepo, bookingMessaging)
+	bookingService := service.NewBookingService(bookingRepo, acceptAllReferences{}, nil, 0, 0)
 	bookingHandler := handler.NewBookingHandler(bookingService)
 
 	r := mux.NewRouter()
 	r.HandleFunc("/bookings", bookingHandler.ListBookings).Methods("GET")
 	r.HandleFunc("/bookings/{book_id}", bookingHandler.GetBooking).Methods("GET")
 	r.HandleFunc("/bookings", bookingHandler.CreateBooking).Methods("POST")
+	r.HandleFunc("/bookings/quote", bookingHandler.QuoteBooking).Methods("POST")
+	r.HandleFunc("/bookings/holds", bookingHandler.HoldRoom).Methods("POST")
 	r.HandleFunc("/bookings/{book_id}", bookingHandler.UpdateBooking).Methods("PUT")
+	r.HandleFunc("/bookings/{book_id}", bookingHandler.PatchBooking).Methods("PATCH")
 	r.HandleFunc("/bookings/{book_id}", bookingHandler.DeleteBooking).Methods("DELETE")
+	r.HandleFunc("/bookings/{book_id}/confirm", bookingHandler.ConfirmBooking).Methods("POST")
+	r.HandleFunc("/bookings/{book_id}/confirm-hold", bookingHandler.ConfirmHold).Methods("POST")
+	r.HandleFunc("/bookings/{book_id}/cancel", bookingHandler.CancelBooking).Methods("POST")
+	r.HandleFunc("/bookings/{book_id}/check-in", bookingHandler.CheckInBooking).Methods("POST")
+	r.HandleFunc("/bookings/{book_id}/check-out", bookingHandler.CheckOutBooking).Methods("POST")
+	r.HandleFunc("/bookings/{book_id}/no-show", bookingHandler.MarkNoShow).Methods("POST")
 
-	server = httptest.NewServer(r)
+	server = httptest.NewServer(auth.NewVerifier(testJWTSecret).Middleware(r))
+	http.DefaultClient = &http.Client{Transport: &bearerTransport{token: adminToken()}}
 }
 
 func teardown() {
 	server.Close()
-	bookingMessaging.Close()
 }
 
 func TestMain(m *testing.M) {
@@ -89,7 +131,7 @@ func TestCreateBookingIntegration(t *testing.T) {
 func TestGetBookingByIDIntegration(t *testing.T) {
 	booking := &model.Booking{
 		ClientID:  1,
-		RoomID:    1,
+		RoomID:    2,
 		StartDate: time.Now(),
 		EndDate:   time.Now().Add(24 * time.Hour),
 		Status:    "confirmed",
@@ -108,7 +150,7 @@ func TestGetBookingByIDIntegration(t *testing.T) {
 func TestUpdateBookingIntegration(t *testing.T) {
 	booking := &model.Booking{
 		ClientID:  1,
-		RoomID:    1,
+		RoomID:    3,
 		StartDate: time.Now(),
 		EndDate:   time.Now().Add(24 * time.Hour),
 		Status:    "confirmed",
@@ -130,7 +172,7 @@ func TestUpdateBookingIntegration(t *testing.T) {
 func TestDeleteBookingIntegration(t *testing.T) {
 	booking := &model.Booking{
 		ClientID:  1,
-		RoomID:    1,
+		RoomID:    4,
 		StartDate: time.Now(),
 		EndDate:   time.Now().Add(24 * time.Hour),
 		Status:    "confirmed",
@@ -149,7 +191,7 @@ func TestDeleteBookingIntegration(t *testing.T) {
 func TestListBookingsIntegration(t *testing.T) {
 	booking1 := &model.Booking{
 		ClientID:  1,
-		RoomID:    1,
+		RoomID:    5,
 		StartDate: time.Now(),
 		EndDate:   time.Now().Add(24 * time.Hour),
 		Status:    "confirmed",
@@ -158,18 +200,63 @@ func TestListBookingsIntegration(t *testing.T) {
 
 	booking2 := &model.Booking{
 		ClientID:  2,
-		RoomID:    2,
+		RoomID:    6,
 		StartDate: time.Now(),
 		EndDate:   time.Now().Add(24 * time.Hour),
 		Status:    "confirmed",
 	}
 	bookingRepo.CreateBooking(booking2)
 
-	resp, err := http.Get(server.URL + "/bookings")
+	type page struct {
+		Bookings []model.Booking `json:"bookings"`
+		Metadata query.Metadata  `json:"metadata"`
+	}
+
+	// Page through both bookings one at a time.
+	resp, err := http.Get(server.URL + "/bookings?room_id[in]=5,6&limit=1")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var first page
+	json.NewDecoder(resp.Body).Decode(&first)
+	assert.Len(t, first.Bookings, 1)
+	assert.Equal(t, 2, first.Metadata.TotalRecords)
+	assert.Equal(t, 1, first.Metadata.PageSize)
+	assert.NotEmpty(t, first.Metadata.NextCursor)
+
+	resp, err = http.Get(server.URL + "/bookings?room_id[in]=5,6&limit=1&cursor=" + first.Metadata.NextCursor)
 	assert.Nil(t, err)
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 
-	var bookings []model.Booking
-	json.NewDecoder(resp.Body).Decode(&bookings)
-	assert.Len(t, bookings, 2)
+	var second page
+	json.NewDecoder(resp.Body).Decode(&second)
+	assert.Len(t, second.Bookings, 1)
+	assert.NotEqual(t, first.Bookings[0].ID, second.Bookings[0].ID)
+	assert.Empty(t, second.Metadata.NextCursor)
+}
+
+func TestCreateOverlappingBookingIntegration(t *testing.T) {
+	start := time.Now().Add(72 * time.Hour)
+	booking := &model.Booking{
+		ClientID:  1,
+		RoomID:    7,
+		StartDate: start,
+		EndDate:   start.Add(48 * time.Hour),
+		Status:    "confirmed",
+	}
+	err := bookingRepo.CreateBooking(booking)
+	assert.Nil(t, err)
+
+	overlapping := &model.Booking{
+		ClientID:  2,
+		RoomID:    7,
+		StartDate: start.Add(24 * time.Hour),
+		EndDate:   start.Add(72 * time.Hour),
+		Status:    "confirmed",
+	}
+	body, _ := json.Marshal(overlapping)
+
+	resp, err := http.Post(server.URL+"/bookings", "application/json", bytes.NewBuffer(body))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
 }
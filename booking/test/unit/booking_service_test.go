@@ -2,24 +2,30 @@ package service_test
 
 import (
 	"booking/internal/domain/model"
+	"booking/internal/query"
 	"booking/internal/repository"
 	"booking/internal/service"
-	"booking/internal/transport/messaging"
+	"context"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
-func setup() (*repository.BookingRepositoryMock, *messaging.BookingMessagingMock, *service.BookingService) {
+// setup returns a service whose client and room checks always pass.
+func setup() (*repository.BookingRepositoryMock, *service.BookingService) {
 	repoMock := new(repository.BookingRepositoryMock)
-	messagingMock := new(messaging.BookingMessagingMock)
-	svc := service.NewBookingService(repoMock, messagingMock)
-	return repoMock, messagingMock, svc
+	refsMock := new(service.ReferenceCheckerMock)
+	refsMock.On("CheckClient", mock.Anything, mock.Anything).Return(nil)
+	refsMock.On("CheckRoom", mock.Anything, mock.Anything).Return(nil)
+	svc := service.NewBookingService(repoMock, refsMock, nil, 0, 0)
+	return repoMock, svc
 }
 
 func TestCreateBooking(t *testing.T) {
-	repoMock, messagingMock, svc := setup()
+	repoMock, svc := setup()
 
 	booking := &model.Booking{
 		ClientID:  1,
@@ -29,19 +35,20 @@ func TestCreateBooking(t *testing.T) {
 		Status:    "confirmed",
 	}
 
-	repoMock.On("CreateBooking", booking).Return(nil)
-	messagingMock.On("PublishBookingCreated", booking).Return(nil)
+	repoMock.On("CreateBooking", booking).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.Booking).ID = 1
+	}).Return(nil)
 
-	err := svc.CreateBooking(booking)
+	err := svc.CreateBooking(context.Background(), model.AllBookings(), booking)
 	assert.Nil(t, err)
 	assert.NotZero(t, booking.ID)
+	assert.Equal(t, model.StatusPending, booking.Status)
 
 	repoMock.AssertCalled(t, "CreateBooking", booking)
-	messagingMock.AssertCalled(t, "PublishBookingCreated", booking)
 }
 
 func TestGetBookingByID(t *testing.T) {
-	repoMock, _, svc := setup()
+	repoMock, svc := setup()
 
 	booking := &model.Booking{
 		ID:        1,
@@ -53,14 +60,14 @@ func TestGetBookingByID(t *testing.T) {
 	}
 
 	repoMock.On("GetBookingByID", int64(1)).Return(booking, nil)
-	result, err := svc.GetBookingByID(1)
+	result, err := svc.GetBookingByID(model.AllBookings(), 1)
 	assert.Nil(t, err)
 	assert.Equal(t, booking, result)
 	repoMock.AssertExpectations(t)
 }
 
 func TestUpdateBooking(t *testing.T) {
-	repoMock, _, svc := setup()
+	repoMock, svc := setup()
 
 	booking := &model.Booking{
 		ID:        1,
@@ -71,14 +78,16 @@ func TestUpdateBooking(t *testing.T) {
 		Status:    "confirmed",
 	}
 
+	current := *booking
+	repoMock.On("GetBookingByID", int64(1)).Return(&current, nil)
 	repoMock.On("UpdateBooking", booking).Return(nil)
-	err := svc.UpdateBooking(booking)
+	err := svc.UpdateBooking(context.Background(), booking)
 	assert.Nil(t, err)
 	repoMock.AssertExpectations(t)
 }
 
 func TestDeleteBooking(t *testing.T) {
-	repoMock, _, svc := setup()
+	repoMock, svc := setup()
 
 	repoMock.On("DeleteBooking", int64(1)).Return(nil)
 
@@ -88,7 +97,7 @@ func TestDeleteBooking(t *testing.T) {
 }
 
 func TestListBookings(t *testing.T) {
-	repoMock, _, svc := setup()
+	repoMock, svc := setup()
 
 	bookings := []*model.Booking{
 		{
@@ -101,17 +110,19 @@ func TestListBookings(t *testing.T) {
 		},
 	}
 
-	filters := make(map[string]interface{})
-	repoMock.On("ListBookings", 0, 10, filters, "id", "asc").Return(bookings, nil)
+	q, _ := query.Parse(nil, "id", 0, 10)
+	metadata := query.Metadata{TotalRecords: 1, PageSize: 10}
+	repoMock.On("ListBookings", q).Return(bookings, metadata, nil)
 
-	result, err := svc.ListBookings(0, 10, filters, "id", "asc")
+	result, resultMetadata, err := svc.ListBookings(model.AllBookings(), q)
 	assert.Nil(t, err)
 	assert.Equal(t, bookings, result)
+	assert.Equal(t, metadata, resultMetadata)
 	repoMock.AssertExpectations(t)
 }
 
 func TestCreateBookingWithError(t *testing.T) {
-	repoMock, messagingMock, svc := setup()
+	repoMock, svc := setup()
 
 	booking := &model.Booking{
 		ClientID:  1,
@@ -122,27 +133,25 @@ func TestCreateBookingWithError(t *testing.T) {
 	}
 
 	repoMock.On("CreateBooking", booking).Return(assert.AnError)
-	messagingMock.On("PublishBookingCreated", booking).Return(nil)
 
-	err := svc.CreateBooking(booking)
+	err := svc.CreateBooking(context.Background(), model.AllBookings(), booking)
 	assert.NotNil(t, err)
 	repoMock.AssertExpectations(t)
-	messagingMock.AssertExpectations(t)
 }
 
 func TestGetBookingByIDNotFound(t *testing.T) {
-	repoMock, _, svc := setup()
+	repoMock, svc := setup()
 
 	repoMock.On("GetBookingByID", int64(1)).Return(nil, assert.AnError)
 
-	result, err := svc.GetBookingByID(1)
+	result, err := svc.GetBookingByID(model.AllBookings(), 1)
 	assert.NotNil(t, err)
 	assert.Nil(t, result)
 	repoMock.AssertExpectations(t)
 }
 
 func TestUpdateBookingWithError(t *testing.T) {
-	repoMock, _, svc := setup()
+	repoMock, svc := setup()
 
 	booking := &model.Booking{
 		ID:        1,
@@ -153,15 +162,17 @@ func TestUpdateBookingWithError(t *testing.T) {
 		Status:    "confirmed",
 	}
 
+	current := *booking
+	repoMock.On("GetBookingByID", int64(1)).Return(&current, nil)
 	repoMock.On("UpdateBooking", booking).Return(assert.AnError)
 
-	err := svc.UpdateBooking(booking)
+	err := svc.UpdateBooking(context.Background(), booking)
 	assert.NotNil(t, err)
 	repoMock.AssertExpectations(t)
 }
 
 func TestDeleteBookingWithError(t *testing.T) {
-	repoMock, _, svc := setup()
+	repoMock, svc := setup()
 
 	repoMock.On("DeleteBooking", int64(1)).Return(assert.AnError)
 
@@ -171,13 +182,362 @@ func TestDeleteBookingWithError(t *testing.T) {
 }
 
 func TestListBookingsWithError(t *testing.T) {
-	repoMock, _, svc := setup()
+	repoMock, svc := setup()
 
-	filters := make(map[string]interface{})
-	repoMock.On("ListBookings", 0, 10, filters, "id", "asc").Return(nil, assert.AnError)
+	q, _ := query.Parse(nil, "id", 0, 10)
+	repoMock.On("ListBookings", q).Return(nil, query.Metadata{}, assert.AnError)
 
-	result, err := svc.ListBookings(0, 10, filters, "id", "asc")
+	result, _, err := svc.ListBookings(model.AllBookings(), q)
 	assert.NotNil(t, err)
 	assert.Nil(t, result)
 	repoMock.AssertExpectations(t)
 }
+
+func TestCreateBookingOverlap(t *testing.T) {
+	repoMock, svc := setup()
+
+	booking := &model.Booking{
+		ClientID:  1,
+		RoomID:    1,
+		StartDate: time.Now(),
+		EndDate:   time.Now().Add(24 * time.Hour),
+		Status:    "confirmed",
+	}
+
+	repoMock.On("CreateBooking", booking).Return(model.ErrBookingOverlap)
+
+	err := svc.CreateBooking(context.Background(), model.AllBookings(), booking)
+	assert.ErrorIs(t, err, model.ErrBookingOverlap)
+	repoMock.AssertExpectations(t)
+}
+
+func TestCreateBookingInvalidDateRange(t *testing.T) {
+	repoMock, svc := setup()
+
+	booking := &model.Booking{
+		ClientID:  1,
+		RoomID:    1,
+		StartDate: time.Now(),
+		EndDate:   time.Now().Add(-24 * time.Hour),
+		Status:    "confirmed",
+	}
+
+	err := svc.CreateBooking(context.Background(), model.AllBookings(), booking)
+	assert.ErrorIs(t, err, model.ErrInvalidDateRange)
+	repoMock.AssertNotCalled(t, "CreateBooking", booking)
+}
+
+func TestCreateBookingRejectsBadReferences(t *testing.T) {
+	tests := []struct {
+		name      string
+		clientErr error
+		roomErr   error
+		want      error
+	}{
+		{"missing client", model.ErrClientNotFound, nil, model.ErrClientNotFound},
+		{"inactive client", model.ErrClientInactive, nil, model.ErrClientInactive},
+		{"missing room", nil, model.ErrRoomNotFound, model.ErrRoomNotFound},
+		{"unavailable room", nil, model.ErrRoomUnavailable, model.ErrRoomUnavailable},
+		{"dependency down", nil, model.ErrReferenceCheckFailed, model.ErrReferenceCheckFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repoMock := new(repository.BookingRepositoryMock)
+			refsMock := new(service.ReferenceCheckerMock)
+			refsMock.On("CheckClient", mock.Anything, int64(1)).Return(tt.clientErr)
+			refsMock.On("CheckRoom", mock.Anything, int64(2)).Return(tt.roomErr)
+			svc := service.NewBookingService(repoMock, refsMock, nil, 0, 0)
+
+			booking := &model.Booking{
+				ClientID:  1,
+				RoomID:    2,
+				StartDate: time.Now(),
+				EndDate:   time.Now().Add(24 * time.Hour),
+			}
+
+			err := svc.CreateBooking(context.Background(), model.AllBookings(), booking)
+			assert.ErrorIs(t, err, tt.want)
+			repoMock.AssertNotCalled(t, "CreateBooking", mock.Anything)
+		})
+	}
+}
+
+func TestCreateBookingChecksScopedClient(t *testing.T) {
+	repoMock := new(repository.BookingRepositoryMock)
+	refsMock := new(service.ReferenceCheckerMock)
+	refsMock.On("CheckClient", mock.Anything, int64(7)).Return(nil)
+	refsMock.On("CheckRoom", mock.Anything, int64(2)).Return(nil)
+	svc := service.NewBookingService(repoMock, refsMock, nil, 0, 0)
+
+	booking := &model.Booking{
+		ClientID:  99,
+		RoomID:    2,
+		StartDate: time.Now(),
+		EndDate:   time.Now().Add(24 * time.Hour),
+	}
+	repoMock.On("CreateBooking", booking).Return(nil)
+
+	err := svc.CreateBooking(context.Background(), model.OwnBookings(7), booking)
+	assert.Nil(t, err)
+	refsMock.AssertExpectations(t)
+}
+
+func TestUpdateBookingOverlap(t *testing.T) {
+	repoMock, svc := setup()
+
+	booking := &model.Booking{
+		ID:        1,
+		ClientID:  1,
+		RoomID:    2,
+		StartDate: time.Now(),
+		EndDate:   time.Now().Add(24 * time.Hour),
+		Status:    "confirmed",
+	}
+
+	current := *booking
+	repoMock.On("GetBookingByID", int64(1)).Return(&current, nil)
+	repoMock.On("UpdateBooking", booking).Return(model.ErrBookingOverlap)
+
+	err := svc.UpdateBooking(context.Background(), booking)
+	assert.ErrorIs(t, err, model.ErrBookingOverlap)
+	repoMock.AssertExpectations(t)
+}
+
+func TestUpdateBookingStaleVersion(t *testing.T) {
+	repoMock, svc := setup()
+
+	booking := &model.Booking{
+		ID:        1,
+		ClientID:  1,
+		RoomID:    2,
+		StartDate: time.Now(),
+		EndDate:   time.Now().Add(24 * time.Hour),
+		Version:   2,
+	}
+
+	current := *booking
+	repoMock.On("GetBookingByID", int64(1)).Return(&current, nil)
+	repoMock.On("UpdateBooking", mock.MatchedBy(func(b *model.Booking) bool {
+		return b.ID == 1 && b.Version == 2
+	})).Return(model.ErrEditConflict)
+
+	err := svc.UpdateBooking(context.Background(), booking)
+	assert.ErrorIs(t, err, model.ErrEditConflict)
+}
+
+func TestTransitionBumpsVersion(t *testing.T) {
+	repoMock, svc := setup()
+
+	booking := &model.Booking{ID: 1, Status: model.StatusPending, Version: 3}
+	repoMock.On("GetBookingByID", int64(1)).Return(booking, nil)
+	repoMock.On("TransitionStatus", mock.Anything).Return(nil)
+
+	confirmed, err := svc.ConfirmBooking(model.AllBookings(), 1, "admin")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, confirmed.Version)
+}
+
+func TestConfirmBooking(t *testing.T) {
+	repoMock, svc := setup()
+
+	booking := &model.Booking{
+		ID:        1,
+		ClientID:  1,
+		RoomID:    1,
+		StartDate: time.Now(),
+		EndDate:   time.Now().Add(24 * time.Hour),
+		Status:    model.StatusPending,
+	}
+
+	repoMock.On("GetBookingByID", int64(1)).Return(booking, nil)
+	repoMock.On("TransitionStatus", mock.MatchedBy(func(change *model.StatusChange) bool {
+		return change.BookingID == 1 &&
+			change.FromStatus == model.StatusPending &&
+			change.ToStatus == model.StatusConfirmed &&
+			change.ChangedBy == "admin" &&
+			!change.ChangedAt.IsZero()
+	})).Return(nil)
+
+	result, err := svc.ConfirmBooking(model.AllBookings(), 1, "admin")
+	assert.Nil(t, err)
+	assert.Equal(t, model.StatusConfirmed, result.Status)
+	repoMock.AssertExpectations(t)
+}
+
+func TestCancelBookingRecordsReason(t *testing.T) {
+	repoMock, svc := setup()
+
+	booking := &model.Booking{
+		ID:        1,
+		ClientID:  1,
+		RoomID:    1,
+		StartDate: time.Now(),
+		EndDate:   time.Now().Add(24 * time.Hour),
+		Status:    model.StatusConfirmed,
+	}
+
+	repoMock.On("GetBookingByID", int64(1)).Return(booking, nil)
+	repoMock.On("TransitionStatus", mock.MatchedBy(func(change *model.StatusChange) bool {
+		return change.ToStatus == model.StatusCancelled && change.Reason == "change of plans"
+	})).Return(nil)
+
+	result, err := svc.CancelBooking(model.AllBookings(), 1, "client", "change of plans")
+	assert.Nil(t, err)
+	assert.Equal(t, model.StatusCancelled, result.Status)
+	repoMock.AssertExpectations(t)
+}
+
+func TestCheckInPendingBookingIsRejected(t *testing.T) {
+	repoMock, svc := setup()
+
+	booking := &model.Booking{
+		ID:        1,
+		ClientID:  1,
+		RoomID:    1,
+		StartDate: time.Now(),
+		EndDate:   time.Now().Add(24 * time.Hour),
+		Status:    model.StatusPending,
+	}
+
+	repoMock.On("GetBookingByID", int64(1)).Return(booking, nil)
+
+	_, err := svc.CheckInBooking(model.AllBookings(), 1, "admin")
+	assert.ErrorIs(t, err, model.ErrInvalidTransition)
+	repoMock.AssertNotCalled(t, "TransitionStatus", mock.Anything)
+}
+
+func TestCheckOutMissingBooking(t *testing.T) {
+	repoMock, svc := setup()
+
+	repoMock.On("GetBookingByID", int64(1)).Return(nil, nil)
+
+	_, err := svc.CheckOutBooking(model.AllBookings(), 1, "admin")
+	assert.ErrorIs(t, err, model.ErrBookingNotFound)
+}
+
+func TestClientCannotGetAnotherClientsBooking(t *testing.T) {
+	repoMock, svc := setup()
+
+	booking := &model.Booking{ID: 1, ClientID: 2, RoomID: 1, Status: model.StatusPending}
+	repoMock.On("GetBookingByID", int64(1)).Return(booking, nil)
+
+	result, err := svc.GetBookingByID(model.OwnBookings(1), 1)
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+
+	result, err = svc.GetBookingByID(model.OwnBookings(2), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, booking, result)
+}
+
+func TestClientListIsScopedToOwnBookings(t *testing.T) {
+	repoMock, svc := setup()
+
+	q, err := query.Parse([]query.Filter{{Key: "client_id", Value: "2"}, {Key: "status", Value: "pending"}}, "", 0, 10)
+	assert.Nil(t, err)
+	repoMock.On("ListBookings", mock.MatchedBy(func(scoped *query.Query) bool {
+		where, args := scoped.Where()
+		return where == "status = $1 AND client_id = $2" && args[1] == int64(1)
+	})).Return([]*model.Booking{}, query.Metadata{}, nil)
+
+	_, _, err = svc.ListBookings(model.OwnBookings(1), q)
+	assert.Nil(t, err)
+	assert.Equal(t, "client_id", q.Conditions[0].Field)
+	assert.Equal(t, int64(2), q.Conditions[0].Values[0])
+	repoMock.AssertExpectations(t)
+}
+
+func TestClientCreatesBookingForThemselves(t *testing.T) {
+	repoMock, svc := setup()
+
+	booking := &model.Booking{
+		ClientID:  2,
+		RoomID:    1,
+		StartDate: time.Now(),
+		EndDate:   time.Now().Add(24 * time.Hour),
+	}
+	repoMock.On("CreateBooking", booking).Return(nil)
+
+	err := svc.CreateBooking(context.Background(), model.OwnBookings(1), booking)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), booking.ClientID)
+}
+
+func TestClientCannotCancelAnotherClientsBooking(t *testing.T) {
+	repoMock, svc := setup()
+
+	booking := &model.Booking{ID: 1, ClientID: 2, RoomID: 1, Status: model.StatusConfirmed}
+	repoMock.On("GetBookingByID", int64(1)).Return(booking, nil)
+
+	_, err := svc.CancelBooking(model.OwnBookings(1), 1, "1", "")
+	assert.ErrorIs(t, err, model.ErrBookingNotFound)
+	repoMock.AssertNotCalled(t, "TransitionStatus", mock.Anything)
+}
+
+func TestZeroScopeMatchesNothing(t *testing.T) {
+	assert.False(t, model.Scope{}.Includes(&model.Booking{ClientID: 0}))
+	assert.True(t, model.AllBookings().Includes(&model.Booking{ClientID: 5}))
+}
+
+func TestCanTransition(t *testing.T) {
+	assert.True(t, model.CanTransition(model.StatusPending, model.StatusConfirmed))
+	assert.True(t, model.CanTransition(model.StatusConfirmed, model.StatusCheckedIn))
+	assert.True(t, model.CanTransition(model.StatusCheckedIn, model.StatusCheckedOut))
+	assert.True(t, model.CanTransition(model.StatusConfirmed, model.StatusNoShow))
+	assert.False(t, model.CanTransition(model.StatusPending, model.StatusCheckedIn))
+	assert.False(t, model.CanTransition(model.StatusCheckedOut, model.StatusCancelled))
+	assert.False(t, model.CanTransition(model.StatusCancelled, model.StatusConfirmed))
+	assert.True(t, model.CanTransition(model.StatusHeld, model.StatusPending))
+	assert.True(t, model.CanTransition(model.StatusHeld, model.StatusHoldExpired))
+	assert.False(t, model.CanTransition(model.StatusHoldExpired, model.StatusPending))
+}
+
+func TestCancelUpcomingForClient(t *testing.T) {
+	repoMock, svc := setup()
+
+	upcoming := &model.Booking{ID: 1, ClientID: 5, Status: model.StatusConfirmed}
+	// Booking 2 was checked in after it was listed, so it must be left alone.
+	raced := &model.Booking{ID: 2, ClientID: 5, Status: model.StatusCheckedIn}
+
+	repoMock.On("ListBookings", mock.MatchedBy(func(q *query.Query) bool {
+		where, args := q.Where()
+		return strings.Contains(where, "client_id") && args[0] == int64(5)
+	})).Return([]*model.Booking{upcoming, raced}, query.Metadata{TotalRecords: 2}, nil)
+	repoMock.On("GetBookingByID", int64(1)).Return(upcoming, nil)
+	repoMock.On("GetBookingByID", int64(2)).Return(raced, nil)
+	repoMock.On("TransitionStatus", mock.MatchedBy(func(change *model.StatusChange) bool {
+		return change.BookingID == 1 &&
+			change.ToStatus == model.StatusCancelled &&
+			change.ChangedBy == service.SystemActor &&
+			change.Reason == "client deleted" &&
+			change.CorrelationID == "flow-1"
+	})).Return(nil)
+
+	cancelled, err := svc.CancelUpcomingForClient(5, "client deleted", "flow-1")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, cancelled)
+	repoMock.AssertExpectations(t)
+}
+
+func TestCancelUpcomingForRoomFollowsCursor(t *testing.T) {
+	repoMock, svc := setup()
+
+	first := &model.Booking{ID: 1, RoomID: 3, Status: model.StatusPending}
+	second := &model.Booking{ID: 2, RoomID: 3, Status: model.StatusConfirmed}
+
+	// Cursors are opaque, so take a real one from a query of the same shape.
+	cursorQuery, _ := query.Parse(nil, "id", 0, query.MaxLimit)
+	next := cursorQuery.Cursor([]interface{}{int64(1)})
+
+	repoMock.On("ListBookings", mock.Anything).Return([]*model.Booking{first}, query.Metadata{TotalRecords: 2, NextCursor: next}, nil).Once()
+	repoMock.On("ListBookings", mock.Anything).Return([]*model.Booking{second}, query.Metadata{TotalRecords: 2}, nil).Once()
+	repoMock.On("GetBookingByID", int64(1)).Return(first, nil)
+	repoMock.On("GetBookingByID", int64(2)).Return(second, nil)
+	repoMock.On("TransitionStatus", mock.Anything).Return(nil)
+
+	cancelled, err := svc.CancelUpcomingForRoom(3, "room deleted", "")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, cancelled)
+	repoMock.AssertNumberOfCalls(t, "ListBookings", 2)
+}
@@ -0,0 +1,141 @@
+package service_test
+
+import (
+	"booking/internal/domain/model"
+	"booking/internal/service"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHoldRoomSetsExpiry(t *testing.T) {
+	repoMock, svc := setup()
+
+	booking := &model.Booking{
+		ClientID:  1,
+		RoomID:    1,
+		StartDate: time.Now(),
+		EndDate:   time.Now().Add(24 * time.Hour),
+	}
+	repoMock.On("CreateBooking", booking).Return(nil)
+
+	before := time.Now()
+	err := svc.HoldRoom(context.Background(), model.AllBookings(), booking)
+	assert.Nil(t, err)
+	assert.Equal(t, model.StatusHeld, booking.Status)
+	if assert.NotNil(t, booking.HoldExpiresAt) {
+		assert.WithinDuration(t, before.Add(service.DefaultHoldTTL), *booking.HoldExpiresAt, time.Second)
+	}
+	repoMock.AssertExpectations(t)
+}
+
+func TestCreateBookingIgnoresHoldExpiry(t *testing.T) {
+	repoMock, svc := setup()
+
+	expiresAt := time.Now().Add(time.Hour)
+	booking := &model.Booking{
+		ClientID:      1,
+		RoomID:        1,
+		StartDate:     time.Now(),
+		EndDate:       time.Now().Add(24 * time.Hour),
+		HoldExpiresAt: &expiresAt,
+	}
+	repoMock.On("CreateBooking", booking).Return(nil)
+
+	err := svc.CreateBooking(context.Background(), model.AllBookings(), booking)
+	assert.Nil(t, err)
+	assert.Equal(t, model.StatusPending, booking.Status)
+	assert.Nil(t, booking.HoldExpiresAt)
+}
+
+func TestHoldRoomOverlap(t *testing.T) {
+	repoMock, svc := setup()
+
+	booking := &model.Booking{ClientID: 1, RoomID: 1, StartDate: time.Now(), EndDate: time.Now().Add(24 * time.Hour)}
+	repoMock.On("CreateBooking", booking).Return(model.ErrBookingOverlap)
+
+	err := svc.HoldRoom(context.Background(), model.AllBookings(), booking)
+	assert.ErrorIs(t, err, model.ErrBookingOverlap)
+}
+
+func TestConfirmHold(t *testing.T) {
+	repoMock, svc := setup()
+
+	expiresAt := time.Now().Add(time.Minute)
+	hold := &model.Booking{ID: 1, ClientID: 1, RoomID: 1, Status: model.StatusHeld, HoldExpiresAt: &expiresAt}
+
+	repoMock.On("GetBookingByID", int64(1)).Return(hold, nil)
+	repoMock.On("TransitionStatus", mock.MatchedBy(func(change *model.StatusChange) bool {
+		return change.BookingID == 1 &&
+			change.FromStatus == model.StatusHeld &&
+			change.ToStatus == model.StatusPending &&
+			change.ChangedBy == "user:1"
+	})).Return(nil)
+
+	result, err := svc.ConfirmHold(model.OwnBookings(1), 1, "user:1")
+	assert.Nil(t, err)
+	assert.Equal(t, model.StatusPending, result.Status)
+	repoMock.AssertExpectations(t)
+}
+
+func TestConfirmExpiredHold(t *testing.T) {
+	repoMock, svc := setup()
+
+	expiresAt := time.Now().Add(-time.Second)
+	hold := &model.Booking{ID: 1, ClientID: 1, RoomID: 1, Status: model.StatusHeld, HoldExpiresAt: &expiresAt}
+	repoMock.On("GetBookingByID", int64(1)).Return(hold, nil)
+
+	_, err := svc.ConfirmHold(model.AllBookings(), 1, "admin")
+	assert.ErrorIs(t, err, model.ErrHoldExpired)
+	repoMock.AssertNotCalled(t, "TransitionStatus", mock.Anything)
+}
+
+func TestConfirmHoldWithoutExpiry(t *testing.T) {
+	repoMock, svc := setup()
+
+	hold := &model.Booking{ID: 1, ClientID: 1, RoomID: 1, Status: model.StatusHeld}
+	repoMock.On("GetBookingByID", int64(1)).Return(hold, nil)
+	repoMock.On("TransitionStatus", mock.Anything).Return(nil)
+
+	result, err := svc.ConfirmHold(model.AllBookings(), 1, "admin")
+	assert.Nil(t, err)
+	assert.Equal(t, model.StatusPending, result.Status)
+}
+
+func TestConfirmHoldOfBooking(t *testing.T) {
+	repoMock, svc := setup()
+
+	booking := &model.Booking{ID: 1, ClientID: 1, RoomID: 1, Status: model.StatusConfirmed}
+	repoMock.On("GetBookingByID", int64(1)).Return(booking, nil)
+
+	_, err := svc.ConfirmHold(model.AllBookings(), 1, "admin")
+	assert.ErrorIs(t, err, model.ErrInvalidTransition)
+}
+
+func TestExpireHolds(t *testing.T) {
+	repoMock, svc := setup()
+
+	expiresAt := time.Now().Add(-time.Minute)
+	lapsed := &model.Booking{ID: 1, Status: model.StatusHeld, HoldExpiresAt: &expiresAt}
+	// Hold 2 was confirmed after it was listed, so it must be left alone.
+	confirmed := &model.Booking{ID: 2, Status: model.StatusPending, HoldExpiresAt: &expiresAt}
+
+	repoMock.On("ListExpiredHolds", mock.Anything, mock.Anything).Return([]*model.Booking{lapsed, confirmed}, nil)
+	repoMock.On("GetBookingByID", int64(1)).Return(lapsed, nil)
+	repoMock.On("GetBookingByID", int64(2)).Return(confirmed, nil)
+	repoMock.On("TransitionStatus", mock.MatchedBy(func(change *model.StatusChange) bool {
+		return change.BookingID == 1 &&
+			change.FromStatus == model.StatusHeld &&
+			change.ToStatus == model.StatusHoldExpired &&
+			change.ChangedBy == service.SystemActor
+	})).Return(nil)
+
+	expired, err := svc.ExpireHolds()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, expired)
+	repoMock.AssertExpectations(t)
+	repoMock.AssertNumberOfCalls(t, "ListExpiredHolds", 1)
+}
@@ -0,0 +1,153 @@
+package service_test
+
+import (
+	"booking/internal/domain/model"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newIdempotentBooking() *model.Booking {
+	start := time.Date(2030, 1, 1, 14, 0, 0, 0, time.UTC)
+	return &model.Booking{ClientID: 1, RoomID: 2, StartDate: start, EndDate: start.Add(48 * time.Hour)}
+}
+
+func TestCreateBookingIdempotentStoresKey(t *testing.T) {
+	repoMock, svc := setup()
+	booking := newIdempotentBooking()
+
+	repoMock.On("GetIdempotencyKey", "7", "key-1").Return(nil, nil)
+	repoMock.On("CreateBookingWithKey", booking, mock.MatchedBy(func(key *model.IdempotencyKey) bool {
+		return key.Owner == "7" &&
+			key.Key == "key-1" &&
+			key.RequestHash == model.HashBookingRequest(booking) &&
+			key.ExpiresAt.After(time.Now().Add(23*time.Hour))
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*model.Booking).ID = 10
+	}).Return(nil)
+
+	replayed, err := svc.CreateBookingIdempotent(context.Background(), model.AllBookings(), "7", "key-1", booking)
+	assert.Nil(t, err)
+	assert.False(t, replayed)
+	assert.Equal(t, int64(10), booking.ID)
+	assert.Equal(t, model.StatusPending, booking.Status)
+}
+
+func TestCreateBookingIdempotentReplays(t *testing.T) {
+	repoMock, svc := setup()
+
+	original := newIdempotentBooking()
+	original.ID = 10
+	original.Status = model.StatusConfirmed
+	response, _ := json.Marshal(original)
+	repoMock.On("GetIdempotencyKey", "7", "key-1").Return(&model.IdempotencyKey{
+		Owner:       "7",
+		Key:         "key-1",
+		RequestHash: model.HashBookingRequest(original),
+		BookingID:   10,
+		Response:    response,
+	}, nil)
+
+	retry := newIdempotentBooking()
+	replayed, err := svc.CreateBookingIdempotent(context.Background(), model.AllBookings(), "7", "key-1", retry)
+	assert.Nil(t, err)
+	assert.True(t, replayed)
+	assert.Equal(t, int64(10), retry.ID)
+	assert.Equal(t, model.StatusConfirmed, retry.Status)
+	repoMock.AssertNotCalled(t, "CreateBookingWithKey", mock.Anything, mock.Anything)
+}
+
+func TestCreateBookingIdempotentRejectsReusedKey(t *testing.T) {
+	repoMock, svc := setup()
+
+	original := newIdempotentBooking()
+	repoMock.On("GetIdempotencyKey", "7", "key-1").Return(&model.IdempotencyKey{
+		RequestHash: model.HashBookingRequest(original),
+	}, nil)
+
+	other := newIdempotentBooking()
+	other.RoomID = 3
+	_, err := svc.CreateBookingIdempotent(context.Background(), model.AllBookings(), "7", "key-1", other)
+	assert.ErrorIs(t, err, model.ErrIdempotencyKeyReused)
+}
+
+// The repository claims the key before inserting the booking, so a request
+// racing another with the same key loses on the key, not on the room.
+func TestCreateBookingIdempotentLosesRace(t *testing.T) {
+	repoMock, svc := setup()
+	booking := newIdempotentBooking()
+
+	winner := newIdempotentBooking()
+	winner.ID = 11
+	response, _ := json.Marshal(winner)
+
+	repoMock.On("GetIdempotencyKey", "7", "key-1").Return(nil, nil).Once()
+	repoMock.On("CreateBookingWithKey", booking, mock.Anything).Return(model.ErrIdempotencyKeyExists)
+	repoMock.On("GetIdempotencyKey", "7", "key-1").Return(&model.IdempotencyKey{
+		RequestHash: model.HashBookingRequest(winner),
+		Response:    response,
+	}, nil).Once()
+
+	replayed, err := svc.CreateBookingIdempotent(context.Background(), model.AllBookings(), "7", "key-1", booking)
+	assert.Nil(t, err)
+	assert.True(t, replayed)
+	assert.Equal(t, int64(11), booking.ID)
+}
+
+// The key was claimed by a request that has not committed its booking yet, or
+// that rolled back, so there is nothing to replay.
+func TestCreateBookingIdempotentKeyInProgress(t *testing.T) {
+	repoMock, svc := setup()
+	booking := newIdempotentBooking()
+
+	repoMock.On("GetIdempotencyKey", "7", "key-1").Return(nil, nil)
+	repoMock.On("CreateBookingWithKey", booking, mock.Anything).Return(model.ErrIdempotencyKeyExists)
+
+	replayed, err := svc.CreateBookingIdempotent(context.Background(), model.AllBookings(), "7", "key-1", booking)
+	assert.ErrorIs(t, err, model.ErrIdempotencyKeyInProgress)
+	assert.False(t, replayed)
+	repoMock.AssertNumberOfCalls(t, "GetIdempotencyKey", 2)
+}
+
+func TestCreateBookingIdempotentHashesScopedClient(t *testing.T) {
+	repoMock, svc := setup()
+
+	// A client's retry may send another client_id; the booking is theirs anyway.
+	sent := newIdempotentBooking()
+	sent.ClientID = 99
+	stored := newIdempotentBooking()
+	stored.ClientID = 7
+	response, _ := json.Marshal(stored)
+	repoMock.On("GetIdempotencyKey", "7", "key-1").Return(&model.IdempotencyKey{
+		RequestHash: model.HashBookingRequest(stored),
+		Response:    response,
+	}, nil)
+
+	replayed, err := svc.CreateBookingIdempotent(context.Background(), model.OwnBookings(7), "7", "key-1", sent)
+	assert.Nil(t, err)
+	assert.True(t, replayed)
+}
+
+func TestCreateBookingIdempotentRejectsLongKey(t *testing.T) {
+	_, svc := setup()
+
+	_, err := svc.CreateBookingIdempotent(context.Background(), model.AllBookings(), "7", strings.Repeat("k", model.MaxIdempotencyKeyLength+1), newIdempotentBooking())
+	assert.ErrorIs(t, err, model.ErrInvalidIdempotencyKey)
+}
+
+func TestCreateBookingWithoutKey(t *testing.T) {
+	repoMock, svc := setup()
+	booking := newIdempotentBooking()
+
+	repoMock.On("CreateBooking", booking).Return(nil)
+
+	replayed, err := svc.CreateBookingIdempotent(context.Background(), model.AllBookings(), "7", "", booking)
+	assert.Nil(t, err)
+	assert.False(t, replayed)
+	repoMock.AssertNotCalled(t, "GetIdempotencyKey", mock.Anything, mock.Anything)
+}
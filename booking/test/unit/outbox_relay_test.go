@@ -0,0 +1,68 @@
+package service_test
+
+import (
+	"booking/internal/domain/model"
+	"booking/internal/repository"
+	"booking/internal/service"
+	"booking/internal/transport/messaging"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupRelay(batchSize int) (*repository.OutboxRepositoryMock, *messaging.BookingMessagingMock, *service.OutboxRelay) {
+	outboxMock := new(repository.OutboxRepositoryMock)
+	messagingMock := new(messaging.BookingMessagingMock)
+	relay := service.NewOutboxRelay(outboxMock, messagingMock, time.Second, batchSize)
+	return outboxMock, messagingMock, relay
+}
+
+func TestRelayPendingPublishesMessages(t *testing.T) {
+	outboxMock, messagingMock, relay := setupRelay(10)
+
+	messages := []*model.OutboxMessage{
+		{ID: 1, RoutingKey: "booking.created", Payload: []byte(`{"id":1}`)},
+		{ID: 2, RoutingKey: "booking.confirmed", Payload: []byte(`{"id":1}`)},
+	}
+	outboxMock.On("ProcessPending", 10).Return(messages, nil)
+	messagingMock.On("Publish", "booking.created", []byte(`{"id":1}`)).Return(nil)
+	messagingMock.On("Publish", "booking.confirmed", []byte(`{"id":1}`)).Return(nil)
+
+	sent, err := relay.RelayPending()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, sent)
+	outboxMock.AssertNumberOfCalls(t, "ProcessPending", 1)
+	messagingMock.AssertExpectations(t)
+}
+
+func TestRelayPendingStopsAtFirstFailure(t *testing.T) {
+	outboxMock, messagingMock, relay := setupRelay(10)
+
+	messages := []*model.OutboxMessage{
+		{ID: 1, RoutingKey: "booking.created", Payload: []byte(`{"id":1}`)},
+		{ID: 2, RoutingKey: "booking.confirmed", Payload: []byte(`{"id":1}`)},
+	}
+	outboxMock.On("ProcessPending", 10).Return(messages, nil)
+	messagingMock.On("Publish", "booking.created", mock.Anything).Return(assert.AnError)
+
+	sent, err := relay.RelayPending()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, sent)
+	messagingMock.AssertNotCalled(t, "Publish", "booking.confirmed", mock.Anything)
+}
+
+func TestRelayPendingDrainsFullBatches(t *testing.T) {
+	outboxMock, messagingMock, relay := setupRelay(1)
+
+	first := []*model.OutboxMessage{{ID: 1, RoutingKey: "booking.created", Payload: []byte(`{}`)}}
+	outboxMock.On("ProcessPending", 1).Return(first, nil).Once()
+	outboxMock.On("ProcessPending", 1).Return(nil, nil).Once()
+	messagingMock.On("Publish", "booking.created", mock.Anything).Return(nil)
+
+	sent, err := relay.RelayPending()
+	assert.Nil(t, err)
+	assert.Equal(t, 1, sent)
+	outboxMock.AssertNumberOfCalls(t, "ProcessPending", 2)
+}
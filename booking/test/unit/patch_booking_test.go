@@ -0,0 +1,94 @@
+package service_test
+
+import (
+	"Booking_System/common/auth"
+	"Booking_System/common/patch"
+	"booking/internal/domain/model"
+	grpcTransport "booking/internal/transport/grpc"
+	pb "booking/proto"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func storedBooking() *model.Booking {
+	start := time.Date(2030, 3, 1, 14, 0, 0, 0, time.UTC)
+	return &model.Booking{ID: 1, ClientID: 5, RoomID: 2, StartDate: start, EndDate: start.Add(24 * time.Hour), Status: model.StatusConfirmed, Version: 4}
+}
+
+func TestPatchBookingKeepsOmittedFields(t *testing.T) {
+	repoMock, svc := setup()
+
+	repoMock.On("GetBookingByID", int64(1)).Return(storedBooking(), nil)
+	repoMock.On("UpdateBooking", mock.MatchedBy(func(b *model.Booking) bool {
+		// Only the room changes; the loaded version guards the write.
+		return b.ID == 1 && b.ClientID == 5 && b.RoomID == 3 && b.Version == 4
+	})).Return(nil)
+
+	booking, err := svc.PatchBooking(context.Background(), 1, 0, func(b *model.Booking) error {
+		return patch.Apply(b, []byte(`{"room_id": 3}`))
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), booking.ClientID)
+	assert.Equal(t, int64(3), booking.RoomID)
+	repoMock.AssertExpectations(t)
+}
+
+func TestPatchBookingUsesGivenVersion(t *testing.T) {
+	repoMock, svc := setup()
+
+	repoMock.On("GetBookingByID", int64(1)).Return(storedBooking(), nil)
+	repoMock.On("UpdateBooking", mock.MatchedBy(func(b *model.Booking) bool {
+		return b.Version == 3
+	})).Return(model.ErrEditConflict)
+
+	_, err := svc.PatchBooking(context.Background(), 1, 3, func(b *model.Booking) error {
+		return patch.Apply(b, []byte(`{"room_id": 3}`))
+	})
+	assert.ErrorIs(t, err, model.ErrEditConflict)
+}
+
+func TestPatchBookingRejectsReadOnlyFields(t *testing.T) {
+	for _, body := range []string{
+		`{"status": "cancelled"}`, `{"id": 2}`, `{"version": 9}`, `{"total_price": 1}`, `{"currency": "USD"}`,
+	} {
+		repoMock, svc := setup()
+		repoMock.On("GetBookingByID", int64(1)).Return(storedBooking(), nil)
+
+		_, err := svc.PatchBooking(context.Background(), 1, 0, func(b *model.Booking) error {
+			return patch.Apply(b, []byte(body))
+		})
+		assert.ErrorIs(t, err, model.ErrReadOnlyField, body)
+		repoMock.AssertNotCalled(t, "UpdateBooking", mock.Anything)
+	}
+}
+
+func TestPatchMissingBooking(t *testing.T) {
+	repoMock, svc := setup()
+
+	repoMock.On("GetBookingByID", int64(1)).Return(nil, nil)
+
+	_, err := svc.PatchBooking(context.Background(), 1, 0, func(b *model.Booking) error { return nil })
+	assert.ErrorIs(t, err, model.ErrBookingNotFound)
+}
+
+func TestPatchBookingGRPCRejectsMissingMaskedDate(t *testing.T) {
+	repoMock, svc := setup()
+	server := grpcTransport.NewBookingGRPCServer(svc)
+	ctx := auth.WithIdentity(context.Background(), &auth.Identity{UserID: 1, Role: auth.RoleAdmin})
+
+	for _, path := range []string{"start_date", "end_date"} {
+		_, err := server.UpdateBooking(ctx, &pb.UpdateBookingRequest{
+			Id:         1,
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{path}},
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), path)
+	}
+	repoMock.AssertNotCalled(t, "UpdateBooking", mock.Anything)
+}
@@ -0,0 +1,231 @@
+package service_test
+
+import (
+	"booking/internal/domain/model"
+	"booking/internal/pricing"
+	"booking/internal/repository"
+	"booking/internal/service"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func day(value string) time.Time {
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func suitePlan() pricing.RatePlan {
+	return pricing.RatePlan{
+		Name:          "suites",
+		RoomType:      "suite",
+		Currency:      "EUR",
+		WeekdayRate:   10000,
+		WeekendRate:   12000,
+		Seasons:       []pricing.Season{{Name: "winter", From: "12-20", To: "01-05", WeekdayRate: 15000}},
+		StayDiscounts: []pricing.StayDiscount{{Name: "short", MinNights: 2, Percent: 5}, {Name: "long", MinNights: 3, Percent: 10}},
+		Taxes:         []pricing.Tax{{Name: "VAT", Percent: 10}, {Name: "city tax", PerNight: 200}},
+	}
+}
+
+func TestQuoteItemisesWeekendsDiscountsAndTaxes(t *testing.T) {
+	plan := suitePlan()
+
+	// Wednesday to Sunday: two weekday and two weekend nights.
+	quote, err := plan.Quote(day("2026-03-04"), day("2026-03-08"))
+	assert.NoError(t, err)
+	assert.Equal(t, "EUR", quote.Currency)
+	assert.Equal(t, []pricing.Night{
+		{Date: "2026-03-04", Rate: 10000},
+		{Date: "2026-03-05", Rate: 10000},
+		{Date: "2026-03-06", Weekend: true, Rate: 12000},
+		{Date: "2026-03-07", Weekend: true, Rate: 12000},
+	}, quote.Nights)
+	assert.Equal(t, int64(44000), quote.Subtotal)
+	assert.Equal(t, []pricing.Charge{{Name: "long", Amount: 4400}}, quote.Discounts)
+	assert.Equal(t, []pricing.Charge{{Name: "VAT", Amount: 3960}, {Name: "city tax", Amount: 800}}, quote.Taxes)
+	assert.Equal(t, int64(44360), quote.Total)
+}
+
+func TestQuoteSeasonRunsOverNewYear(t *testing.T) {
+	plan := suitePlan()
+	plan.StayDiscounts, plan.Taxes = nil, nil
+
+	quote, err := plan.Quote(day("2026-12-31"), day("2027-01-02"))
+	assert.NoError(t, err)
+	for _, night := range quote.Nights {
+		assert.Equal(t, "winter", night.Season)
+		assert.Equal(t, int64(15000), night.Rate)
+	}
+	assert.Equal(t, int64(30000), quote.Total)
+}
+
+func TestQuoteRejectsLongStays(t *testing.T) {
+	plan := suitePlan()
+	_, err := plan.Quote(day("2026-01-01"), day("2028-01-01"))
+	assert.ErrorIs(t, err, pricing.ErrStayTooLong)
+}
+
+func TestRatePlanFallsBackToBaseRate(t *testing.T) {
+	plans, err := pricing.NewRatePlans([]pricing.RatePlan{suitePlan()})
+	assert.NoError(t, err)
+
+	plan := plans.For("single")
+	assert.Equal(t, "base", plan.Name)
+	_, err = plan.WithBaseRate(0, "")
+	assert.ErrorIs(t, err, pricing.ErrNoRate)
+
+	plan, err = plan.WithBaseRate(8000, "EUR")
+	assert.NoError(t, err)
+	quote, err := plan.Quote(day("2026-03-06"), day("2026-03-07"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8000), quote.Total)
+	assert.Equal(t, "EUR", quote.Currency)
+}
+
+func TestLoadRatePlansValidates(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	plans, err := pricing.LoadRatePlans(write("ok.json",
+		`[{"name": "suites", "room_type": "suite", "currency": "EUR", "weekday_rate": 18000}]`))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(18000), plans.For("suite").WeekdayRate)
+
+	for name, content := range map[string]string{
+		"currency.json":  `[{"name": "suites", "room_type": "suite", "weekday_rate": 18000}]`,
+		"season.json":    `[{"name": "suites", "seasons": [{"name": "summer", "from": "13-01", "to": "08-31", "weekday_rate": 1}]}]`,
+		"duplicate.json": `[{"name": "a", "room_type": "suite"}, {"name": "b", "room_type": "suite"}]`,
+		"syntax.json":    `{`,
+	} {
+		_, err := pricing.LoadRatePlans(write(name, content))
+		assert.ErrorIs(t, err, pricing.ErrInvalidRatePlan, name)
+	}
+}
+
+// pricedSetup returns a service that prices rooms with suitePlan. The room is
+// only ever looked up through the catalog, never checked separately.
+func pricedSetup(room *model.Room) (*repository.BookingRepositoryMock, *service.BookingService) {
+	repoMock := new(repository.BookingRepositoryMock)
+	refsMock := new(service.ReferenceCheckerMock)
+	refsMock.On("CheckClient", mock.Anything, mock.Anything).Return(nil)
+	roomsMock := new(service.RoomCatalogMock)
+	roomsMock.On("GetRoom", mock.Anything, room.ID).Return(room, nil)
+
+	plans, err := pricing.NewRatePlans([]pricing.RatePlan{suitePlan()})
+	if err != nil {
+		panic(err)
+	}
+	pricingService := service.NewPricingService(roomsMock, plans)
+	return repoMock, service.NewBookingService(repoMock, refsMock, pricingService, 0, 0)
+}
+
+func TestCreateBookingStoresQuotedPrice(t *testing.T) {
+	repoMock, svc := pricedSetup(&model.Room{ID: 7, Type: "suite", Available: true})
+
+	booking := &model.Booking{
+		ClientID:   1,
+		RoomID:     7,
+		StartDate:  day("2026-03-04"),
+		EndDate:    day("2026-03-08"),
+		TotalPrice: 1,
+	}
+	repoMock.On("CreateBooking", booking).Return(nil)
+
+	err := svc.CreateBooking(context.Background(), model.AllBookings(), booking)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(44360), booking.TotalPrice)
+	assert.Equal(t, "EUR", booking.Currency)
+}
+
+func TestCreateBookingWithoutRateIsUnpriced(t *testing.T) {
+	repoMock, svc := pricedSetup(&model.Room{ID: 7, Type: "single", Available: true})
+
+	booking := &model.Booking{ClientID: 1, RoomID: 7, StartDate: day("2026-03-04"), EndDate: day("2026-03-08")}
+	repoMock.On("CreateBooking", booking).Return(nil)
+
+	err := svc.CreateBooking(context.Background(), model.AllBookings(), booking)
+	assert.NoError(t, err)
+	assert.Zero(t, booking.TotalPrice)
+	assert.Empty(t, booking.Currency)
+
+	_, err = svc.QuoteBooking(context.Background(), booking)
+	assert.ErrorIs(t, err, pricing.ErrNoRate)
+}
+
+func TestQuoteBookingRejectsUnavailableRoom(t *testing.T) {
+	_, svc := pricedSetup(&model.Room{ID: 7, Type: "suite"})
+
+	_, err := svc.QuoteBooking(context.Background(), &model.Booking{RoomID: 7, StartDate: day("2026-03-04"), EndDate: day("2026-03-08")})
+	assert.ErrorIs(t, err, model.ErrRoomUnavailable)
+}
+
+func TestUpdateBookingRequotesChangedDates(t *testing.T) {
+	repoMock, svc := pricedSetup(&model.Room{ID: 7, Type: "suite", Available: true})
+
+	stored := &model.Booking{ID: 1, ClientID: 1, RoomID: 7, StartDate: day("2026-03-04"), EndDate: day("2026-03-05"),
+		TotalPrice: 11200, Currency: "EUR", Version: 2}
+	repoMock.On("GetBookingByID", int64(1)).Return(stored, nil)
+	repoMock.On("UpdateBooking", mock.Anything).Return(nil)
+
+	booking := &model.Booking{ID: 1, ClientID: 1, RoomID: 7, StartDate: day("2026-03-04"), EndDate: day("2026-03-08")}
+	err := svc.UpdateBooking(context.Background(), booking)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(44360), booking.TotalPrice)
+	assert.Equal(t, "EUR", booking.Currency)
+}
+
+func TestUpdateBookingKeepsPriceOfSameStay(t *testing.T) {
+	repoMock := new(repository.BookingRepositoryMock)
+	refsMock := new(service.ReferenceCheckerMock)
+	refsMock.On("CheckClient", mock.Anything, mock.Anything).Return(nil)
+	refsMock.On("CheckRoom", mock.Anything, int64(7)).Return(nil)
+	// The catalog expects no calls: the stay is not quoted again.
+	roomsMock := new(service.RoomCatalogMock)
+	plans, err := pricing.NewRatePlans([]pricing.RatePlan{suitePlan()})
+	assert.NoError(t, err)
+	svc := service.NewBookingService(repoMock, refsMock, service.NewPricingService(roomsMock, plans), 0, 0)
+
+	stored := &model.Booking{ID: 1, ClientID: 1, RoomID: 7, StartDate: day("2026-03-04"), EndDate: day("2026-03-08"),
+		TotalPrice: 40000, Currency: "EUR"}
+	repoMock.On("GetBookingByID", int64(1)).Return(stored, nil)
+	repoMock.On("UpdateBooking", mock.Anything).Return(nil)
+
+	// Only the client changes, so the price quoted before is kept.
+	booking := &model.Booking{ID: 1, ClientID: 2, RoomID: 7, StartDate: day("2026-03-04"), EndDate: day("2026-03-08"),
+		TotalPrice: 1, Currency: "USD"}
+	err = svc.UpdateBooking(context.Background(), booking)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(40000), booking.TotalPrice)
+	assert.Equal(t, "EUR", booking.Currency)
+}
+
+func TestPatchBookingRequotesChangedRoom(t *testing.T) {
+	repoMock, svc := pricedSetup(&model.Room{ID: 8, Type: "suite", Available: true})
+
+	stored := &model.Booking{ID: 1, ClientID: 1, RoomID: 7, StartDate: day("2026-03-04"), EndDate: day("2026-03-08"),
+		TotalPrice: 40000, Currency: "USD"}
+	repoMock.On("GetBookingByID", int64(1)).Return(stored, nil)
+	repoMock.On("UpdateBooking", mock.MatchedBy(func(b *model.Booking) bool {
+		return b.RoomID == 8 && b.TotalPrice == 44360 && b.Currency == "EUR"
+	})).Return(nil)
+
+	_, err := svc.PatchBooking(context.Background(), 1, 0, func(b *model.Booking) error {
+		b.RoomID = 8
+		return nil
+	})
+	assert.NoError(t, err)
+	repoMock.AssertExpectations(t)
+}
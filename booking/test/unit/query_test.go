@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"booking/internal/query"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQueryBuildsParameterizedSQL(t *testing.T) {
+	filters := []query.Filter{
+		{Key: "room_id", Value: "3"},
+		{Key: "start_date[gte]", Value: "2024-06-01"},
+		{Key: "status[in]", Value: "pending,confirmed"},
+	}
+
+	q, err := query.Parse(filters, "-start_date,room_id", 20, 10)
+	assert.Nil(t, err)
+
+	where, args := q.Where()
+	assert.Equal(t, "room_id = $1 AND start_date >= $2 AND status IN ($3, $4)", where)
+	assert.Equal(t, []interface{}{int64(3), time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), "pending", "confirmed"}, args)
+	assert.Equal(t, "start_date DESC, room_id ASC, id ASC", q.OrderBy())
+	assert.Equal(t, 20, q.Offset)
+	assert.Equal(t, 10, q.Limit)
+}
+
+func TestParseQueryDefaults(t *testing.T) {
+	q, err := query.Parse(nil, "", 0, 0)
+	assert.Nil(t, err)
+
+	where, args := q.Where()
+	assert.Equal(t, "", where)
+	assert.Empty(t, args)
+	assert.Equal(t, "id ASC", q.OrderBy())
+	assert.Equal(t, query.DefaultLimit, q.Limit)
+}
+
+func TestParseQueryRejectsUnsafeInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters []query.Filter
+		sort    string
+		limit   int
+	}{
+		{name: "unknown field", filters: []query.Filter{{Key: "1=1; DROP TABLE bookings; --", Value: "x"}}},
+		{name: "unknown operator", filters: []query.Filter{{Key: "room_id[like]", Value: "1"}}},
+		{name: "malformed key", filters: []query.Filter{{Key: "room_id[gte", Value: "1"}}},
+		{name: "range on text", filters: []query.Filter{{Key: "status[gt]", Value: "a"}}},
+		{name: "bad integer", filters: []query.Filter{{Key: "room_id", Value: "1 OR 1=1"}}},
+		{name: "bad date", filters: []query.Filter{{Key: "end_date[lt]", Value: "tomorrow"}}},
+		{name: "sort injection", sort: "id; DELETE FROM bookings"},
+		{name: "duplicate sort", sort: "id,-id"},
+		{name: "limit too large", limit: query.MaxLimit + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := query.Parse(tt.filters, tt.sort, 0, tt.limit)
+			assert.ErrorIs(t, err, query.ErrInvalidQuery)
+		})
+	}
+}
+
+func TestLegacySort(t *testing.T) {
+	spec, err := query.LegacySort("start_date", "desc")
+	assert.Nil(t, err)
+	assert.Equal(t, "-start_date", spec)
+
+	spec, err = query.LegacySort("room_id", "")
+	assert.Nil(t, err)
+	assert.Equal(t, "room_id", spec)
+
+	_, err = query.LegacySort("id", "asc; DROP TABLE bookings")
+	assert.ErrorIs(t, err, query.ErrInvalidQuery)
+}
+
+func TestCursorContinuesAfterLastRow(t *testing.T) {
+	q, err := query.Parse([]query.Filter{{Key: "room_id", Value: "3"}}, "-start_date", 0, 10)
+	assert.Nil(t, err)
+
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	token := q.Cursor([]interface{}{start, int64(42)})
+
+	next, err := query.Parse([]query.Filter{{Key: "room_id", Value: "3"}}, "-start_date", 0, 10)
+	assert.Nil(t, err)
+	assert.Nil(t, next.After(token))
+
+	where, args := next.Where()
+	assert.Equal(t, "room_id = $1 AND ((start_date < $2) OR (start_date = $2 AND id > $3))", where)
+	assert.Equal(t, []interface{}{int64(3), start, int64(42)}, args)
+
+	filterWhere, filterArgs := next.FilterWhere()
+	assert.Equal(t, "room_id = $1", filterWhere)
+	assert.Equal(t, []interface{}{int64(3)}, filterArgs)
+}
+
+func TestCursorIsBoundToSort(t *testing.T) {
+	q, _ := query.Parse(nil, "-start_date", 0, 10)
+	token := q.Cursor([]interface{}{time.Now(), int64(1)})
+
+	other, _ := query.Parse(nil, "room_id", 0, 10)
+	assert.ErrorIs(t, other.After(token), query.ErrInvalidQuery)
+	assert.ErrorIs(t, other.After("not-a-cursor"), query.ErrInvalidQuery)
+
+	withOffset, _ := query.Parse(nil, "-start_date", 10, 10)
+	assert.ErrorIs(t, withOffset.After(token), query.ErrInvalidQuery)
+}
@@ -0,0 +1,119 @@
+package service_test
+
+import (
+	"Booking_System/common/auth"
+	"booking/internal/domain/model"
+	grpcTransport "booking/internal/transport/grpc"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	clientpb "clientManage/proto"
+	roompb "roomManage/proto"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeClientServer struct {
+	clientpb.UnimplementedClientManagementServiceServer
+	clients map[int64]*clientpb.Client
+	delay   time.Duration
+}
+
+// GetClient is for staff and services only, like clientManage's.
+func (s *fakeClientServer) GetClient(ctx context.Context, req *clientpb.GetClientRequest) (*clientpb.GetClientResponse, error) {
+	if _, err := auth.Require(ctx, auth.RoleAdmin, auth.RoleOperator, auth.RoleService); err != nil {
+		return nil, auth.StatusError(err)
+	}
+	select {
+	case <-time.After(s.delay):
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	client, ok := s.clients[req.Id]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "client not found")
+	}
+	return &clientpb.GetClientResponse{Client: client}, nil
+}
+
+type fakeRoomServer struct {
+	roompb.UnimplementedRoomServiceServer
+	rooms map[string]*roompb.Room
+}
+
+func (s *fakeRoomServer) GetRoomByID(ctx context.Context, req *roompb.GetRoomByIDRequest) (*roompb.GetRoomResponse, error) {
+	room, ok := s.rooms[req.Id]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "room not found")
+	}
+	return &roompb.GetRoomResponse{Room: room}, nil
+}
+
+const checkerSecret = "checker-secret"
+
+// dialFake serves both fakes over an in-memory listener, verifying tokens
+// signed with checkerSecret.
+func dialFake(t *testing.T, clients *fakeClientServer, rooms *fakeRoomServer) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(auth.NewVerifier(checkerSecret).UnaryServerInterceptor()))
+	clientpb.RegisterClientManagementServiceServer(srv, clients)
+	roompb.RegisterRoomServiceServer(srv, rooms)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestReferenceChecker(t *testing.T) {
+	clients := &fakeClientServer{clients: map[int64]*clientpb.Client{
+		1: {Id: 1, Activated: true},
+		2: {Id: 2, Activated: false},
+	}}
+	rooms := &fakeRoomServer{rooms: map[string]*roompb.Room{
+		"10": {Id: "10", Available: true},
+		"11": {Id: "11", Available: false},
+	}}
+	conn := dialFake(t, clients, rooms)
+	checker := grpcTransport.NewReferenceChecker(conn, conn, time.Second, checkerSecret)
+	ctx := context.Background()
+
+	assert.NoError(t, checker.CheckClient(ctx, 1))
+	assert.ErrorIs(t, checker.CheckClient(ctx, 2), model.ErrClientInactive)
+	assert.ErrorIs(t, checker.CheckClient(ctx, 3), model.ErrClientNotFound)
+
+	assert.NoError(t, checker.CheckRoom(ctx, 10))
+	assert.ErrorIs(t, checker.CheckRoom(ctx, 11), model.ErrRoomUnavailable)
+	assert.ErrorIs(t, checker.CheckRoom(ctx, 12), model.ErrRoomNotFound)
+}
+
+func TestReferenceCheckerTimeout(t *testing.T) {
+	clients := &fakeClientServer{clients: map[int64]*clientpb.Client{1: {Id: 1, Activated: true}}, delay: time.Second}
+	conn := dialFake(t, clients, &fakeRoomServer{})
+	checker := grpcTransport.NewReferenceChecker(conn, conn, 50*time.Millisecond, checkerSecret)
+
+	err := checker.CheckClient(context.Background(), 1)
+	assert.ErrorIs(t, err, model.ErrReferenceCheckFailed)
+}
+
+func TestReferenceCheckerNeedsTheSharedSecret(t *testing.T) {
+	clients := &fakeClientServer{clients: map[int64]*clientpb.Client{1: {Id: 1, Activated: true}}}
+	conn := dialFake(t, clients, &fakeRoomServer{})
+	checker := grpcTransport.NewReferenceChecker(conn, conn, time.Second, "wrong-secret")
+
+	err := checker.CheckClient(context.Background(), 1)
+	assert.ErrorIs(t, err, model.ErrReferenceCheckFailed)
+}
@@ -15,6 +15,8 @@ import (
 
 type envelope map[string]any
 
+var errInvalidIfMatch = errors.New(`If-Match must be a user version such as "3"`)
+
 func (app *application) readIDPAram(r *http.Request) (int64, error) {
 	params := httprouter.ParamsFromContext(r.Context())
 
@@ -101,6 +103,26 @@ func (app *application) readJSON(w http.ResponseWriter, r *http.Request, dst any
 	return nil
 }
 
+// readIfMatchVersion returns the user version an If-Match header asks for, or
+// zero if the header is missing or "*". Like the booking service it takes the
+// version as an ETag such as "3", weak or not.
+func (app *application) readIfMatchVersion(r *http.Request) (int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	value = strings.TrimPrefix(value, "W/")
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, errInvalidIfMatch
+	}
+	version, err := strconv.Atoi(value[1 : len(value)-1])
+	if err != nil || version < 1 {
+		return 0, errInvalidIfMatch
+	}
+	return version, nil
+}
+
 func (app *application) background(fn func()) {
 	go func() {
 		defer func() {
@@ -140,12 +162,3 @@ func (app *application) readInt(qs url.Values, key string, defaultValue int, v *
 	}
 	return i
 }
-
-func contains(slice []string, value string) bool {
-	for _, v := range slice {
-		if v == value {
-			return true
-		}
-	}
-	return false
-}
@@ -4,8 +4,11 @@ import (
 	"clientManage/internal/data"
 	"clientManage/internal/jsonlog"
 	"clientManage/internal/mailer"
+	"clientManage/internal/service"
+	"clientManage/internal/transport/messaging"
 	"context"
 	"database/sql"
+	"errors"
 	"flag"
 	_ "github.com/lib/pq"
 	"os"
@@ -16,9 +19,10 @@ import (
 const version = "1.0.0"
 
 type config struct {
-	port int
-	env  string
-	db   struct {
+	port     int
+	grpcPort int
+	env      string
+	db       struct {
 		dsn          string
 		maxOpenConns int
 		maxIdleConns int
@@ -38,19 +42,31 @@ type config struct {
 		password string
 		sender   string
 	}
+
+	jwt struct {
+		secret string
+	}
+
+	rabbitmq struct {
+		url           string
+		statsInterval time.Duration
+	}
 }
 
 type application struct {
-	config config
-	logger *jsonlog.Logger
-	models data.Models
-	mailer mailer.Mailer
-	wg     sync.WaitGroup
+	config  config
+	logger  *jsonlog.Logger
+	models  data.Models
+	mailer  mailer.Mailer
+	events  messaging.UserMessaging
+	clients *service.ClientService
+	wg      sync.WaitGroup
 }
 
 func main() {
 	var cfg config
 	flag.IntVar(&cfg.port, "port", 4000, "API server port")
+	flag.IntVar(&cfg.grpcPort, "grpc-port", 4100, "gRPC server port")
 	flag.StringVar(&cfg.env, "env", "development", "Environment (development|staging|production)")
 	// Read the DSN value from the db-dsn command-line flag into the config struct. We
 	// default to using our development DSN if no flag is provided.
@@ -68,9 +84,20 @@ func main() {
 	flag.StringVar(&cfg.smtp.password, "smtp-password", "57597add00cddd", "SMTP password")
 	flag.StringVar(&cfg.smtp.sender, "smtp-sender", "ModuleInfo <no-reply@module.info>", "SMTP sender")
 
+	// The secret is shared with the booking and room services, which verify the
+	// access tokens we sign with it.
+	flag.StringVar(&cfg.jwt.secret, "jwt-secret", os.Getenv("JWT_SECRET"), "JWT signing secret")
+
+	flag.StringVar(&cfg.rabbitmq.url, "rabbitmq-url", os.Getenv("RABBITMQ_URL"), "RabbitMQ URL")
+	flag.DurationVar(&cfg.rabbitmq.statsInterval, "rabbitmq-stats-interval", time.Minute, "How often to log publish stats")
+
 	flag.Parse()
 
 	logger := jsonlog.New(os.Stdout, jsonlog.LevelInfo)
+	if cfg.jwt.secret == "" {
+		logger.PrintFatal(errors.New("jwt secret must be provided"), nil)
+	}
+
 	db, err := openDB(cfg)
 	if err != nil {
 		// Use the PrintFatal() method to write a log entry containing the error at the
@@ -81,11 +108,22 @@ func main() {
 	defer db.Close()
 	logger.PrintInfo("database connection pool established", nil)
 
+	events, err := messaging.NewUserMessaging(cfg.rabbitmq.url)
+	if err != nil {
+		logger.PrintFatal(err, nil)
+	}
+	defer events.Close()
+	logger.PrintInfo("rabbitmq connection established", nil)
+	go events.LogStats(cfg.rabbitmq.statsInterval)
+
+	models := data.NewModels(db)
 	app := &application{
-		config: cfg,
-		logger: logger,
-		models: data.NewModels(db),
-		mailer: mailer.New(cfg.smtp.host, cfg.smtp.port, cfg.smtp.username, cfg.smtp.password, cfg.smtp.sender),
+		config:  cfg,
+		logger:  logger,
+		models:  models,
+		mailer:  mailer.New(cfg.smtp.host, cfg.smtp.port, cfg.smtp.username, cfg.smtp.password, cfg.smtp.sender),
+		events:  events,
+		clients: service.NewClientService(models, events),
 	}
 
 	err = app.serve()
@@ -15,6 +15,7 @@ func (app *application) routes() http.Handler {
 	router.HandlerFunc(http.MethodGet, "/v1/healthcheck", app.healthcheckHandler)
 
 	router.HandlerFunc(http.MethodPut, "/v1/users/update/:id", app.requirePermission("user:write", app.updateUserHandler))
+	router.HandlerFunc(http.MethodPatch, "/v1/users/update/:id", app.requirePermission("user:write", app.patchUserHandler))
 	router.HandlerFunc(http.MethodDelete, "/v1/users/delete/:id", app.requirePermission("user:write", app.deleteUserHandler))
 	router.HandlerFunc(http.MethodGet, "/v1/users", app.requirePermission("user:write", app.getAllUsersHandler))
 	router.HandlerFunc(http.MethodGet, "/v1/users/email", app.requirePermission("user:write", app.getUserByEmailHandler))
@@ -1,14 +1,20 @@
 package main
 
 import (
+	"Booking_System/common/auth"
+	grpcTransport "clientManage/internal/transport/grpc"
+	pb "clientManage/proto"
 	"context" // New import
 	"errors"  // New import
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+
+	"google.golang.org/grpc"
 )
 
 func (app *application) serve() error {
@@ -19,6 +25,26 @@ func (app *application) serve() error {
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 30 * time.Second,
 	}
+
+	// Other services reach clients over gRPC on a port of its own.
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", app.config.grpcPort))
+	if err != nil {
+		return err
+	}
+	// gRPC callers authenticate with the same access tokens the other
+	// services accept.
+	verifier := auth.NewVerifier(app.config.jwt.secret)
+	grpcSrv := grpc.NewServer(grpc.UnaryInterceptor(verifier.UnaryServerInterceptor()))
+	pb.RegisterClientManagementServiceServer(grpcSrv, grpcTransport.NewClientGRPCServer(app.clients))
+	go func() {
+		app.logger.PrintInfo("starting grpc server", map[string]string{
+			"addr": lis.Addr().String(),
+		})
+		if err := grpcSrv.Serve(lis); err != nil {
+			app.logger.PrintError(err, nil)
+		}
+	}()
+
 	shutdownError := make(chan error)
 	go func() {
 		quit := make(chan os.Signal, 1)
@@ -31,6 +57,7 @@ func (app *application) serve() error {
 		defer cancel()
 		// Call Shutdown() on the server like before, but now we only send on the
 		// shutdownError channel if it returns an error.
+		grpcSrv.GracefulStop()
 		err := srv.Shutdown(ctx)
 		if err != nil {
 			shutdownError <- err
@@ -51,7 +78,7 @@ func (app *application) serve() error {
 		"addr": srv.Addr,
 		"env":  app.config.env,
 	})
-	err := srv.ListenAndServe()
+	err = srv.ListenAndServe()
 	if !errors.Is(err, http.ErrServerClosed) {
 		return err
 	}
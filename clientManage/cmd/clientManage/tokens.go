@@ -1,6 +1,7 @@
 package main
 
 import (
+	"clientManage/internal/auth"
 	"clientManage/internal/data"
 	"clientManage/internal/validator"
 	"errors"
@@ -52,13 +53,34 @@ func (app *application) createAuthenticationTokenHandler(w http.ResponseWriter,
 		return
 	}
 
+	// Tokens carry the user's role to the other services, so accounts that
+	// were never activated get none.
+	if !user.Activated {
+		app.inactiveAccountResponse(w, r)
+		return
+	}
+
 	token, err := app.models.Token.New(user.ID, 24*time.Hour, data.ScopeAuthentication)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusCreated, envelope{"authentication_token": token}, nil)
+	// The access token carries the user's identity to the booking and room
+	// services, which verify it without calling back to us.
+	permissions, err := app.models.Permissions.GetAllForUsers(user.ID)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	accessToken, err := auth.NewAccessToken(app.config.jwt.secret, user.ID, user.UserRole, permissions, 24*time.Hour)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
+	}
+
+	err = app.writeJSON(w, http.StatusCreated, envelope{"authentication_token": token, "access_token": accessToken}, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -1,20 +1,27 @@
 package main
 
 import (
+	"Booking_System/common/patch"
 	"clientManage/internal/data"
+	"clientManage/internal/service"
 	"clientManage/internal/validator"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"time"
 )
 
+// registerUserHandler signs up a client. Anyone may call it, so the role is
+// never taken from the request.
 func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Request) {
 	var input struct {
 		Fname    string `json:"fname"`
 		Sname    string `json:"sname"`
 		Email    string `json:"email"`
-		UserRole string `json:"user_role"`
 		Password string `json:"password"`
+		// UserRole is still accepted so existing clients keep working, but
+		// it is ignored.
+		UserRole string `json:"user_role"`
 	}
 
 	err := app.readJSON(w, r, &input)
@@ -27,7 +34,7 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 		Fname:     input.Fname,
 		Sname:     input.Sname,
 		Email:     input.Email,
-		UserRole:  input.UserRole,
+		UserRole:  data.RoleClient,
 		Activated: false,
 	}
 
@@ -56,19 +63,12 @@ func (app *application) registerUserHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if user.UserRole == "ADMIN" || user.UserRole == "OPERATOR" {
-		err = app.models.Permissions.AddForUser(user.ID, "info:write", "info:read")
-		if err != nil {
-			app.serverErrorResponse(w, r, err)
-			return
-		}
-	} else {
-		err = app.models.Permissions.AddForUser(user.ID, "info:read")
-		if err != nil {
-			app.serverErrorResponse(w, r, err)
-			return
-		}
+	err = app.models.Permissions.AddForUser(user.ID, data.DefaultPermissions(user.UserRole)...)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+		return
 	}
+	service.ReportPublishError(app.events.PublishUserCreated(user), user.ID)
 
 	token, err := app.models.Token.New(user.ID, 24*time.Hour, data.ScopeActivation)
 	if err != nil {
@@ -137,6 +137,7 @@ func (app *application) activateUserHandler(w http.ResponseWriter, r *http.Reque
 		}
 		return
 	}
+	service.ReportPublishError(app.events.PublishUserActivated(user), user.ID)
 
 	err = app.writeJSON(w, http.StatusOK, envelope{"user": user}, nil)
 	if err != nil {
@@ -144,6 +145,9 @@ func (app *application) activateUserHandler(w http.ResponseWriter, r *http.Reque
 	}
 }
 
+// updateUserHandler replaces a user's name, email and password. With an
+// If-Match header holding the user's version it fails with 409 Conflict if the
+// user changed in the meantime.
 func (app *application) updateUserHandler(w http.ResponseWriter, r *http.Request) {
 	id, err := app.readIDPAram(r)
 	if err != nil {
@@ -175,6 +179,10 @@ func (app *application) updateUserHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if !app.applyIfMatch(w, r, user) {
+		return
+	}
+
 	user.Fname = input.Fname
 	user.Sname = input.Sname
 	user.Email = input.Email
@@ -202,6 +210,116 @@ func (app *application) updateUserHandler(w http.ResponseWriter, r *http.Request
 		}
 		return
 	}
+	service.ReportPublishError(app.events.PublishUserUpdated(user), user.ID)
+
+	err = app.writeJSON(w, http.StatusOK, envelope{"updated_user": user}, nil)
+	if err != nil {
+		app.serverErrorResponse(w, r, err)
+	}
+}
+
+// applyIfMatch makes the update of user conditional on the version in the
+// If-Match header, if there is one, so a stale write fails with an edit
+// conflict instead of overwriting a newer change. It writes the error response
+// itself and reports whether the handler may go on.
+func (app *application) applyIfMatch(w http.ResponseWriter, r *http.Request, user *data.User) bool {
+	version, err := app.readIfMatchVersion(r)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return false
+	}
+	if version != 0 {
+		user.Version = version
+	}
+	return true
+}
+
+// patchUserHandler applies a JSON Merge Patch to a user's name, email and
+// password. Members that are left out keep their value. Like a full update it
+// honours If-Match.
+func (app *application) patchUserHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := app.readIDPAram(r)
+	if err != nil {
+		app.notFoundResponse(w, r)
+		return
+	}
+
+	if err := patch.CheckContentType(r); err != nil {
+		app.errorResponse(w, r, http.StatusUnsupportedMediaType, err.Error())
+		return
+	}
+
+	user, err := app.models.User.GetByID(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			app.notFoundResponse(w, r)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	var body json.RawMessage
+	err = app.readJSON(w, r, &body)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	input := struct {
+		Fname    string  `json:"fname"`
+		Sname    string  `json:"sname"`
+		Email    string  `json:"email"`
+		Password *string `json:"password,omitempty"`
+	}{
+		Fname: user.Fname,
+		Sname: user.Sname,
+		Email: user.Email,
+	}
+	err = patch.Apply(&input, body)
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if !app.applyIfMatch(w, r, user) {
+		return
+	}
+
+	user.Fname = input.Fname
+	user.Sname = input.Sname
+	user.Email = input.Email
+
+	if input.Password != nil {
+		err = user.Password.Set(*input.Password)
+		if err != nil {
+			app.serverErrorResponse(w, r, err)
+			return
+		}
+	}
+
+	v := validator.New()
+
+	if data.ValidateUser(v, user); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	err = app.models.User.Update(user)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrEditConflict):
+			app.editConflictResponse(w, r)
+		case errors.Is(err, data.ErrDuplicateEmail):
+			v.AddError("email", "a user with this email address is already exists")
+			app.failedValidationResponse(w, r, v.Errors)
+		default:
+			app.serverErrorResponse(w, r, err)
+		}
+		return
+	}
+	service.ReportPublishError(app.events.PublishUserUpdated(user), user.ID)
 
 	err = app.writeJSON(w, http.StatusOK, envelope{"updated_user": user}, nil)
 	if err != nil {
@@ -216,11 +334,15 @@ func (app *application) deleteUserHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	err = app.models.User.Delete(id)
+	// The client service announces the deletion, and keeps the user if it
+	// cannot.
+	err = app.clients.DeleteClient(id)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
 			app.notFoundResponse(w, r)
+		case errors.Is(err, service.ErrEventNotPublished):
+			app.eventNotPublishedResponse(w, r, err)
 		default:
 			app.serverErrorResponse(w, r, err)
 		}
@@ -235,24 +357,34 @@ func (app *application) deleteUserHandler(w http.ResponseWriter, r *http.Request
 }
 
 func (app *application) getAllUsersHandler(w http.ResponseWriter, r *http.Request) {
-	// Get query parameters for role and sort
-	role := r.URL.Query().Get("role")
-	sort := r.URL.Query().Get("sort")
-
-	// Validate sort parameter
-	validSortValues := []string{"fname", "sname"}
-	if sort != "" && !contains(validSortValues, sort) {
-		app.badRequestResponse(w, r, errors.New("invalid sort value"))
+	var input struct {
+		Role string
+		data.Filters
+	}
+
+	v := validator.New()
+	qs := r.URL.Query()
+
+	input.Role = app.readString(qs, "role", "")
+
+	input.Filters.Page = app.readInt(qs, "page", 1, v)
+	input.Filters.PageSize = app.readInt(qs, "page_size", 20, v)
+	input.Filters.Sort = app.readString(qs, "sort", "id")
+	input.Filters.SortSafelist = []string{"id", "fname", "sname", "-id", "-fname", "-sname"}
+	input.Filters.Cursor = app.readString(qs, "cursor", "")
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		app.failedValidationResponse(w, r, v.Errors)
 		return
 	}
 
-	users, err := app.models.User.GetAll(role, sort)
+	users, metadata, err := app.models.User.GetAll(input.Role, input.Filters)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 		return
 	}
 
-	err = app.writeJSON(w, http.StatusOK, envelope{"users": users}, nil)
+	err = app.writeJSON(w, http.StatusOK, envelope{"users": users, "metadata": metadata}, nil)
 	if err != nil {
 		app.serverErrorResponse(w, r, err)
 	}
@@ -41,3 +41,9 @@ require (
 	gopkg.in/mail.v2 v2.3.1 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+require Booking_System v0.0.0
+
+// Booking_System is the repository root, which holds the shared common
+// packages.
+replace Booking_System => ../
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	commonAuth "Booking_System/common/auth"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// Issuer is checked by the services that verify the tokens, so it comes from
+// the package they verify with.
+const (
+	Issuer      = commonAuth.Issuer
+	DefaultRole = commonAuth.RoleClient
+)
+
+// Claims is the payload of the access tokens verified by the booking and room
+// services.
+type Claims = commonAuth.Claims
+
+type AccessToken struct {
+	Token  string    `json:"token"`
+	Expiry time.Time `json:"expiry"`
+}
+
+// NormalizeRole maps the stored user_role (e.g. "ADMIN") to the lower case
+// role names the other services check against. Users without a role are
+// clients.
+func NormalizeRole(role string) string {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if role == "" {
+		return DefaultRole
+	}
+	return role
+}
+
+// NewAccessToken signs an HS256 token for the user with the shared secret.
+func NewAccessToken(secret string, userID int64, role string, permissions []string, ttl time.Duration) (*AccessToken, error) {
+	now := time.Now()
+	expiry := now.Add(ttl)
+
+	claims := Claims{
+		StandardClaims: jwt.StandardClaims{
+			Subject:   strconv.FormatInt(userID, 10),
+			Issuer:    Issuer,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: expiry.Unix(),
+		},
+		Role:        NormalizeRole(role),
+		Permissions: permissions,
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccessToken{Token: token, Expiry: expiry}, nil
+}
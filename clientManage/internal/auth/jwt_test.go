@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func TestNormalizeRole(t *testing.T) {
+	tests := map[string]string{
+		"ADMIN":    "admin",
+		"OPERATOR": "operator",
+		" Client ": "client",
+		"":         DefaultRole,
+	}
+	for input, want := range tests {
+		if got := NormalizeRole(input); got != want {
+			t.Errorf("NormalizeRole(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestNewAccessToken(t *testing.T) {
+	accessToken, err := NewAccessToken("secret", 42, "ADMIN", []string{"user:write"}, time.Hour)
+	if err != nil {
+		t.Fatalf("NewAccessToken returned error: %v", err)
+	}
+
+	var claims Claims
+	_, err = jwt.ParseWithClaims(accessToken.Token, &claims, func(*jwt.Token) (interface{}, error) {
+		return []byte("secret"), nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to parse access token: %v", err)
+	}
+
+	if claims.Subject != "42" {
+		t.Errorf("Incorrect subject: got %q, want %q", claims.Subject, "42")
+	}
+	if claims.Role != "admin" {
+		t.Errorf("Incorrect role: got %q, want %q", claims.Role, "admin")
+	}
+	if len(claims.Permissions) != 1 || claims.Permissions[0] != "user:write" {
+		t.Errorf("Incorrect permissions: got %v", claims.Permissions)
+	}
+	if claims.ExpiresAt != accessToken.Expiry.Unix() {
+		t.Errorf("Expiry mismatch: claims %d, token %d", claims.ExpiresAt, accessToken.Expiry.Unix())
+	}
+
+	_, err = jwt.ParseWithClaims(accessToken.Token, &Claims{}, func(*jwt.Token) (interface{}, error) {
+		return []byte("other-secret"), nil
+	})
+	if err == nil {
+		t.Error("Token verified with the wrong secret")
+	}
+}
@@ -1,12 +1,35 @@
 package data
 
-import "clientManage/internal/validator"
+import (
+	"clientManage/internal/validator"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
 
 type Filters struct {
 	Page         int
 	PageSize     int
 	Sort         string
 	SortSafelist []string
+	// Cursor is the next_cursor of a previous page. When set it takes the
+	// place of Page.
+	Cursor string
+}
+
+// Metadata describes a page of results. NextCursor is empty on the last page.
+type Metadata struct {
+	TotalRecords int    `json:"total_records"`
+	PageSize     int    `json:"page_size"`
+	NextCursor   string `json:"next_cursor,omitempty"`
+}
+
+// cursor is the decoded form of the opaque next_cursor token: the sort it was
+// issued for and the sort value and id of the last row on the page.
+type cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    int64  `json:"id"`
 }
 
 func ValidateFilters(v *validator.Validator, f Filters) {
@@ -15,4 +38,55 @@ func ValidateFilters(v *validator.Validator, f Filters) {
 	v.Check(f.PageSize > 0, "pagesize", "must be greater than zero")
 	v.Check(f.PageSize <= 100, "pagesize", "must be a maximum of 100")
 	v.Check(validator.PermittedValue(f.Sort, f.SortSafelist...), "sort", "invalid sort value")
+
+	if f.Cursor != "" {
+		_, ok := f.decodeCursor()
+		v.Check(ok, "cursor", "invalid cursor")
+	}
+}
+
+func (f Filters) sortColumn() string {
+	for _, safeValue := range f.SortSafelist {
+		if f.Sort == safeValue {
+			return strings.TrimPrefix(f.Sort, "-")
+		}
+	}
+	panic("unsafe sort parameter: " + f.Sort)
+}
+
+func (f Filters) sortDirection() string {
+	if strings.HasPrefix(f.Sort, "-") {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+func (f Filters) limit() int {
+	return f.PageSize
+}
+
+func (f Filters) offset() int {
+	if f.Cursor != "" {
+		return 0
+	}
+	return (f.Page - 1) * f.PageSize
+}
+
+func (f Filters) encodeCursor(value string, id int64) string {
+	js, _ := json.Marshal(cursor{Sort: f.Sort, Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(js)
+}
+
+// decodeCursor returns false if the cursor is malformed or was issued for a
+// different sort.
+func (f Filters) decodeCursor() (cursor, bool) {
+	var c cursor
+	js, err := base64.RawURLEncoding.DecodeString(f.Cursor)
+	if err != nil {
+		return c, false
+	}
+	if err := json.Unmarshal(js, &c); err != nil {
+		return c, false
+	}
+	return c, c.Sort == f.Sort
 }
@@ -47,3 +47,38 @@ func assertInvalid(t *testing.T, v *validator.Validator, key string, message str
 		t.Errorf("Expected error message '%s' for key '%s', got '%s'", message, key, v.Errors[key])
 	}
 }
+
+func TestFilters_Cursor(t *testing.T) {
+	f := Filters{
+		Page:         1,
+		PageSize:     20,
+		Sort:         "-fname",
+		SortSafelist: []string{"id", "fname", "-fname"},
+	}
+
+	f.Cursor = f.encodeCursor("Primer", 42)
+	c, ok := f.decodeCursor()
+	if !ok {
+		t.Fatal("Cursor issued for the same sort should decode")
+	}
+	if c.Value != "Primer" || c.ID != 42 {
+		t.Errorf("Unexpected cursor contents: %+v", c)
+	}
+	if f.offset() != 0 {
+		t.Errorf("Offset should be ignored with a cursor, got %d", f.offset())
+	}
+
+	v := validator.New()
+	ValidateFilters(v, f)
+	assertValid(t, v)
+
+	f.Sort = "id"
+	v = validator.New()
+	ValidateFilters(v, f)
+	assertInvalid(t, v, "cursor", "invalid cursor")
+
+	f.Cursor = "not-a-cursor"
+	v = validator.New()
+	ValidateFilters(v, f)
+	assertInvalid(t, v, "cursor", "invalid cursor")
+}
@@ -18,6 +18,15 @@ func (p Permissions) Include(code string) bool {
 	return false
 }
 
+// DefaultPermissions returns the permission codes a newly registered user
+// with the given role receives.
+func DefaultPermissions(role string) []string {
+	if role == "ADMIN" || role == "OPERATOR" {
+		return []string{"info:write", "info:read"}
+	}
+	return []string{"info:read"}
+}
+
 type PermissionModel struct {
 	DB *sql.DB
 }
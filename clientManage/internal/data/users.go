@@ -6,16 +6,23 @@ import (
 	"crypto/sha256"
 	"database/sql"
 	"errors"
+	"fmt"
 	"golang.org/x/crypto/bcrypt"
+	"strconv"
 	"time"
 )
 
 var (
 	ErrDuplicateEmail = errors.New("duplicate email")
+	ErrInvalidCursor  = errors.New("invalid cursor")
 )
 
 var AnonymousUser = &User{}
 
+// RoleClient is the role of users who sign up themselves. Other roles are
+// only given by staff.
+const RoleClient = "CLIENT"
+
 type User struct {
 	ID        int64     `json:"id"`
 	CreatedAt time.Time `json:"created_at"`
@@ -239,6 +246,7 @@ func (m UserModel) GetForToken(tokenScope, tokenPlainText string) (*User, error)
 		       users.email, 
 		       users.password_hash, 
 		       users.activated, 
+		       users.user_role,
 		       users.version
 		FROM users
 		INNER JOIN tokens
@@ -262,6 +270,7 @@ func (m UserModel) GetForToken(tokenScope, tokenPlainText string) (*User, error)
 		&user.Email,
 		&user.Password.hash,
 		&user.Activated,
+		&user.UserRole,
 		&user.Version,
 	)
 	if err != nil {
@@ -276,57 +285,88 @@ func (m UserModel) GetForToken(tokenScope, tokenPlainText string) (*User, error)
 	return &user, nil
 }
 
-func (user *UserModel) Delete(id int64) error {
+// Delete deletes the user. announce, if not nil, runs once the row is gone
+// but before the deletion is committed; if it fails the user is kept and its
+// error returned, so other services never miss a committed deletion.
+func (user *UserModel) Delete(id int64, announce func() error) error {
 	query := `DELETE FROM users WHERE id = $1`
 
-	result, err := user.DB.Exec(query, id)
+	tx, err := user.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(query, id)
 	if err != nil {
 		return err
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return nil
+		return err
 	}
 
 	if rowsAffected == 0 {
 		return ErrRecordNotFound
 	}
 
-	return nil
+	if announce != nil {
+		if err := announce(); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
-func (m UserModel) GetAll(role string, sort string) ([]*User, error) {
-	query := `
-		SELECT id, created_at, fname, sname, email, password_hash, user_role, activated, version
-		FROM users`
+// GetAll returns one page of users, optionally only those with the given role,
+// ordered by filters.Sort with id as a tie-breaker. Pages after the first are
+// found with a keyset condition on the cursor rather than an offset, so deep
+// pages stay cheap. One extra row is fetched to tell whether another page
+// follows.
+func (m UserModel) GetAll(role string, filters Filters) ([]*User, Metadata, error) {
+	metadata := Metadata{PageSize: filters.limit()}
+	column, direction := filters.sortColumn(), filters.sortDirection()
 
-	args := []interface{}{}
-	if role != "" {
-		query += " WHERE user_role = $1"
-		args = append(args, role)
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	countQuery := `SELECT count(*) FROM users WHERE (user_role = $1 OR $1 = '')`
+	err := m.DB.QueryRowContext(ctx, countQuery, role).Scan(&metadata.TotalRecords)
+	if err != nil {
+		return nil, Metadata{}, err
 	}
 
-	// Determine the sorting order
-	switch sort {
-	case "fname":
-		query += " ORDER BY fname"
-	case "sname":
-		query += " ORDER BY sname"
-	default:
-		query += " ORDER BY id"
+	query := `
+		SELECT id, created_at, fname, sname, email, password_hash, user_role, activated, version
+		FROM users
+		WHERE (user_role = $1 OR $1 = '')`
+	args := []any{role}
+
+	if filters.Cursor != "" {
+		c, ok := filters.decodeCursor()
+		if !ok {
+			return nil, Metadata{}, ErrInvalidCursor
+		}
+		comparison := ">"
+		if direction == "DESC" {
+			comparison = "<"
+		}
+		query += fmt.Sprintf(" AND (%s, id) %s ($2, $3)", column, comparison)
+		args = append(args, c.Value, c.ID)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s LIMIT $%d OFFSET $%d", column, direction, direction, len(args)+1, len(args)+2)
+	args = append(args, filters.limit()+1, filters.offset())
 
 	rows, err := m.DB.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return nil, Metadata{}, err
 	}
 	defer rows.Close()
 
-	var users []*User
+	users := []*User{}
 	for rows.Next() {
 		var user User
 		err := rows.Scan(
@@ -341,14 +381,33 @@ func (m UserModel) GetAll(role string, sort string) ([]*User, error) {
 			&user.Version,
 		)
 		if err != nil {
-			return nil, err
+			return nil, Metadata{}, err
 		}
 		users = append(users, &user)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, err
+		return nil, Metadata{}, err
+	}
+
+	if len(users) > filters.limit() {
+		users = users[:filters.limit()]
+		last := users[len(users)-1]
+		metadata.NextCursor = filters.encodeCursor(userSortValue(last, column), last.ID)
 	}
 
-	return users, nil
+	return users, metadata, nil
+}
+
+// userSortValue returns the value of the sort column for u as text, the form
+// it is stored in inside a cursor.
+func userSortValue(u *User, column string) string {
+	switch column {
+	case "fname":
+		return u.Fname
+	case "sname":
+		return u.Sname
+	default:
+		return strconv.FormatInt(u.ID, 10)
+	}
 }
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// Client is the view of a user that other services see over gRPC. It leaves
+// out credentials and everything else that only clientManage needs.
+type Client struct {
+	ID        int64
+	CreatedAt time.Time
+	Name      string
+	Surname   string
+	Email     string
+	Role      string
+	Activated bool
+	Version   int
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	commonMessaging "Booking_System/common/messaging"
+	"clientManage/internal/data"
+	"clientManage/internal/domain/model"
+	"clientManage/internal/validator"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+// ErrEventNotPublished is returned when a change could not be announced to
+// other services and was therefore not made.
+var ErrEventNotPublished = errors.New("event could not be published")
+
+// ValidationError carries the field errors of a rejected client, in the same
+// shape the HTTP API reports them.
+type ValidationError struct {
+	Errors map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Errors))
+	for field, message := range e.Errors {
+		fields = append(fields, field+": "+message)
+	}
+	sort.Strings(fields)
+	return "validation failed: " + strings.Join(fields, ", ")
+}
+
+// UserEvents announces user changes that other services react to.
+type UserEvents interface {
+	PublishUserCreated(user *data.User) error
+	PublishUserActivated(user *data.User) error
+	PublishUserUpdated(user *data.User) error
+	PublishUserDeleted(userID int64) error
+}
+
+var clientSortSafelist = []string{"id", "fname", "sname", "-id", "-fname", "-sname"}
+
+// clientUpdateMaskSafelist holds the field mask paths UpdateClient accepts.
+var clientUpdateMaskSafelist = []string{"fname", "sname", "email", "activated"}
+
+// ClientService exposes users as clients to other services. It works on the
+// same models as the HTTP API, so both apply the same validation and
+// optimistic locking.
+type ClientService struct {
+	users       data.UserModel
+	permissions data.PermissionModel
+	events      UserEvents
+}
+
+func NewClientService(models data.Models, events UserEvents) *ClientService {
+	return &ClientService{users: models.User, permissions: models.Permissions, events: events}
+}
+
+func toClient(user *data.User) *model.Client {
+	return &model.Client{
+		ID:        user.ID,
+		CreatedAt: user.CreatedAt,
+		Name:      user.Fname,
+		Surname:   user.Sname,
+		Email:     user.Email,
+		Role:      user.UserRole,
+		Activated: user.Activated,
+		Version:   user.Version,
+	}
+}
+
+// CreateClient registers a new, not yet activated user with the default
+// permissions for its role, and fills in the generated ID, creation time and
+// version. Like every change made through the service, it publishes the
+// matching user event.
+func (s *ClientService) CreateClient(client *model.Client, password string) error {
+	user := &data.User{
+		Fname:     client.Name,
+		Sname:     client.Surname,
+		Email:     client.Email,
+		UserRole:  client.Role,
+		Activated: false,
+	}
+	if err := user.Password.Set(password); err != nil {
+		return err
+	}
+
+	v := validator.New()
+	if data.ValidateUser(v, user); !v.Valid() {
+		return &ValidationError{Errors: v.Errors}
+	}
+
+	if err := s.users.Insert(user); err != nil {
+		return err
+	}
+	if err := s.permissions.AddForUser(user.ID, data.DefaultPermissions(user.UserRole)...); err != nil {
+		return err
+	}
+	ReportPublishError(s.events.PublishUserCreated(user), user.ID)
+
+	*client = *toClient(user)
+	return nil
+}
+
+func (s *ClientService) GetClientByID(id int64) (*model.Client, error) {
+	user, err := s.users.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return toClient(user), nil
+}
+
+// UpdateClient changes the name, email and activation of a client. The update
+// only applies if client.Version is still current; otherwise it fails with
+// data.ErrEditConflict. The role cannot be changed here.
+//
+// A non-empty mask limits the update to the listed fields ("fname", "sname",
+// "email" and "activated"); the others keep their stored value. A masked
+// update with a zero client.Version applies to whatever version is current.
+func (s *ClientService) UpdateClient(client *model.Client, mask []string) error {
+	v := validator.New()
+	for _, path := range mask {
+		v.Check(validator.PermittedValue(path, clientUpdateMaskSafelist...), "update_mask", fmt.Sprintf("cannot update %q", path))
+	}
+	if !v.Valid() {
+		return &ValidationError{Errors: v.Errors}
+	}
+
+	user, err := s.users.GetByID(client.ID)
+	if err != nil {
+		return err
+	}
+
+	masked := func(path string) bool {
+		return len(mask) == 0 || validator.PermittedValue(path, mask...)
+	}
+
+	activated := user.Activated
+	if masked("fname") {
+		user.Fname = client.Name
+	}
+	if masked("sname") {
+		user.Sname = client.Surname
+	}
+	if masked("email") {
+		user.Email = client.Email
+	}
+	if masked("activated") {
+		user.Activated = client.Activated
+	}
+	if len(mask) == 0 || client.Version != 0 {
+		user.Version = client.Version
+	}
+	activating := !activated && user.Activated
+
+	if data.ValidateUser(v, user); !v.Valid() {
+		return &ValidationError{Errors: v.Errors}
+	}
+
+	if err := s.users.Update(user); err != nil {
+		return err
+	}
+	if activating {
+		ReportPublishError(s.events.PublishUserActivated(user), user.ID)
+	} else {
+		ReportPublishError(s.events.PublishUserUpdated(user), user.ID)
+	}
+
+	*client = *toClient(user)
+	return nil
+}
+
+// DeleteClient deletes the client and publishes user.deleted before the
+// deletion is committed, so the booking service always hears about it.
+// Publishing is retried a few times; if it still fails the client is kept and
+// ErrEventNotPublished is returned, so the caller can try again.
+func (s *ClientService) DeleteClient(id int64) error {
+	return s.users.Delete(id, func() error {
+		err := commonMessaging.PublishWithRetry(0, 0, func() error {
+			return s.events.PublishUserDeleted(id)
+		})
+		if err != nil {
+			return fmt.Errorf("%w: user.deleted for client %d: %v", ErrEventNotPublished, id, err)
+		}
+		return nil
+	})
+}
+
+// ReportPublishError logs a user event that could not be published. The
+// change itself is already committed, so the call still succeeds. The HTTP
+// handlers use it too, so both APIs report lost events the same way.
+func ReportPublishError(err error, userID int64) {
+	if err != nil {
+		log.Printf("Failed to publish event for client %d: %v", userID, err)
+	}
+}
+
+// ListClients returns one page of clients, optionally only those with the
+// given role. See data.UserModel.GetAll for how paging works.
+func (s *ClientService) ListClients(role string, filters data.Filters) ([]*model.Client, data.Metadata, error) {
+	filters.SortSafelist = clientSortSafelist
+	if filters.Sort == "" {
+		filters.Sort = "id"
+	}
+
+	v := validator.New()
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		return nil, data.Metadata{}, &ValidationError{Errors: v.Errors}
+	}
+
+	users, metadata, err := s.users.GetAll(role, filters)
+	if err != nil {
+		return nil, data.Metadata{}, fmt.Errorf("listing clients: %w", err)
+	}
+
+	clients := make([]*model.Client, len(users))
+	for i, user := range users {
+		clients[i] = toClient(user)
+	}
+	return clients, metadata, nil
+}
@@ -1,12 +1,20 @@
 package grpc
 
 import (
+	"Booking_System/common/auth"
+	"clientManage/internal/data"
 	"clientManage/internal/domain/model"
 	"clientManage/internal/service"
 	pb "clientManage/proto"
 	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+const defaultListLimit = 20
+
 type ClientGRPCServer struct {
 	pb.UnimplementedClientManagementServiceServer
 	clientService *service.ClientService
@@ -16,87 +24,109 @@ func NewClientGRPCServer(clientService *service.ClientService) *ClientGRPCServer
 	return &ClientGRPCServer{clientService: clientService}
 }
 
+// toStatusError maps model and service errors to gRPC status codes so callers
+// can tell a missing client apart from a broken server.
+func toStatusError(err error) error {
+	var validationErr *service.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, data.ErrRecordNotFound):
+		return status.Error(codes.NotFound, "client not found")
+	case errors.Is(err, data.ErrEditConflict):
+		return status.Error(codes.Aborted, "client was modified concurrently, fetch it and try again")
+	case errors.Is(err, data.ErrDuplicateEmail):
+		return status.Error(codes.AlreadyExists, "a client with this email address already exists")
+	case errors.Is(err, data.ErrInvalidCursor):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrEventNotPublished):
+		return status.Error(codes.Unavailable, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func fromProto(client *pb.Client) *model.Client {
+	return &model.Client{
+		ID:        client.Id,
+		Name:      client.Fname,
+		Surname:   client.Sname,
+		Email:     client.Email,
+		Role:      client.UserRole,
+		Activated: client.Activated,
+		Version:   int(client.Version),
+	}
+}
+
+func toProto(client *model.Client) *pb.Client {
+	return &pb.Client{
+		Id:        client.ID,
+		Fname:     client.Name,
+		Sname:     client.Surname,
+		Email:     client.Email,
+		UserRole:  client.Role,
+		Activated: client.Activated,
+		Version:   int32(client.Version),
+	}
+}
+
+// CreateClient, UpdateClient and DeleteClient are for staff: they can set any
+// role and activate accounts.
 func (s *ClientGRPCServer) CreateClient(ctx context.Context, req *pb.CreateClientRequest) (*pb.CreateClientResponse, error) {
-	client := &model.Client{
-		ID:        req.Client.Id,
-		Name:      req.Client.Fname,
-		Surname:   req.Client.Sname,
-		Email:     req.Client.Email,
-		Role:      req.Client.UserRole,
-		Activated: req.Client.Activated,
-		Version:   int(req.Client.Version),
+	if _, err := auth.Require(ctx, auth.RoleAdmin, auth.RoleOperator); err != nil {
+		return nil, auth.StatusError(err)
 	}
+	if req.Client == nil {
+		return nil, status.Error(codes.InvalidArgument, "client is required")
+	}
+	client := fromProto(req.Client)
 
-	err := s.clientService.CreateClient(client)
+	err := s.clientService.CreateClient(client, req.Password)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
-	return &pb.CreateClientResponse{
-		Client: &pb.Client{
-			Id:        client.ID,
-			Fname:     client.Name,
-			Sname:     client.Surname,
-			Email:     client.Email,
-			UserRole:  client.Role,
-			Activated: client.Activated,
-			Version:   int32(client.Version),
-		},
-	}, nil
+	return &pb.CreateClientResponse{Client: toProto(client)}, nil
 }
 
+// GetClient and ListClients expose every client's details, so they are for
+// staff and for the other services, e.g. booking checking who books.
 func (s *ClientGRPCServer) GetClient(ctx context.Context, req *pb.GetClientRequest) (*pb.GetClientResponse, error) {
+	if _, err := auth.Require(ctx, auth.RoleAdmin, auth.RoleOperator, auth.RoleService); err != nil {
+		return nil, auth.StatusError(err)
+	}
 	client, err := s.clientService.GetClientByID(req.Id)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
-	return &pb.GetClientResponse{
-		Client: &pb.Client{
-			Id:        client.ID,
-			Fname:     client.Name,
-			Sname:     client.Surname,
-			Email:     client.Email,
-			UserRole:  client.Role,
-			Activated: client.Activated,
-			Version:   int32(client.Version),
-		},
-	}, nil
+	return &pb.GetClientResponse{Client: toProto(client)}, nil
 }
 
 func (s *ClientGRPCServer) UpdateClient(ctx context.Context, req *pb.UpdateClientRequest) (*pb.UpdateClientResponse, error) {
-	client := &model.Client{
-		ID:        req.Client.Id,
-		Name:      req.Client.Fname,
-		Surname:   req.Client.Sname,
-		Email:     req.Client.Email,
-		Role:      req.Client.UserRole,
-		Activated: req.Client.Activated,
-		Version:   int(req.Client.Version),
+	if _, err := auth.Require(ctx, auth.RoleAdmin, auth.RoleOperator); err != nil {
+		return nil, auth.StatusError(err)
 	}
+	if req.Client == nil {
+		return nil, status.Error(codes.InvalidArgument, "client is required")
+	}
+	client := fromProto(req.Client)
 
-	err := s.clientService.UpdateClient(client)
+	err := s.clientService.UpdateClient(client, req.GetUpdateMask().GetPaths())
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
-	return &pb.UpdateClientResponse{
-		Client: &pb.Client{
-			Id:        client.ID,
-			Fname:     client.Name,
-			Sname:     client.Surname,
-			Email:     client.Email,
-			UserRole:  client.Role,
-			Activated: client.Activated,
-			Version:   int32(client.Version),
-		},
-	}, nil
+	return &pb.UpdateClientResponse{Client: toProto(client)}, nil
 }
 
 func (s *ClientGRPCServer) DeleteClient(ctx context.Context, req *pb.DeleteClientRequest) (*pb.DeleteClientResponse, error) {
+	if _, err := auth.Require(ctx, auth.RoleAdmin, auth.RoleOperator); err != nil {
+		return nil, auth.StatusError(err)
+	}
 	err := s.clientService.DeleteClient(req.Id)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
 	return &pb.DeleteClientResponse{
@@ -106,31 +136,64 @@ func (s *ClientGRPCServer) DeleteClient(ctx context.Context, req *pb.DeleteClien
 	}, nil
 }
 
+// ListClients pages through clients. The only supported filter is
+// "user_role"; sort_by is one of id, fname or sname and sort_order is "asc"
+// or "desc".
 func (s *ClientGRPCServer) ListClients(ctx context.Context, req *pb.ListClientsRequest) (*pb.ListClientsResponse, error) {
-	filters := make(map[string]interface{})
+	if _, err := auth.Require(ctx, auth.RoleAdmin, auth.RoleOperator, auth.RoleService); err != nil {
+		return nil, auth.StatusError(err)
+	}
+	var role string
 	for _, filter := range req.Filters {
-		filters[filter.Key] = filter.Value
+		if filter.Key != "user_role" {
+			return nil, status.Errorf(codes.InvalidArgument, "unsupported filter %q", filter.Key)
+		}
+		role = filter.Value
 	}
 
-	clients, err := s.clientService.ListClients(int(req.Offset), int(req.Limit), filters, req.SortBy, req.SortOrder)
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if req.Offset < 0 || int(req.Offset)%limit != 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset must be a non-negative multiple of limit")
+	}
+
+	sort := req.SortBy
+	switch req.SortOrder {
+	case "", "asc":
+	case "desc":
+		if sort == "" {
+			sort = "id"
+		}
+		sort = "-" + sort
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported sort_order %q", req.SortOrder)
+	}
+
+	filters := data.Filters{
+		Page:     int(req.Offset)/limit + 1,
+		PageSize: limit,
+		Sort:     sort,
+		Cursor:   req.Cursor,
+	}
+
+	clients, metadata, err := s.clientService.ListClients(role, filters)
 	if err != nil {
-		return nil, err
+		return nil, toStatusError(err)
 	}
 
-	var clientResponses []*pb.Client
+	clientResponses := make([]*pb.Client, 0, len(clients))
 	for _, client := range clients {
-		clientResponses = append(clientResponses, &pb.Client{
-			Id:        client.ID,
-			Fname:     client.Name,
-			Sname:     client.Surname,
-			Email:     client.Email,
-			UserRole:  client.Role,
-			Activated: client.Activated,
-			Version:   int32(client.Version),
-		})
+		clientResponses = append(clientResponses, toProto(client))
 	}
 
 	return &pb.ListClientsResponse{
 		Clients: clientResponses,
+		Metadata: &pb.Metadata{
+			TotalRecords: int64(metadata.TotalRecords),
+			PageSize:     int64(metadata.PageSize),
+			NextCursor:   metadata.NextCursor,
+		},
 	}, nil
 }
@@ -2,77 +2,147 @@ package messaging
 
 import (
 	"clientManage/internal/data"
-	"encoding/json"
 	"log"
+	"time"
 
-	"github.com/streadway/amqp"
+	commonMessaging "Booking_System/common/messaging"
 )
 
+const userExchange = "user_exchange"
+
+// Routing keys of the events published on user_exchange.
+const (
+	UserCreated   = "user.created"
+	UserActivated = "user.activated"
+	UserUpdated   = "user.updated"
+	UserDeleted   = "user.deleted"
+)
+
+// UserEventVersion is the schema version of UserEvent. It only changes when
+// a field is removed or changes meaning; new fields are added without a bump,
+// so consumers should ignore fields they do not know.
+const UserEventVersion = 1
+
+// eventProducer names clientManage in the events it publishes.
+const eventProducer = "clientManage"
+
+// UserEvent is the payload of every user event. ID is always set; User holds
+// the state after the change and is left out of user.deleted.
+type UserEvent struct {
+	ID   int64      `json:"id"`
+	User *UserState `json:"user,omitempty"`
+}
+
+// UserState is the public part of a user, enough for other services to keep
+// a local copy. Credentials are never published.
+type UserState struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Fname     string    `json:"fname"`
+	Sname     string    `json:"sname"`
+	Email     string    `json:"email"`
+	Role      string    `json:"user_role"`
+	Activated bool      `json:"activated"`
+	Version   int       `json:"version"`
+}
+
+// NewUserEvent wraps the event of the given type for user in the common
+// event envelope. user may be nil for user.deleted.
+func NewUserEvent(eventType string, userID int64, user *data.User) commonMessaging.Event[UserEvent] {
+	payload := UserEvent{ID: userID}
+	if user != nil {
+		payload.User = &UserState{
+			ID:        user.ID,
+			CreatedAt: user.CreatedAt,
+			Fname:     user.Fname,
+			Sname:     user.Sname,
+			Email:     user.Email,
+			Role:      user.UserRole,
+			Activated: user.Activated,
+			Version:   user.Version,
+		}
+	}
+	return commonMessaging.NewEvent(eventType, UserEventVersion, eventProducer, payload)
+}
+
 type UserMessaging interface {
-	PublishUserCreated(user *data.UserModel) error
+	PublishUserCreated(user *data.User) error
+	PublishUserActivated(user *data.User) error
+	PublishUserUpdated(user *data.User) error
+	PublishUserDeleted(userID int64) error
 	Close() error
 }
 
 type UserMessagingImpl struct {
-	connection *amqp.Connection
-	channel    *amqp.Channel
+	rabbit    *commonMessaging.RabbitMQ
+	publisher *commonMessaging.Publisher
 }
 
+// NewUserMessaging connects to RabbitMQ. The connection recovers on its own
+// after a broker outage; events published while it is down fail fast with
+// commonMessaging.ErrDisconnected.
 func NewUserMessaging(rabbitMQUrl string) (*UserMessagingImpl, error) {
-	conn, err := amqp.Dial(rabbitMQUrl)
+	rabbit, err := commonMessaging.NewRabbitMQ(rabbitMQUrl)
 	if err != nil {
 		return nil, err
 	}
 
-	ch, err := conn.Channel()
+	publisher, err := commonMessaging.NewPublisher(rabbit, commonMessaging.PublisherConfig{
+		// Not mandatory: only user.deleted has a consumer so far, and the
+		// other events must not fail for lack of one.
+		Exchanges: []string{userExchange},
+	})
 	if err != nil {
+		rabbit.Close()
 		return nil, err
 	}
 
-	return &UserMessagingImpl{connection: conn, channel: ch}, nil
+	return &UserMessagingImpl{rabbit: rabbit, publisher: publisher}, nil
 }
 
-func (m *UserMessagingImpl) PublishUserCreated(user *data.UserModel) error {
-	err := m.channel.ExchangeDeclare(
-		"user_exchange",
-		"topic",
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
-	if err != nil {
-		return err
-	}
+func (m *UserMessagingImpl) PublishUserCreated(user *data.User) error {
+	return m.publish(NewUserEvent(UserCreated, user.ID, user))
+}
+
+func (m *UserMessagingImpl) PublishUserActivated(user *data.User) error {
+	return m.publish(NewUserEvent(UserActivated, user.ID, user))
+}
+
+// PublishUserUpdated announces a change to a user's profile or role.
+func (m *UserMessagingImpl) PublishUserUpdated(user *data.User) error {
+	return m.publish(NewUserEvent(UserUpdated, user.ID, user))
+}
 
-	body, err := json.Marshal(user)
+// PublishUserDeleted announces a deleted user so that other services can drop
+// or cancel what still refers to it.
+func (m *UserMessagingImpl) PublishUserDeleted(userID int64) error {
+	return m.publish(NewUserEvent(UserDeleted, userID, nil))
+}
+
+func (m *UserMessagingImpl) publish(event commonMessaging.Event[UserEvent]) error {
+	msg, err := event.Publishing()
 	if err != nil {
 		return err
 	}
 
-	err = m.channel.Publish(
-		"user_exchange",
-		"user.created",
-		false,
-		false,
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		},
-	)
+	err = m.publisher.Publish(userExchange, event.Type, msg)
 	if err != nil {
-		log.Printf("Failed to publish user created message: %v", err)
+		log.Printf("Failed to publish %s message: %v", event.Type, err)
 		return err
 	}
 
-	log.Printf("User created message published: %v", user)
+	log.Printf("%s message published for user %d", event.Type, event.Payload.ID)
 	return nil
 }
 
+// LogStats logs how the broker answered the published events every interval
+// until the connection is closed.
+func (m *UserMessagingImpl) LogStats(interval time.Duration) {
+	m.publisher.LogStats(interval)
+}
+
 func (m *UserMessagingImpl) Close() error {
-	if err := m.channel.Close(); err != nil {
-		return err
-	}
-	return m.connection.Close()
+	err := m.publisher.Close()
+	m.rabbit.Close()
+	return err
 }
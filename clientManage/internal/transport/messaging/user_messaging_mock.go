@@ -9,11 +9,26 @@ type UserMessagingMock struct {
 	mock.Mock
 }
 
-func (m *UserMessagingMock) PublishClientCreated(user *data.UserModel) error {
+func (m *UserMessagingMock) PublishUserCreated(user *data.User) error {
 	args := m.Called(user)
 	return args.Error(0)
 }
 
+func (m *UserMessagingMock) PublishUserActivated(user *data.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *UserMessagingMock) PublishUserUpdated(user *data.User) error {
+	args := m.Called(user)
+	return args.Error(0)
+}
+
+func (m *UserMessagingMock) PublishUserDeleted(userID int64) error {
+	args := m.Called(userID)
+	return args.Error(0)
+}
+
 func (m *UserMessagingMock) Close() error {
 	args := m.Called()
 	return args.Error(0)
@@ -0,0 +1,64 @@
+package unit
+
+import (
+	"Booking_System/common/auth"
+	grpcTransport "clientManage/internal/transport/grpc"
+	pb "clientManage/proto"
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Writes are refused before the service is reached, so the server needs none.
+func TestClientGRPCServer_WritesRequireStaff(t *testing.T) {
+	server := grpcTransport.NewClientGRPCServer(nil)
+	client := &pb.Client{Id: 1, Fname: "Jane", Sname: "Doe", Email: "jane@example.com", UserRole: "ADMIN", Activated: true}
+
+	contexts := map[string]struct {
+		ctx  context.Context
+		want codes.Code
+	}{
+		"anonymous": {context.Background(), codes.Unauthenticated},
+		"client":    {auth.WithIdentity(context.Background(), &auth.Identity{UserID: 1, Role: auth.RoleClient}), codes.PermissionDenied},
+	}
+	for name, tc := range contexts {
+		_, err := server.CreateClient(tc.ctx, &pb.CreateClientRequest{Client: client, Password: "pa55word"})
+		if status.Code(err) != tc.want {
+			t.Errorf("%s: CreateClient() code = %v, want %v", name, status.Code(err), tc.want)
+		}
+		_, err = server.UpdateClient(tc.ctx, &pb.UpdateClientRequest{Client: client})
+		if status.Code(err) != tc.want {
+			t.Errorf("%s: UpdateClient() code = %v, want %v", name, status.Code(err), tc.want)
+		}
+		_, err = server.DeleteClient(tc.ctx, &pb.DeleteClientRequest{Id: 1})
+		if status.Code(err) != tc.want {
+			t.Errorf("%s: DeleteClient() code = %v, want %v", name, status.Code(err), tc.want)
+		}
+	}
+}
+
+// Reads are refused before the service is reached as well; only staff and
+// other services may read clients.
+func TestClientGRPCServer_ReadsRequireStaffOrService(t *testing.T) {
+	server := grpcTransport.NewClientGRPCServer(nil)
+
+	contexts := map[string]struct {
+		ctx  context.Context
+		want codes.Code
+	}{
+		"anonymous": {context.Background(), codes.Unauthenticated},
+		"client":    {auth.WithIdentity(context.Background(), &auth.Identity{UserID: 1, Role: auth.RoleClient}), codes.PermissionDenied},
+	}
+	for name, tc := range contexts {
+		_, err := server.ListClients(tc.ctx, &pb.ListClientsRequest{})
+		if status.Code(err) != tc.want {
+			t.Errorf("%s: ListClients() code = %v, want %v", name, status.Code(err), tc.want)
+		}
+		_, err = server.GetClient(tc.ctx, &pb.GetClientRequest{Id: 1})
+		if status.Code(err) != tc.want {
+			t.Errorf("%s: GetClient() code = %v, want %v", name, status.Code(err), tc.want)
+		}
+	}
+}
@@ -1 +1,88 @@
 package unit
+
+import (
+	"clientManage/internal/data"
+	"clientManage/internal/domain/model"
+	"clientManage/internal/service"
+	"clientManage/internal/transport/messaging"
+	"errors"
+	"testing"
+)
+
+// Invalid input must be rejected before the service touches the database, so
+// these tests run against models without a connection.
+
+func TestClientService_CreateClientValidation(t *testing.T) {
+	svc := service.NewClientService(data.Models{}, new(messaging.UserMessagingMock))
+
+	client := &model.Client{Name: "", Surname: "Doe", Email: "not-an-email", Role: "CLIENT"}
+	err := svc.CreateClient(client, "pa55word")
+
+	var validationErr *service.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if _, ok := validationErr.Errors["fname"]; !ok {
+		t.Errorf("expected an error for fname, got %v", validationErr.Errors)
+	}
+	if _, ok := validationErr.Errors["email"]; !ok {
+		t.Errorf("expected an error for email, got %v", validationErr.Errors)
+	}
+}
+
+func TestClientService_CreateClientShortPassword(t *testing.T) {
+	svc := service.NewClientService(data.Models{}, new(messaging.UserMessagingMock))
+
+	client := &model.Client{Name: "Jane", Surname: "Doe", Email: "jane@example.com", Role: "CLIENT"}
+	err := svc.CreateClient(client, "short")
+
+	var validationErr *service.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if _, ok := validationErr.Errors["password"]; !ok {
+		t.Errorf("expected an error for password, got %v", validationErr.Errors)
+	}
+}
+
+func TestClientService_ListClientsValidation(t *testing.T) {
+	svc := service.NewClientService(data.Models{}, new(messaging.UserMessagingMock))
+
+	tests := []struct {
+		name    string
+		filters data.Filters
+		field   string
+	}{
+		{"unknown sort", data.Filters{Page: 1, PageSize: 20, Sort: "password_hash"}, "sort"},
+		{"page size too large", data.Filters{Page: 1, PageSize: 1000, Sort: "id"}, "pagesize"},
+		{"bad cursor", data.Filters{Page: 1, PageSize: 20, Sort: "id", Cursor: "!!!"}, "cursor"},
+	}
+
+	for _, tt := range tests {
+		_, _, err := svc.ListClients("", tt.filters)
+
+		var validationErr *service.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%s: expected a validation error, got %v", tt.name, err)
+			continue
+		}
+		if _, ok := validationErr.Errors[tt.field]; !ok {
+			t.Errorf("%s: expected an error for %s, got %v", tt.name, tt.field, validationErr.Errors)
+		}
+	}
+}
+
+func TestClientService_UpdateClientUnknownMaskPath(t *testing.T) {
+	svc := service.NewClientService(data.Models{}, new(messaging.UserMessagingMock))
+
+	client := &model.Client{ID: 1, Role: "ADMIN"}
+	err := svc.UpdateClient(client, []string{"email", "user_role"})
+
+	var validationErr *service.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if _, ok := validationErr.Errors["update_mask"]; !ok {
+		t.Errorf("expected an error for update_mask, got %v", validationErr.Errors)
+	}
+}
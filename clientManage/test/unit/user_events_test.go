@@ -0,0 +1,74 @@
+package unit
+
+import (
+	"clientManage/internal/data"
+	"clientManage/internal/transport/messaging"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewUserEvent(t *testing.T) {
+	user := &data.User{ID: 7, Fname: "Jane", Sname: "Doe", Email: "jane@example.com", UserRole: "CLIENT", Activated: true, Version: 2}
+	if err := user.Password.Set("pa55word"); err != nil {
+		t.Fatal(err)
+	}
+
+	body, err := json.Marshal(messaging.NewUserEvent(messaging.UserActivated, user.ID, user))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded["version"] != float64(messaging.UserEventVersion) {
+		t.Errorf("version = %v, want %d", decoded["version"], messaging.UserEventVersion)
+	}
+	if decoded["type"] != "user.activated" {
+		t.Errorf("type = %v, want user.activated", decoded["type"])
+	}
+	if decoded["producer"] != "clientManage" || decoded["event_id"] == "" || decoded["correlation_id"] != decoded["event_id"] {
+		t.Errorf("unexpected envelope %s", body)
+	}
+	payload, ok := decoded["payload"].(map[string]any)
+	if !ok {
+		t.Fatalf("payload missing from %s", body)
+	}
+	if payload["id"] != float64(7) {
+		t.Errorf("id = %v, want 7", payload["id"])
+	}
+	state, ok := payload["user"].(map[string]any)
+	if !ok {
+		t.Fatalf("user missing from %s", body)
+	}
+	if state["email"] != "jane@example.com" || state["user_role"] != "CLIENT" || state["activated"] != true {
+		t.Errorf("unexpected user state %v", state)
+	}
+	if strings.Contains(string(body), "password") {
+		t.Errorf("event leaks the password hash: %s", body)
+	}
+}
+
+func TestNewUserEventDeleted(t *testing.T) {
+	body, err := json.Marshal(messaging.NewUserEvent(messaging.UserDeleted, 7, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	payload, ok := decoded["payload"].(map[string]any)
+	if !ok {
+		t.Fatalf("payload missing from %s", body)
+	}
+	if payload["id"] != float64(7) {
+		t.Errorf("id = %v, want 7", payload["id"])
+	}
+	if _, ok := payload["user"]; ok {
+		t.Errorf("user.deleted should not carry user state: %s", body)
+	}
+}
@@ -0,0 +1,252 @@
+// Package auth verifies the access tokens clientManage issues and carries the
+// caller's identity through HTTP and gRPC requests.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Issuer is the iss claim of every access token. Tokens from anyone else are
+// rejected even if they carry a valid signature.
+const Issuer = "clientManage"
+
+const (
+	RoleAdmin    = "admin"
+	RoleOperator = "operator"
+	RoleClient   = "client"
+	// RoleService is held by the services themselves when they call one
+	// another, e.g. booking checking a client in clientManage.
+	RoleService = "service"
+)
+
+// ServiceTokenTTL is how long the tokens ServiceToken signs are valid. They
+// are signed for every call, so they only need to outlive one request.
+const ServiceTokenTTL = time.Minute
+
+var (
+	ErrInvalidToken    = errors.New("invalid or expired authentication token")
+	ErrUnauthenticated = errors.New("you must be authenticated to access this resource")
+	ErrForbidden       = errors.New("your user account doesn't have the necessary permissions to access this resource")
+)
+
+// Identity is the caller as vouched for by a token signed by clientManage.
+type Identity struct {
+	UserID      int64
+	Role        string
+	Permissions []string
+}
+
+// Actor is how the identity is recorded in audit trails such as the booking
+// status history.
+func (i *Identity) Actor() string {
+	return strconv.FormatInt(i.UserID, 10)
+}
+
+func (i *Identity) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if i.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStaff reports whether the identity may act on every client's data.
+func (i *Identity) IsStaff() bool {
+	return i.HasRole(RoleAdmin, RoleOperator)
+}
+
+func (i *Identity) HasPermission(code string) bool {
+	for _, permission := range i.Permissions {
+		if permission == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Claims is the payload of the access tokens clientManage issues.
+type Claims struct {
+	jwt.StandardClaims
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
+type Verifier struct {
+	secret []byte
+}
+
+func NewVerifier(secret string) *Verifier {
+	return &Verifier{secret: []byte(secret)}
+}
+
+// Verify checks the signature, issuer and expiry of an access token and
+// returns the identity it carries.
+func (v *Verifier) Verify(token string) (*Identity, error) {
+	var c Claims
+	parsed, err := jwt.ParseWithClaims(token, &c, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return v.secret, nil
+	})
+	if err != nil || !parsed.Valid || !c.VerifyIssuer(Issuer, true) {
+		return nil, ErrInvalidToken
+	}
+
+	userID, err := strconv.ParseInt(c.Subject, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return &Identity{UserID: userID, Role: c.Role, Permissions: c.Permissions}, nil
+}
+
+// ServiceToken signs a token with RoleService for one service to present to
+// another. Its subject is 0, which is no user.
+func ServiceToken(secret string) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		StandardClaims: jwt.StandardClaims{
+			Subject:   "0",
+			Issuer:    Issuer,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(ServiceTokenTTL).Unix(),
+		},
+		Role: RoleService,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+}
+
+// OutgoingContext returns ctx with a service token in the "authorization"
+// metadata of the gRPC calls made with it.
+func OutgoingContext(ctx context.Context, secret string) (context.Context, error) {
+	token, err := ServiceToken(secret)
+	if err != nil {
+		return nil, err
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token), nil
+}
+
+type contextKey string
+
+const identityContextKey = contextKey("identity")
+
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey, identity)
+}
+
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityContextKey).(*Identity)
+	return identity, ok
+}
+
+// Require returns the caller's identity if it has one of the given roles. It
+// returns ErrUnauthenticated for anonymous callers and ErrForbidden for
+// callers with another role.
+func Require(ctx context.Context, roles ...string) (*Identity, error) {
+	identity, ok := FromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthenticated
+	}
+	if !identity.HasRole(roles...) {
+		return nil, ErrForbidden
+	}
+	return identity, nil
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>"
+// value. ok is false if the value is malformed.
+func bearerToken(header string) (token string, ok bool) {
+	parts := strings.Split(header, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" || parts[1] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// Middleware attaches the identity from the Authorization header to the
+// request context. Requests without the header pass through anonymously;
+// requests with a bad token are rejected with 401.
+func (v *Verifier) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Authorization")
+
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := bearerToken(header)
+		if !ok {
+			unauthorized(w, ErrInvalidToken)
+			return
+		}
+
+		identity, err := v.Verify(token)
+		if err != nil {
+			unauthorized(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), identity)))
+	})
+}
+
+func unauthorized(w http.ResponseWriter, err error) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	http.Error(w, err.Error(), http.StatusUnauthorized)
+}
+
+// HTTPStatus maps the errors returned by Require to HTTP status codes.
+func HTTPStatus(err error) int {
+	if errors.Is(err, ErrForbidden) {
+		return http.StatusForbidden
+	}
+	return http.StatusUnauthorized
+}
+
+// UnaryServerInterceptor is the gRPC counterpart of Middleware. The token is
+// read from the "authorization" metadata key.
+func (v *Verifier) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get("authorization")
+		if len(values) == 0 {
+			return handler(ctx, req)
+		}
+
+		token, ok := bearerToken(values[0])
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, ErrInvalidToken.Error())
+		}
+
+		identity, err := v.Verify(token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		return handler(WithIdentity(ctx, identity), req)
+	}
+}
+
+// StatusError maps the errors returned by Require to gRPC status errors.
+func StatusError(err error) error {
+	if errors.Is(err, ErrForbidden) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return status.Error(codes.Unauthenticated, err.Error())
+}
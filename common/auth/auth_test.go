@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const testSecret = "test-secret"
+
+func signToken(t *testing.T, secret string, claims jwt.MapClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return token
+}
+
+func TestVerifyToken(t *testing.T) {
+	verifier := NewVerifier(testSecret)
+	token := signToken(t, testSecret, jwt.MapClaims{
+		"iss":         Issuer,
+		"sub":         "42",
+		"role":        "client",
+		"permissions": []string{"bookings:read"},
+		"exp":         time.Now().Add(time.Hour).Unix(),
+	})
+
+	identity, err := verifier.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if identity.UserID != 42 || identity.Role != RoleClient || identity.Actor() != "42" {
+		t.Errorf("Verify() = %+v", identity)
+	}
+	if !identity.HasPermission("bookings:read") {
+		t.Errorf("identity lacks bookings:read")
+	}
+}
+
+func TestVerifyRejectsBadTokens(t *testing.T) {
+	verifier := NewVerifier(testSecret)
+	exp := time.Now().Add(time.Hour).Unix()
+
+	tokens := map[string]string{
+		"expired":   signToken(t, testSecret, jwt.MapClaims{"iss": Issuer, "sub": "1", "role": "admin", "exp": time.Now().Add(-time.Minute).Unix()}),
+		"forged":    signToken(t, "other-secret", jwt.MapClaims{"iss": Issuer, "sub": "1", "role": "admin", "exp": exp}),
+		"no issuer": signToken(t, testSecret, jwt.MapClaims{"sub": "1", "role": "admin", "exp": exp}),
+		"issuer":    signToken(t, testSecret, jwt.MapClaims{"iss": "someone-else", "sub": "1", "role": "admin", "exp": exp}),
+		"garbage":   "garbage",
+	}
+	tokens["unsigned"], _ = jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"iss": Issuer, "sub": "1", "role": "admin"}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+
+	for name, token := range tokens {
+		if _, err := verifier.Verify(token); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("%s: Verify() error = %v, want %v", name, err, ErrInvalidToken)
+		}
+	}
+}
+
+func TestMiddlewareIgnoresRoleHeader(t *testing.T) {
+	verifier := NewVerifier(testSecret)
+	var gotErr error
+	handler := verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, gotErr = Require(r.Context(), RoleAdmin)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/bookings", nil)
+	req.Header.Set("Role", "admin")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !errors.Is(gotErr, ErrUnauthenticated) {
+		t.Errorf("Require() error = %v, want %v", gotErr, ErrUnauthenticated)
+	}
+}
+
+func TestMiddlewareRejectsInvalidToken(t *testing.T) {
+	verifier := NewVerifier(testSecret)
+	handler := verifier.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be called")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/bookings", nil)
+	req.Header.Set("Authorization", "Bearer not-a-token")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestRequireChecksRole(t *testing.T) {
+	ctx := WithIdentity(context.Background(), &Identity{UserID: 7, Role: RoleClient})
+
+	_, err := Require(ctx, RoleAdmin)
+	if !errors.Is(err, ErrForbidden) || HTTPStatus(err) != http.StatusForbidden {
+		t.Errorf("Require(admin) error = %v", err)
+	}
+
+	identity, err := Require(ctx, RoleClient, RoleAdmin)
+	if err != nil || identity.UserID != 7 {
+		t.Errorf("Require(client, admin) = %+v, %v", identity, err)
+	}
+}
+
+func TestServiceToken(t *testing.T) {
+	verifier := NewVerifier(testSecret)
+
+	ctx, err := OutgoingContext(context.Background(), testSecret)
+	if err != nil {
+		t.Fatalf("OutgoingContext() error = %v", err)
+	}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	var identity *Identity
+	interceptor := verifier.UnaryServerInterceptor()
+	_, err = interceptor(metadata.NewIncomingContext(context.Background(), md), nil, &grpc.UnaryServerInfo{},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			identity, err = Require(ctx, RoleService)
+			return nil, err
+		})
+	if err != nil {
+		t.Fatalf("Require() error = %v", err)
+	}
+	if identity.UserID != 0 || identity.IsStaff() {
+		t.Errorf("service identity = %+v", identity)
+	}
+
+	token, _ := ServiceToken("other-secret")
+	if _, err := verifier.Verify(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify() of a token signed with another secret: error = %v", err)
+	}
+}
@@ -0,0 +1,306 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// Headers the consumer sets when it retries or dead-letters a message.
+const (
+	HeaderRetryCount         = "x-retry-count"
+	HeaderOriginalExchange   = "x-original-exchange"
+	HeaderOriginalRoutingKey = "x-original-routing-key"
+	HeaderError              = "x-error"
+	HeaderFailedAt           = "x-failed-at"
+)
+
+// Defaults for the zero fields of ConsumerConfig.
+const (
+	DefaultPrefetch   = 10
+	DefaultMaxRetries = 5
+	DefaultBaseDelay  = time.Second
+	DefaultMaxDelay   = 5 * time.Minute
+)
+
+// Binding routes messages published on Exchange with a routing key matching
+// RoutingKey into the consumer's queue. Exchanges are declared as durable
+// topic exchanges.
+type Binding struct {
+	Exchange   string
+	RoutingKey string
+}
+
+// ConsumerConfig describes the queue a Consumer reads from and how it retries.
+type ConsumerConfig struct {
+	Queue    string
+	Bindings []Binding
+
+	// Prefetch limits how many unacknowledged messages the broker hands out.
+	Prefetch int
+	// MaxRetries is how often a failing message is retried before it is moved
+	// to the dead-letter queue.
+	MaxRetries int
+	// The n-th retry waits BaseDelay * 2^(n-1), but never longer than MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+func (c ConsumerConfig) withDefaults() ConsumerConfig {
+	if c.Prefetch <= 0 {
+		c.Prefetch = DefaultPrefetch
+	}
+	if c.MaxRetries <= 0 {
+		c.MaxRetries = DefaultMaxRetries
+	}
+	if c.BaseDelay <= 0 {
+		c.BaseDelay = DefaultBaseDelay
+	}
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = DefaultMaxDelay
+	}
+	return c
+}
+
+// RetryDelay returns how long the given retry (starting at 1) waits.
+func (c ConsumerConfig) RetryDelay(retry int) time.Duration {
+	c = c.withDefaults()
+	delay := c.BaseDelay
+	for i := 1; i < retry && delay < c.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > c.MaxDelay {
+		delay = c.MaxDelay
+	}
+	return delay
+}
+
+// RetryQueue is the name of the delay queue used for the given retry.
+func (c ConsumerConfig) RetryQueue(retry int) string {
+	return fmt.Sprintf("%s.retry.%d", c.Queue, retry)
+}
+
+// DeadLetterQueue is the name of the queue that collects poison messages.
+func (c ConsumerConfig) DeadLetterQueue() string {
+	return c.Queue + ".dlq"
+}
+
+// Delivery is a message handed to a Handler. RoutingKey and Exchange are the
+// ones the message was originally published with, also when it is retried.
+type Delivery struct {
+	Exchange   string
+	RoutingKey string
+	Headers    amqp.Table
+	Body       []byte
+	// Attempt is 1 on the first delivery and grows with every retry.
+	Attempt int
+}
+
+// Handler processes one message. Returning nil acknowledges it. Any other
+// error retries it later, unless it is wrapped with Permanent.
+type Handler func(d Delivery) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error that retrying cannot fix, such as a malformed
+// body. The message goes straight to the dead-letter queue.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// resumeDelay is how long a consumer waits before it opens a new channel
+// after losing one while the connection stayed up.
+const resumeDelay = time.Second
+
+// Consumer reads a queue with manual acknowledgements. Failed messages are
+// parked in per-retry delay queues that dead-letter back into the queue once
+// their TTL expires, and end up in the dead-letter queue, together with the
+// last error, when retries run out.
+type Consumer struct {
+	rabbit  *RabbitMQ
+	config  ConsumerConfig
+	handler Handler
+}
+
+func NewConsumer(rabbit *RabbitMQ, config ConsumerConfig, handler Handler) *Consumer {
+	return &Consumer{rabbit: rabbit, config: config.withDefaults(), handler: handler}
+}
+
+// declare declares the queue, its bindings, the delay queues and the
+// dead-letter queue. It runs again on every new channel, so the topology is
+// restored after the broker lost it.
+func (c *Consumer) declare(ch *amqp.Channel) error {
+	cfg := c.config
+
+	if err := ch.Qos(cfg.Prefetch, 0, false); err != nil {
+		return err
+	}
+
+	if _, err := ch.QueueDeclare(cfg.Queue, true, false, false, false, nil); err != nil {
+		return err
+	}
+	for _, binding := range cfg.Bindings {
+		if err := ch.ExchangeDeclare(binding.Exchange, "topic", true, false, false, false, nil); err != nil {
+			return err
+		}
+		if err := ch.QueueBind(cfg.Queue, binding.RoutingKey, binding.Exchange, false, nil); err != nil {
+			return err
+		}
+	}
+
+	for retry := 1; retry <= cfg.MaxRetries; retry++ {
+		args := amqp.Table{
+			"x-message-ttl":             int64(cfg.RetryDelay(retry) / time.Millisecond),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": cfg.Queue,
+		}
+		if _, err := ch.QueueDeclare(cfg.RetryQueue(retry), true, false, false, false, args); err != nil {
+			return err
+		}
+	}
+
+	_, err := ch.QueueDeclare(cfg.DeadLetterQueue(), true, false, false, false, nil)
+	return err
+}
+
+// Run consumes messages until the RabbitMQ connection is closed. Whenever the
+// channel or the connection drops, it waits for the broker, declares the
+// topology again and resumes.
+func (c *Consumer) Run() error {
+	for {
+		ch, err := c.rabbit.WaitChannel()
+		if err == nil {
+			err = c.consume(ch)
+		}
+		if errors.Is(err, ErrClosed) {
+			return nil
+		}
+		log.Printf("Consumer for %s stopped, resuming: %v", c.config.Queue, err)
+
+		select {
+		case <-c.rabbit.done:
+			return nil
+		case <-time.After(resumeDelay):
+		}
+	}
+}
+
+// consume reads deliveries from ch until it is closed.
+func (c *Consumer) consume(ch *amqp.Channel) error {
+	defer ch.Close()
+
+	if err := c.declare(ch); err != nil {
+		return err
+	}
+
+	msgs, err := ch.Consume(
+		c.config.Queue, // queue
+		"",             // consumer
+		false,          // auto-ack
+		false,          // exclusive
+		false,          // no-local
+		false,          // no-wait
+		nil,            // args
+	)
+	if err != nil {
+		return err
+	}
+
+	for msg := range msgs {
+		c.process(ch, msg)
+	}
+	return errors.New("channel closed")
+}
+
+// publishChannel is the part of an AMQP channel that process needs to park a
+// failed message. *amqp.Channel implements it; tests use a fake broker.
+type publishChannel interface {
+	Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+}
+
+// process hands msg to the handler and acknowledges it once it was handled,
+// moved to a retry queue or moved to the dead-letter queue. If it cannot be
+// moved it is requeued instead.
+func (c *Consumer) process(ch publishChannel, msg amqp.Delivery) {
+	retries := headerInt(msg.Headers, HeaderRetryCount)
+	d := Delivery{
+		Exchange:   headerString(msg.Headers, HeaderOriginalExchange, msg.Exchange),
+		RoutingKey: headerString(msg.Headers, HeaderOriginalRoutingKey, msg.RoutingKey),
+		Headers:    msg.Headers,
+		Body:       msg.Body,
+		Attempt:    retries + 1,
+	}
+
+	err := c.handler(d)
+	if err == nil {
+		_ = msg.Ack(false)
+		return
+	}
+
+	target := c.config.RetryQueue(retries + 1)
+	if IsPermanent(err) || retries >= c.config.MaxRetries {
+		target = c.config.DeadLetterQueue()
+		log.Printf("Moving %s message to %s after %d attempts: %v", d.RoutingKey, target, d.Attempt, err)
+	} else {
+		log.Printf("Retrying %s message in %s (attempt %d): %v", d.RoutingKey, c.config.RetryDelay(retries+1), d.Attempt, err)
+	}
+
+	headers := amqp.Table{}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[HeaderRetryCount] = int32(retries + 1)
+	headers[HeaderOriginalExchange] = d.Exchange
+	headers[HeaderOriginalRoutingKey] = d.RoutingKey
+	headers[HeaderError] = err.Error()
+	headers[HeaderFailedAt] = time.Now().UTC().Format(time.RFC3339)
+
+	err = ch.Publish("", target, false, false, amqp.Publishing{
+		Headers:      headers,
+		ContentType:  msg.ContentType,
+		DeliveryMode: amqp.Persistent,
+		MessageId:    msg.MessageId,
+		Timestamp:    msg.Timestamp,
+		Body:         msg.Body,
+	})
+	if err != nil {
+		// Leave the message with the broker rather than lose it.
+		log.Printf("Failed to move %s message to %s: %v", d.RoutingKey, target, err)
+		_ = msg.Nack(false, true)
+		return
+	}
+	_ = msg.Ack(false)
+}
+
+func headerInt(headers amqp.Table, key string) int {
+	switch v := headers[key].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
+
+func headerString(headers amqp.Table, key, fallback string) string {
+	if v, ok := headers[key].(string); ok && v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,180 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+func TestRetryDelay(t *testing.T) {
+	cfg := ConsumerConfig{Queue: "q", BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, w := range want {
+		if got := cfg.RetryDelay(i + 1); got != w {
+			t.Errorf("RetryDelay(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+}
+
+func TestConsumerConfigNames(t *testing.T) {
+	cfg := ConsumerConfig{Queue: "booking_deletions"}
+	if got := cfg.RetryQueue(2); got != "booking_deletions.retry.2" {
+		t.Errorf("RetryQueue(2) = %q", got)
+	}
+	if got := cfg.DeadLetterQueue(); got != "booking_deletions.dlq" {
+		t.Errorf("DeadLetterQueue() = %q", got)
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	base := errors.New("bad payload")
+	err := fmt.Errorf("handling: %w", Permanent(base))
+
+	if !IsPermanent(err) {
+		t.Errorf("IsPermanent(%v) = false, want true", err)
+	}
+	if !errors.Is(err, base) {
+		t.Errorf("Permanent should keep the wrapped error")
+	}
+	if IsPermanent(base) {
+		t.Errorf("IsPermanent(%v) = true, want false", base)
+	}
+}
+
+type publishedMessage struct {
+	queue string
+	msg   amqp.Publishing
+}
+
+// fakeBroker records what a consumer publishes and how it settles deliveries.
+type fakeBroker struct {
+	published  []publishedMessage
+	publishErr error
+	acked      int
+	nacked     int
+	requeued   bool
+}
+
+func (b *fakeBroker) Publish(exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	if b.publishErr != nil {
+		return b.publishErr
+	}
+	b.published = append(b.published, publishedMessage{queue: key, msg: msg})
+	return nil
+}
+
+func (b *fakeBroker) Ack(tag uint64, multiple bool) error {
+	b.acked++
+	return nil
+}
+
+func (b *fakeBroker) Nack(tag uint64, multiple, requeue bool) error {
+	b.nacked++
+	b.requeued = requeue
+	return nil
+}
+
+func (b *fakeBroker) Reject(tag uint64, requeue bool) error {
+	return b.Nack(tag, false, requeue)
+}
+
+// deliver runs one delivery with the given retry count through a consumer of
+// booking_deletions that allows two retries.
+func deliver(broker *fakeBroker, retries int, handler Handler) {
+	c := NewConsumer(nil, ConsumerConfig{Queue: "booking_deletions", MaxRetries: 2}, handler)
+	msg := amqp.Delivery{
+		Acknowledger: broker,
+		Exchange:     "user_exchange",
+		RoutingKey:   "user.deleted",
+		Body:         []byte(`{"id": 7}`),
+	}
+	if retries > 0 {
+		// Retried messages come back through the default exchange.
+		msg.Exchange, msg.RoutingKey = "", "booking_deletions"
+		msg.Headers = amqp.Table{
+			HeaderRetryCount:         int32(retries),
+			HeaderOriginalExchange:   "user_exchange",
+			HeaderOriginalRoutingKey: "user.deleted",
+		}
+	}
+	c.process(broker, msg)
+}
+
+func TestProcessAcksHandledMessage(t *testing.T) {
+	broker := &fakeBroker{}
+	var got Delivery
+	deliver(broker, 0, func(d Delivery) error {
+		got = d
+		return nil
+	})
+
+	if broker.acked != 1 || broker.nacked != 0 || len(broker.published) != 0 {
+		t.Errorf("acked %d, nacked %d, published %d; want one ack only", broker.acked, broker.nacked, len(broker.published))
+	}
+	if got.Exchange != "user_exchange" || got.RoutingKey != "user.deleted" || got.Attempt != 1 {
+		t.Errorf("handler got %+v", got)
+	}
+}
+
+func TestProcessRetriesThenDeadLetters(t *testing.T) {
+	failure := errors.New("database unavailable")
+
+	for retries, want := range []string{"booking_deletions.retry.1", "booking_deletions.retry.2", "booking_deletions.dlq"} {
+		broker := &fakeBroker{}
+		var attempt int
+		deliver(broker, retries, func(d Delivery) error {
+			attempt = d.Attempt
+			return failure
+		})
+
+		if attempt != retries+1 {
+			t.Errorf("retry %d: handler saw attempt %d, want %d", retries, attempt, retries+1)
+		}
+		if broker.acked != 1 || len(broker.published) != 1 {
+			t.Fatalf("retry %d: acked %d, published %d; want one each", retries, broker.acked, len(broker.published))
+		}
+		published := broker.published[0]
+		if published.queue != want {
+			t.Errorf("retry %d: moved to %q, want %q", retries, published.queue, want)
+		}
+		headers := published.msg.Headers
+		if headers[HeaderRetryCount] != int32(retries+1) {
+			t.Errorf("retry %d: %s = %v, want %d", retries, HeaderRetryCount, headers[HeaderRetryCount], retries+1)
+		}
+		if headers[HeaderOriginalExchange] != "user_exchange" || headers[HeaderOriginalRoutingKey] != "user.deleted" {
+			t.Errorf("retry %d: original exchange and routing key not kept: %v", retries, headers)
+		}
+		if headers[HeaderError] != failure.Error() {
+			t.Errorf("retry %d: %s = %v, want %q", retries, HeaderError, headers[HeaderError], failure)
+		}
+	}
+}
+
+func TestProcessDeadLettersPermanentErrors(t *testing.T) {
+	broker := &fakeBroker{}
+	deliver(broker, 0, func(d Delivery) error {
+		return Permanent(errors.New("bad payload"))
+	})
+
+	if broker.acked != 1 || len(broker.published) != 1 {
+		t.Fatalf("acked %d, published %d; want one each", broker.acked, len(broker.published))
+	}
+	if got := broker.published[0].queue; got != "booking_deletions.dlq" {
+		t.Errorf("moved to %q, want booking_deletions.dlq", got)
+	}
+}
+
+func TestProcessRequeuesWhenMoveFails(t *testing.T) {
+	broker := &fakeBroker{publishErr: errors.New("channel closed")}
+	deliver(broker, 0, func(d Delivery) error {
+		return errors.New("database unavailable")
+	})
+
+	if broker.acked != 0 || broker.nacked != 1 || !broker.requeued {
+		t.Errorf("acked %d, nacked %d, requeued %t; want one requeueing nack", broker.acked, broker.nacked, broker.requeued)
+	}
+}
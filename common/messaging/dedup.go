@@ -0,0 +1,98 @@
+package messaging
+
+import (
+	"log"
+	"sync"
+)
+
+// DefaultDedupCapacity is how many event IDs a MemoryDeduplicator created
+// with a non-positive capacity remembers.
+const DefaultDedupCapacity = 10000
+
+// Deduplicator remembers the IDs of the events a consumer has handled.
+type Deduplicator interface {
+	Seen(eventID string) (bool, error)
+	Remember(eventID string) error
+}
+
+// Deduplicate skips events whose event_id dedup has seen before and remembers
+// the ones handler processed successfully. Delivery stays at-least-once: an
+// event can still be handled twice if the consumer stops between handling it
+// and remembering it, so handlers should remain idempotent.
+func Deduplicate(dedup Deduplicator, handler Handler) Handler {
+	return func(d Delivery) error {
+		event, err := Decode[struct{}](d.Body)
+		if err != nil {
+			return Permanent(err)
+		}
+
+		seen, err := dedup.Seen(event.EventID)
+		if err != nil {
+			return err
+		}
+		if seen {
+			log.Printf("Skipping duplicate %s event %s", event.Type, event.EventID)
+			return nil
+		}
+
+		if err := handler(d); err != nil {
+			return err
+		}
+
+		// The event was handled; failing now would only handle it again.
+		if err := dedup.Remember(event.EventID); err != nil {
+			log.Printf("Failed to remember %s event %s: %v", event.Type, event.EventID, err)
+		}
+		return nil
+	}
+}
+
+// MemoryDeduplicator remembers the most recent event IDs in memory. It
+// forgets everything on restart, which covers the usual redeliveries after a
+// lost acknowledgement or a relay retry.
+type MemoryDeduplicator struct {
+	mu       sync.Mutex
+	capacity int
+	seen     map[string]struct{}
+	// order holds the remembered IDs oldest first, as a ring of capacity.
+	order []string
+	next  int
+}
+
+func NewMemoryDeduplicator(capacity int) *MemoryDeduplicator {
+	if capacity <= 0 {
+		capacity = DefaultDedupCapacity
+	}
+	return &MemoryDeduplicator{
+		capacity: capacity,
+		seen:     make(map[string]struct{}, capacity),
+		order:    make([]string, 0, capacity),
+	}
+}
+
+func (m *MemoryDeduplicator) Seen(eventID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.seen[eventID]
+	return ok, nil
+}
+
+// Remember adds eventID, forgetting the oldest ID once capacity is reached.
+func (m *MemoryDeduplicator) Remember(eventID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.seen[eventID]; ok {
+		return nil
+	}
+	if len(m.order) < m.capacity {
+		m.order = append(m.order, eventID)
+	} else {
+		delete(m.seen, m.order[m.next])
+		m.order[m.next] = eventID
+		m.next = (m.next + 1) % m.capacity
+	}
+	m.seen[eventID] = struct{}{}
+	return nil
+}
@@ -0,0 +1,133 @@
+package messaging
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+var (
+	// ErrInvalidEvent is returned for bodies that are not an event envelope.
+	ErrInvalidEvent = errors.New("messaging: invalid event envelope")
+	// ErrUnsupportedVersion is returned for events newer than the consumer
+	// understands.
+	ErrUnsupportedVersion = errors.New("messaging: unsupported event version")
+)
+
+// Event is the envelope every service publishes its events in. Type is the
+// routing key the event is published with. Version is the schema version of
+// Payload; it only changes when a field is removed or changes meaning, so
+// consumers should ignore payload fields they do not know.
+//
+// CorrelationID ties together the events that one request caused across
+// services: an event that reacts to another one carries the other's
+// correlation ID, every other event starts a new one equal to its EventID.
+type Event[T any] struct {
+	EventID       string    `json:"event_id"`
+	Type          string    `json:"type"`
+	Version       int       `json:"version"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	Producer      string    `json:"producer"`
+	CorrelationID string    `json:"correlation_id"`
+	Payload       T         `json:"payload"`
+}
+
+// Envelope is an event whose payload has not been decoded yet.
+type Envelope = Event[json.RawMessage]
+
+// NewEvent wraps payload in a new event with a fresh ID.
+func NewEvent[T any](eventType string, version int, producer string, payload T) Event[T] {
+	id := newEventID()
+	return Event[T]{
+		EventID:       id,
+		Type:          eventType,
+		Version:       version,
+		OccurredAt:    time.Now().UTC(),
+		Producer:      producer,
+		CorrelationID: id,
+		Payload:       payload,
+	}
+}
+
+// CausedBy returns e as part of the flow identified by correlationID. An empty
+// correlationID leaves e unchanged.
+func (e Event[T]) CausedBy(correlationID string) Event[T] {
+	if correlationID != "" {
+		e.CorrelationID = correlationID
+	}
+	return e
+}
+
+// CheckVersion fails with ErrUnsupportedVersion if e is newer than
+// maxVersion.
+func (e Event[T]) CheckVersion(maxVersion int) error {
+	if e.Version > maxVersion {
+		return fmt.Errorf("%w: %s v%d, want at most v%d", ErrUnsupportedVersion, e.Type, e.Version, maxVersion)
+	}
+	return nil
+}
+
+// Publishing encodes e and copies its ID, type, producer and correlation ID
+// into the AMQP message properties, so they can be read without decoding the
+// body.
+func (e Event[T]) Publishing() (amqp.Publishing, error) {
+	body, err := Encode(e)
+	if err != nil {
+		return amqp.Publishing{}, err
+	}
+	return amqp.Publishing{
+		ContentType:   "application/json",
+		DeliveryMode:  amqp.Persistent,
+		MessageId:     e.EventID,
+		CorrelationId: e.CorrelationID,
+		Type:          e.Type,
+		AppId:         e.Producer,
+		Timestamp:     e.OccurredAt,
+		Body:          body,
+	}, nil
+}
+
+// Encode marshals e after checking that its envelope fields are set.
+func Encode[T any](e Event[T]) ([]byte, error) {
+	if err := e.validate(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(e)
+}
+
+// Decode unmarshals an event with a payload of type T. Errors are not worth
+// retrying, so consumers usually wrap them with Permanent.
+func Decode[T any](body []byte) (Event[T], error) {
+	var e Event[T]
+	if err := json.Unmarshal(body, &e); err != nil {
+		return e, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	return e, e.validate()
+}
+
+func (e Event[T]) validate() error {
+	switch {
+	case e.EventID == "":
+		return fmt.Errorf("%w: missing event_id", ErrInvalidEvent)
+	case e.Type == "":
+		return fmt.Errorf("%w: missing type", ErrInvalidEvent)
+	case e.Version < 1:
+		return fmt.Errorf("%w: missing version", ErrInvalidEvent)
+	}
+	return nil
+}
+
+// newEventID returns a random (version 4) UUID.
+func newEventID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("messaging: reading random event id: %v", err))
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
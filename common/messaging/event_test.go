@@ -0,0 +1,110 @@
+package messaging
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+type roomPayload struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestEncodeDecode(t *testing.T) {
+	event := NewEvent("room.created", 1, "roomManage", roomPayload{ID: "7", Name: "Blue"})
+
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(event.EventID) {
+		t.Errorf("EventID = %q, want a UUID", event.EventID)
+	}
+	if event.CorrelationID != event.EventID {
+		t.Errorf("CorrelationID = %q, want the event's own ID %q", event.CorrelationID, event.EventID)
+	}
+
+	body, err := Encode(event.CausedBy("flow-1"))
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	decoded, err := Decode[roomPayload](body)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if decoded.EventID != event.EventID || decoded.Type != "room.created" || decoded.Version != 1 ||
+		decoded.Producer != "roomManage" || decoded.CorrelationID != "flow-1" || decoded.Payload != event.Payload {
+		t.Errorf("Decode() = %+v, want %+v with correlation flow-1", decoded, event)
+	}
+	if !decoded.OccurredAt.Equal(event.OccurredAt) {
+		t.Errorf("OccurredAt = %s, want %s", decoded.OccurredAt, event.OccurredAt)
+	}
+}
+
+func TestDecodeRejectsInvalidEvents(t *testing.T) {
+	bodies := map[string]string{
+		"not json":        `{`,
+		"bare payload":    `{"id": "7", "name": "Blue"}`,
+		"missing type":    `{"event_id": "e1", "version": 1, "payload": {}}`,
+		"missing version": `{"event_id": "e1", "type": "room.created", "payload": {}}`,
+	}
+	for name, body := range bodies {
+		if _, err := Decode[roomPayload]([]byte(body)); !errors.Is(err, ErrInvalidEvent) {
+			t.Errorf("%s: Decode() error = %v, want ErrInvalidEvent", name, err)
+		}
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	event := NewEvent("room.created", 2, "roomManage", roomPayload{})
+	if err := event.CheckVersion(2); err != nil {
+		t.Errorf("CheckVersion(2) error = %v", err)
+	}
+	if err := event.CheckVersion(1); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("CheckVersion(1) error = %v, want ErrUnsupportedVersion", err)
+	}
+}
+
+func TestDeduplicate(t *testing.T) {
+	handled := 0
+	fail := true
+	handler := Deduplicate(NewMemoryDeduplicator(2), func(d Delivery) error {
+		handled++
+		if fail {
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	body, _ := Encode(NewEvent("room.deleted", 1, "roomManage", roomPayload{ID: "7"}))
+
+	// A failed attempt must not be remembered, or the retry would be skipped.
+	if err := handler(Delivery{Body: body}); err == nil {
+		t.Fatal("expected the handler's error")
+	}
+	fail = false
+	if err := handler(Delivery{Body: body}); err != nil {
+		t.Fatalf("retry error = %v", err)
+	}
+	if err := handler(Delivery{Body: body}); err != nil {
+		t.Fatalf("duplicate error = %v", err)
+	}
+	if handled != 2 {
+		t.Errorf("handler ran %d times, want 2", handled)
+	}
+
+	if err := handler(Delivery{Body: []byte(`{"id": "7"}`)}); !IsPermanent(err) {
+		t.Errorf("malformed event error = %v, want a permanent error", err)
+	}
+}
+
+func TestMemoryDeduplicatorForgetsOldest(t *testing.T) {
+	dedup := NewMemoryDeduplicator(2)
+	for _, id := range []string{"a", "b", "c"} {
+		_ = dedup.Remember(id)
+	}
+
+	for id, want := range map[string]bool{"a": false, "b": true, "c": true} {
+		if seen, _ := dedup.Seen(id); seen != want {
+			t.Errorf("Seen(%q) = %t, want %t", id, seen, want)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+// DefaultConfirmTimeout is used when PublisherConfig.ConfirmTimeout is zero.
+const DefaultConfirmTimeout = 5 * time.Second
+
+var (
+	// ErrNacked is returned when the broker refused to take responsibility
+	// for a message.
+	ErrNacked = errors.New("messaging: message nacked by the broker")
+	// ErrUnroutable is returned for mandatory messages that no queue is bound
+	// to receive.
+	ErrUnroutable = errors.New("messaging: message returned as unroutable")
+	// ErrConfirmTimeout is returned when the broker did not confirm a message
+	// in time. The message may or may not have been delivered.
+	ErrConfirmTimeout = errors.New("messaging: timed out waiting for publisher confirm")
+)
+
+// PublisherConfig describes where a Publisher publishes and how strictly.
+type PublisherConfig struct {
+	// Exchanges are declared as durable topic exchanges on every new channel.
+	Exchanges []string
+	// Mandatory makes the broker return messages that no queue is bound to
+	// receive instead of dropping them; Publish then fails with ErrUnroutable.
+	Mandatory bool
+	// ConfirmTimeout bounds how long Publish waits for the broker's ack.
+	ConfirmTimeout time.Duration
+}
+
+// PublishStats counts the outcome of the messages published on one exchange.
+type PublishStats struct {
+	// Published counts every call to Publish, whatever its outcome.
+	Published uint64
+	Confirmed uint64
+	Nacked    uint64
+	Returned  uint64
+	TimedOut  uint64
+	// Failed counts messages that never reached the broker, for instance
+	// because the connection was down.
+	Failed uint64
+}
+
+func (s PublishStats) String() string {
+	return fmt.Sprintf("published %d, confirmed %d, nacked %d, returned %d, timed out %d, failed %d",
+		s.Published, s.Confirmed, s.Nacked, s.Returned, s.TimedOut, s.Failed)
+}
+
+// Publisher publishes on a channel of its own in confirm mode: Publish only
+// returns nil once the broker acknowledged the message, so nothing is dropped
+// silently. After the connection was re-established it opens a new channel
+// and declares its exchanges again. While the broker is unreachable Publish
+// fails fast with ErrDisconnected instead of buffering, so callers decide
+// whether to keep the message (the booking outbox does) or drop it.
+type Publisher struct {
+	rabbit *RabbitMQ
+	config PublisherConfig
+
+	mu       sync.Mutex
+	channel  *amqp.Channel
+	confirms chan amqp.Confirmation
+	returns  chan amqp.Return
+	// nextTag is the delivery tag the broker assigns to the next message.
+	nextTag uint64
+	stats   map[string]*PublishStats
+}
+
+// NewPublisher returns a confirmed publisher and declares its exchanges right
+// away.
+func NewPublisher(rabbit *RabbitMQ, config PublisherConfig) (*Publisher, error) {
+	if config.ConfirmTimeout <= 0 {
+		config.ConfirmTimeout = DefaultConfirmTimeout
+	}
+	p := &Publisher{rabbit: rabbit, config: config, stats: make(map[string]*PublishStats)}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := p.openChannel(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Publish sends msg and waits until the broker confirmed it. Messages are
+// published one at a time, so a slow confirm holds up the next Publish for at
+// most the confirm timeout.
+func (p *Publisher) Publish(exchange, routingKey string, msg amqp.Publishing) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.statsFor(exchange)
+	stats.Published++
+
+	ch, err := p.openChannel()
+	if err != nil {
+		stats.Failed++
+		return err
+	}
+
+	if err := ch.Publish(exchange, routingKey, p.config.Mandatory, false, msg); err != nil {
+		stats.Failed++
+		p.resetChannel()
+		return err
+	}
+	tag := p.nextTag
+	p.nextTag++
+
+	timeout := time.NewTimer(p.config.ConfirmTimeout)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case confirm, ok := <-p.confirms:
+			if !ok {
+				stats.Failed++
+				p.resetChannel()
+				return fmt.Errorf("messaging: channel closed before %s message was confirmed", routingKey)
+			}
+			if confirm.DeliveryTag < tag {
+				continue
+			}
+			if !confirm.Ack {
+				stats.Nacked++
+				return ErrNacked
+			}
+			// The broker sends a return before the ack of the same message.
+			select {
+			case <-p.returns:
+				stats.Returned++
+				return fmt.Errorf("%w: %s on %q", ErrUnroutable, routingKey, exchange)
+			default:
+			}
+			stats.Confirmed++
+			return nil
+		case <-timeout.C:
+			stats.TimedOut++
+			// A late confirm must not be taken for the next message's.
+			p.resetChannel()
+			return ErrConfirmTimeout
+		}
+	}
+}
+
+// Stats returns a snapshot of the publish counters, keyed by exchange. The
+// default exchange is listed under "".
+func (p *Publisher) Stats() map[string]PublishStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	snapshot := make(map[string]PublishStats, len(p.stats))
+	for exchange, stats := range p.stats {
+		snapshot[exchange] = *stats
+	}
+	return snapshot
+}
+
+// LogStats logs the publish counters of every exchange each interval until
+// the RabbitMQ connection is closed. Run it in a goroutine of its own.
+func (p *Publisher) LogStats(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.rabbit.done:
+			return
+		case <-ticker.C:
+			stats := p.Stats()
+			exchanges := make([]string, 0, len(stats))
+			for exchange := range stats {
+				exchanges = append(exchanges, exchange)
+			}
+			sort.Strings(exchanges)
+			for _, exchange := range exchanges {
+				log.Printf("Publish stats for %q: %s", exchange, stats[exchange])
+			}
+		}
+	}
+}
+
+// Defaults for the zero arguments of PublishWithRetry.
+const (
+	DefaultPublishAttempts   = 3
+	DefaultPublishRetryDelay = 200 * time.Millisecond
+)
+
+// PublishWithRetry calls publish until it succeeds, at most attempts times,
+// waiting delay after the first failure and twice as long after each further
+// one. It is meant for events that have no outbox behind them but must not be
+// dropped silently: if every attempt fails the last error is returned, so the
+// caller can refuse the change it wanted to announce.
+func PublishWithRetry(attempts int, delay time.Duration, publish func() error) error {
+	if attempts <= 0 {
+		attempts = DefaultPublishAttempts
+	}
+	if delay <= 0 {
+		delay = DefaultPublishRetryDelay
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = publish(); err == nil {
+			return nil
+		}
+		if errors.Is(err, ErrClosed) || attempt == attempts {
+			break
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+	return err
+}
+
+func (p *Publisher) statsFor(exchange string) *PublishStats {
+	stats, ok := p.stats[exchange]
+	if !ok {
+		stats = &PublishStats{}
+		p.stats[exchange] = stats
+	}
+	return stats
+}
+
+// openChannel returns the current channel, opening a new one in confirm mode
+// if the last one was closed. p.mu must be held.
+func (p *Publisher) openChannel() (*amqp.Channel, error) {
+	if p.channel != nil {
+		return p.channel, nil
+	}
+
+	ch, err := p.rabbit.Channel()
+	if err != nil {
+		return nil, err
+	}
+	for _, exchange := range p.config.Exchanges {
+		if err := ch.ExchangeDeclare(exchange, "topic", true, false, false, false, nil); err != nil {
+			_ = ch.Close()
+			return nil, err
+		}
+	}
+	if err := ch.Confirm(false); err != nil {
+		_ = ch.Close()
+		return nil, err
+	}
+
+	// Publishing is serialized, so one buffered confirm and return are enough
+	// to never block the connection's reader.
+	p.confirms = ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+	p.returns = ch.NotifyReturn(make(chan amqp.Return, 1))
+	p.nextTag = 1
+
+	closed := ch.NotifyClose(make(chan *amqp.Error, 1))
+	go func() {
+		<-closed
+		p.mu.Lock()
+		if p.channel == ch {
+			p.channel = nil
+		}
+		p.mu.Unlock()
+	}()
+
+	p.channel = ch
+	return ch, nil
+}
+
+// resetChannel drops the current channel so the next Publish starts over on a
+// fresh one. p.mu must be held.
+func (p *Publisher) resetChannel() {
+	if p.channel == nil {
+		return
+	}
+	_ = p.channel.Close()
+	p.channel = nil
+}
+
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.channel == nil {
+		return nil
+	}
+	err := p.channel.Close()
+	p.channel = nil
+	return err
+}
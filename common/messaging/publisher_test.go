@@ -0,0 +1,73 @@
+package messaging
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+func TestPublishWhileDisconnected(t *testing.T) {
+	// A RabbitMQ without a connection behaves like one that is reconnecting.
+	rabbit := &RabbitMQ{done: make(chan struct{}), ready: make(chan struct{})}
+	p := &Publisher{rabbit: rabbit, config: PublisherConfig{ConfirmTimeout: DefaultConfirmTimeout}, stats: make(map[string]*PublishStats)}
+
+	for i := 0; i < 2; i++ {
+		err := p.Publish("booking_exchange", "booking.created", amqp.Publishing{Body: []byte("{}")})
+		if !errors.Is(err, ErrDisconnected) {
+			t.Fatalf("Publish() error = %v, want ErrDisconnected", err)
+		}
+	}
+
+	stats := p.Stats()
+	want := PublishStats{Published: 2, Failed: 2}
+	if stats["booking_exchange"] != want {
+		t.Errorf("Stats()[booking_exchange] = %+v, want %+v", stats["booking_exchange"], want)
+	}
+	if got := stats["booking_exchange"].String(); got != "published 2, confirmed 0, nacked 0, returned 0, timed out 0, failed 2" {
+		t.Errorf("PublishStats.String() = %q", got)
+	}
+	if _, ok := stats["room_exchange"]; ok {
+		t.Errorf("Stats() lists an exchange nothing was published on")
+	}
+
+	rabbit.Close()
+	// Returns right away once the connection is closed.
+	p.LogStats(time.Hour)
+	if err := p.Publish("booking_exchange", "booking.created", amqp.Publishing{}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Publish() after Close error = %v, want ErrClosed", err)
+	}
+}
+
+func TestPublishWithRetry(t *testing.T) {
+	calls := 0
+	err := PublishWithRetry(3, time.Millisecond, func() error {
+		calls++
+		if calls < 3 {
+			return ErrDisconnected
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("PublishWithRetry() = %v after %d calls, want nil after 3", err, calls)
+	}
+
+	calls = 0
+	err = PublishWithRetry(2, time.Millisecond, func() error {
+		calls++
+		return ErrNacked
+	})
+	if !errors.Is(err, ErrNacked) || calls != 2 {
+		t.Errorf("PublishWithRetry() = %v after %d calls, want ErrNacked after 2", err, calls)
+	}
+
+	calls = 0
+	err = PublishWithRetry(3, time.Millisecond, func() error {
+		calls++
+		return ErrClosed
+	})
+	if !errors.Is(err, ErrClosed) || calls != 1 {
+		t.Errorf("PublishWithRetry() = %v after %d calls, want ErrClosed after 1", err, calls)
+	}
+}
@@ -1,35 +1,155 @@
 package messaging
 
 import (
-	"github.com/streadway/amqp"
+	"errors"
 	"log"
+	"sync"
+	"time"
+
+	"github.com/streadway/amqp"
+)
+
+var (
+	// ErrDisconnected is returned while the broker connection is down and
+	// being re-established.
+	ErrDisconnected = errors.New("messaging: not connected to the broker")
+	// ErrClosed is returned once Close has been called.
+	ErrClosed = errors.New("messaging: connection closed")
+)
+
+// Backoff between reconnection attempts.
+const (
+	minReconnectDelay = 500 * time.Millisecond
+	maxReconnectDelay = 30 * time.Second
 )
 
+// RabbitMQ keeps one broker connection alive for the whole process. When the
+// connection drops it reconnects with exponential backoff; consumers and
+// publishers built on it re-declare their topology and carry on.
 type RabbitMQ struct {
-	Conn    *amqp.Connection
-	Channel *amqp.Channel
+	url string
+
+	mu   sync.Mutex
+	conn *amqp.Connection
+	// ready is closed while connected and replaced by an open channel while
+	// reconnecting, so waiters can block on it.
+	ready chan struct{}
+	done  chan struct{}
+	once  sync.Once
 }
 
+// NewRabbitMQ connects to the broker. Only the first connection attempt is
+// reported as an error; later outages are handled in the background.
 func NewRabbitMQ(url string) (*RabbitMQ, error) {
 	conn, err := amqp.Dial(url)
 	if err != nil {
 		return nil, err
 	}
-	ch, err := conn.Channel()
-	if err != nil {
-		return nil, err
+
+	r := &RabbitMQ{url: url, done: make(chan struct{})}
+	r.setConnection(conn)
+	return r, nil
+}
+
+func (r *RabbitMQ) setConnection(conn *amqp.Connection) {
+	r.mu.Lock()
+	r.conn = conn
+	ready := make(chan struct{})
+	close(ready)
+	r.ready = ready
+	r.mu.Unlock()
+
+	go r.watch(conn)
+}
+
+// watch waits for conn to close and reconnects unless Close was called.
+func (r *RabbitMQ) watch(conn *amqp.Connection) {
+	reason, ok := <-conn.NotifyClose(make(chan *amqp.Error, 1))
+	if !ok || reason == nil {
+		return
+	}
+	log.Printf("RabbitMQ connection lost: %v", reason)
+
+	r.mu.Lock()
+	r.conn = nil
+	r.ready = make(chan struct{})
+	r.mu.Unlock()
+
+	delay := minReconnectDelay
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-time.After(delay):
+		}
+
+		conn, err := amqp.Dial(r.url)
+		if err == nil {
+			log.Printf("RabbitMQ connection re-established")
+			r.setConnection(conn)
+			return
+		}
+		log.Printf("RabbitMQ reconnect failed, retrying in %s: %v", delay, err)
+
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
 	}
-	return &RabbitMQ{
-		Conn:    conn,
-		Channel: ch,
-	}, nil
 }
 
-func (r *RabbitMQ) Close() {
-	if err := r.Channel.Close(); err != nil {
-		log.Println("Failed to close RabbitMQ channel:", err)
+// Channel opens a channel on the current connection. It fails fast with
+// ErrDisconnected while reconnecting.
+func (r *RabbitMQ) Channel() (*amqp.Channel, error) {
+	select {
+	case <-r.done:
+		return nil, ErrClosed
+	default:
+	}
+
+	r.mu.Lock()
+	conn := r.conn
+	r.mu.Unlock()
+	if conn == nil {
+		return nil, ErrDisconnected
 	}
-	if err := r.Conn.Close(); err != nil {
-		log.Println("Failed to close RabbitMQ connection:", err)
+	return conn.Channel()
+}
+
+// WaitChannel is like Channel but blocks until the broker is reachable.
+func (r *RabbitMQ) WaitChannel() (*amqp.Channel, error) {
+	for {
+		r.mu.Lock()
+		ready := r.ready
+		r.mu.Unlock()
+
+		select {
+		case <-r.done:
+			return nil, ErrClosed
+		case <-ready:
+		}
+
+		ch, err := r.Channel()
+		if errors.Is(err, ErrDisconnected) {
+			continue
+		}
+		return ch, err
 	}
 }
+
+// Close closes the connection for good and stops reconnecting.
+func (r *RabbitMQ) Close() {
+	r.once.Do(func() {
+		close(r.done)
+
+		r.mu.Lock()
+		conn := r.conn
+		r.mu.Unlock()
+		if conn == nil {
+			return
+		}
+		if err := conn.Close(); err != nil {
+			log.Println("Failed to close RabbitMQ connection:", err)
+		}
+	})
+}
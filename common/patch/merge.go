@@ -0,0 +1,99 @@
+// Package patch implements JSON Merge Patch (RFC 7386) for the PATCH
+// endpoints of the services.
+package patch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// ContentType is the media type of a JSON Merge Patch document.
+const ContentType = "application/merge-patch+json"
+
+// ErrInvalidPatch is returned for patch documents that cannot be applied.
+var ErrInvalidPatch = errors.New("invalid merge patch")
+
+// ErrUnsupportedMediaType is returned by CheckContentType.
+var ErrUnsupportedMediaType = errors.New("PATCH requests must be sent as " + ContentType)
+
+// CheckContentType accepts requests sent as application/merge-patch+json, and
+// as plain application/json or without a content type for older clients.
+func CheckContentType(r *http.Request) error {
+	value := r.Header.Get("Content-Type")
+	if value == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(value)
+	if err != nil || (mediaType != ContentType && mediaType != "application/json") {
+		return ErrUnsupportedMediaType
+	}
+	return nil
+}
+
+// Merge applies patch to the JSON document doc: members of the patch replace
+// those of doc, objects are merged recursively and null removes a member.
+func Merge(doc, patch []byte) ([]byte, error) {
+	var patchValue interface{}
+	if err := decode(patch, &patchValue); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	var docValue interface{}
+	if err := decode(doc, &docValue); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergeValue(docValue, patchValue))
+}
+
+func mergeValue(doc, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	docObject, ok := doc.(map[string]interface{})
+	if !ok {
+		docObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(docObject, key)
+			continue
+		}
+		docObject[key] = mergeValue(docObject[key], value)
+	}
+	return docObject
+}
+
+// Apply merges patch into the JSON form of *target and decodes the result
+// back into a fresh value, so that members the patch removes end up as zero
+// values. Members that T does not know are rejected.
+func Apply[T any](target *T, patch []byte) error {
+	doc, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+	merged, err := Merge(doc, patch)
+	if err != nil {
+		return err
+	}
+
+	var result T
+	dec := json.NewDecoder(bytes.NewReader(merged))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&result); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+	*target = result
+	return nil
+}
+
+// decode unmarshals data keeping numbers exact, so that large IDs survive
+// the round trip.
+func decode(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
@@ -0,0 +1,92 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	// Examples from RFC 7386, appendix A.
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		got, err := Merge([]byte(tt.doc), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("Merge(%s, %s) error = %v", tt.doc, tt.patch, err)
+			continue
+		}
+		var gotValue, wantValue interface{}
+		_ = json.Unmarshal(got, &gotValue)
+		_ = json.Unmarshal([]byte(tt.want), &wantValue)
+		if !reflect.DeepEqual(gotValue, wantValue) {
+			t.Errorf("Merge(%s, %s) = %s, want %s", tt.doc, tt.patch, got, tt.want)
+		}
+	}
+}
+
+type room struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+}
+
+func TestApply(t *testing.T) {
+	r := room{ID: 9007199254740993, Name: "Blue", Available: true}
+
+	if err := Apply(&r, []byte(`{"available": false}`)); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	want := room{ID: 9007199254740993, Name: "Blue"}
+	if r != want {
+		t.Errorf("Apply() = %+v, want %+v", r, want)
+	}
+
+	if err := Apply(&r, []byte(`{"name": null}`)); err != nil || r.Name != "" {
+		t.Errorf("Apply(null) = %+v, %v; want the name cleared", r, err)
+	}
+
+	for _, body := range []string{`{"colour": "blue"}`, `{"name": 1}`, `{`} {
+		if err := Apply(&r, []byte(body)); !errors.Is(err, ErrInvalidPatch) {
+			t.Errorf("Apply(%s) error = %v, want ErrInvalidPatch", body, err)
+		}
+	}
+}
+
+func TestCheckContentType(t *testing.T) {
+	for value, ok := range map[string]bool{
+		"":                             true,
+		"application/merge-patch+json": true,
+		"application/merge-patch+json; charset=utf-8": true,
+		"application/json":            true,
+		"application/json-patch+json": false,
+		"text/plain":                  false,
+	} {
+		r := httptest.NewRequest("PATCH", "/", nil)
+		if value != "" {
+			r.Header.Set("Content-Type", value)
+		}
+		if err := CheckContentType(r); (err == nil) != ok {
+			t.Errorf("CheckContentType(%q) error = %v", value, err)
+		}
+	}
+}
@@ -3,13 +3,35 @@ package main
 import (
 	"roomManage/internal/app"
 	"roomManage/internal/config"
+	"roomManage/internal/repository"
+	"roomManage/pkg/database"
 	"roomManage/pkg/logger"
 )
 
 func main() {
 	log := logger.NewLogger()
 	cfg := config.LoadConfig()
-	application := app.NewApp(cfg, log)
+	if cfg.Auth.JWTSecret == "" {
+		log.Fatalf("auth.jwt_secret (JWT_SECRET) must be set")
+	}
+
+	db, err := database.NewDB(cfg.Database.DSN())
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	application := app.NewApp(cfg, log, repository.NewPostgresRoomRepository(db), repository.NewPostgresBookingProjection(db))
+	if err := application.ConnectMessaging(); err != nil {
+		log.Fatalf("Failed to connect to RabbitMQ: %v", err)
+	}
+	defer application.Messaging.Close()
+
+	go func() {
+		if err := application.RunMessaging(); err != nil {
+			log.Fatalf("Failed to consume booking events: %v", err)
+		}
+	}()
 
 	go func() {
 		if err := application.RunHTTPServer(); err != nil {
@@ -3,6 +3,7 @@ module roomManage
 go 1.20
 
 require (
+	github.com/dgrijalva/jwt-go v3.2.0+incompatible
 	github.com/gorilla/mux v1.8.1
 	github.com/lib/pq v1.10.9
 	github.com/spf13/viper v1.19.0
@@ -11,6 +12,12 @@ require (
 	google.golang.org/protobuf v1.33.0
 )
 
+require Booking_System v0.0.0
+
+// Booking_System is the repository root, which holds the shared common
+// packages.
+replace Booking_System => ../
+
 require (
 	github.com/fsnotify/fsnotify v1.7.0 // indirect
 	github.com/golang/protobuf v1.5.3 // indirect
@@ -7,11 +7,13 @@ import (
 	"net/http"
 	"os"
 
+	"Booking_System/common/auth"
 	"roomManage/internal/config"
 	"roomManage/internal/repository"
 	"roomManage/internal/service"
 	grpcHandler "roomManage/internal/transport/grpc"
 	httpHandler "roomManage/internal/transport/http"
+	messaging "roomManage/internal/transport/mesagging"
 	"roomManage/pkg/logger"
 	"roomManage/proto"
 
@@ -20,8 +22,11 @@ import (
 )
 
 type App struct {
-	Config *config.Config
-	Logger *logger.Logger
+	Config    *config.Config
+	Logger    *logger.Logger
+	Rooms     repository.RoomRepository
+	Bookings  repository.BookingProjection
+	Messaging *messaging.RoomMessaging
 }
 type Config struct {
 	Port        string
@@ -48,27 +53,34 @@ func getEnv(key, defaultValue string) string {
 	return value
 }
 
-func NewApp(cfg *config.Config, log *logger.Logger) *App {
+// NewApp wires the application around a single room repository and booking
+// projection so that the HTTP server, the gRPC server and the messaging
+// consumer all see the same rooms and booked periods.
+func NewApp(cfg *config.Config, log *logger.Logger, rooms repository.RoomRepository, bookings repository.BookingProjection) *App {
 	return &App{
-		Config: cfg,
-		Logger: log,
+		Config:   cfg,
+		Logger:   log,
+		Rooms:    rooms,
+		Bookings: bookings,
 	}
 }
 
 func (a *App) RunHTTPServer() error {
 	r := mux.NewRouter()
-	roomRepo := repository.NewRoomRepository()
-	roomService := service.NewRoomService(roomRepo)
-	roomHandler := httpHandler.NewRoomHandler(roomService)
+	roomService := a.roomService()
+	availabilityService := service.NewAvailabilityService(a.Rooms, a.Bookings)
+	roomHandler := httpHandler.NewRoomHandler(roomService, availabilityService)
 
 	r.HandleFunc("/rooms", roomHandler.GetRooms).Methods("GET")
+	r.HandleFunc("/rooms/availability", roomHandler.GetAvailability).Methods("GET")
 	r.HandleFunc("/rooms/{room_id}", roomHandler.GetRoomByID).Methods("GET")
 	r.HandleFunc("/rooms", roomHandler.CreateRoom).Methods("POST")
 	r.HandleFunc("/rooms/{room_id}", roomHandler.UpdateRoom).Methods("PUT")
+	r.HandleFunc("/rooms/{room_id}", roomHandler.PatchRoom).Methods("PATCH")
 	r.HandleFunc("/rooms/{room_id}", roomHandler.DeleteRoom).Methods("DELETE")
 
 	serverAddr := fmt.Sprintf(":%d", a.Config.Server.Port)
-	return http.ListenAndServe(serverAddr, r)
+	return http.ListenAndServe(serverAddr, a.verifier().Middleware(r))
 }
 
 func (a *App) RunGRPCServer() error {
@@ -77,11 +89,42 @@ func (a *App) RunGRPCServer() error {
 		return err
 	}
 
-	grpcServer := grpc.NewServer()
-	roomService := service.NewRoomService(repository.NewRoomRepository())
-	roomGRPCServer := grpcHandler.NewRoomGRPCServer(roomService)
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(a.verifier().UnaryServerInterceptor()))
+	roomService := a.roomService()
+	availabilityService := service.NewAvailabilityService(a.Rooms, a.Bookings)
+	roomGRPCServer := grpcHandler.NewRoomGRPCServer(roomService, availabilityService)
 
 	proto.RegisterRoomServiceServer(grpcServer, roomGRPCServer)
 
 	return grpcServer.Serve(lis)
 }
+
+// roomService returns a room service that announces changes through the
+// broker once ConnectMessaging has been called.
+func (a *App) roomService() *service.RoomService {
+	if a.Messaging == nil {
+		return service.NewRoomService(a.Rooms, nil)
+	}
+	return service.NewRoomService(a.Rooms, a.Messaging)
+}
+
+func (a *App) verifier() *auth.Verifier {
+	return auth.NewVerifier(a.Config.Auth.JWTSecret)
+}
+
+// ConnectMessaging connects to the broker. Call it before starting the
+// servers so that room deletions are published.
+func (a *App) ConnectMessaging() error {
+	roomMessaging, err := messaging.NewRoomMessaging(a.Config, service.NewRoomService(a.Rooms, nil), a.Bookings)
+	if err != nil {
+		return err
+	}
+	a.Messaging = roomMessaging
+	return nil
+}
+
+// RunMessaging consumes booking events to keep the availability projection
+// up to date. It blocks until the broker closes the delivery channel.
+func (a *App) RunMessaging() error {
+	return a.Messaging.ConsumeBookingEvents()
+}
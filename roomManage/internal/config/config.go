@@ -1,8 +1,10 @@
 package config
 
 import (
+	"fmt"
 	"github.com/spf13/viper"
 	"log"
+	"strings"
 )
 
 type Config struct {
@@ -10,6 +12,7 @@ type Config struct {
 	GRPC     GRPCConfig
 	Database DatabaseConfig
 	RabbitMQ RabbitMQConfig
+	Auth     AuthConfig
 }
 
 type ServerConfig struct {
@@ -26,14 +29,34 @@ type DatabaseConfig struct {
 	User     string
 	Password string
 	DBName   string
+	SSLMode  string
+}
+
+func (c DatabaseConfig) DSN() string {
+	sslMode := c.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s", c.User, c.Password, c.Host, c.Port, c.DBName, sslMode)
 }
 
 type RabbitMQConfig struct {
 	URL string
 }
 
+// AuthConfig holds the secret clientManage signs access tokens with.
+type AuthConfig struct {
+	JWTSecret string `mapstructure:"jwt_secret"`
+}
+
 func LoadConfig() *Config {
 	viper.SetConfigFile("config.yaml")
+	// Allow every key to be overridden from the environment, e.g.
+	// DATABASE_HOST overrides database.host.
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
+	viper.AutomaticEnv()
+	// The signing secret is shared by all services under the same name.
+	_ = viper.BindEnv("auth.jwt_secret", "JWT_SECRET")
 	err := viper.ReadInConfig()
 	if err != nil {
 		log.Fatalf("Error reading config file, %s", err)
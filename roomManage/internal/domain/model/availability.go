@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// BookedPeriod is the local projection of a booking made in the booking
+// service. Only the fields needed to work out room availability are kept.
+type BookedPeriod struct {
+	BookingID int64     `json:"booking_id"`
+	RoomID    string    `json:"room_id"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Status    string    `json:"status"`
+	// Version is the booking's version in the booking service. Events can
+	// arrive out of order, so a period is only replaced by a newer version.
+	Version int `json:"version"`
+}
+
+type TimeRange struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type RoomAvailability struct {
+	Room           *Room       `json:"room"`
+	FullyAvailable bool        `json:"fully_available"`
+	Gaps           []TimeRange `json:"gaps"`
+}
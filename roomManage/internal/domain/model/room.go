@@ -1,8 +1,92 @@
 package model
 
+import "strings"
+
 type Room struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Available   bool   `json:"available"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Available   bool     `json:"available"`
+	Capacity    int      `json:"capacity"`
+	Type        RoomType `json:"type"`
+	// BaseRate is the nightly rate in minor units of Currency, e.g. cents.
+	BaseRate      int64         `json:"base_rate"`
+	Currency      string        `json:"currency"`
+	Floor         int           `json:"floor"`
+	Amenities     []string      `json:"amenities"`
+	Accessibility Accessibility `json:"accessibility"`
+}
+
+type RoomType string
+
+const (
+	RoomTypeSingle RoomType = "single"
+	RoomTypeDouble RoomType = "double"
+	RoomTypeTwin   RoomType = "twin"
+	RoomTypeSuite  RoomType = "suite"
+	RoomTypeFamily RoomType = "family"
+)
+
+// RoomTypes lists the valid room types. A room may also leave its type empty.
+var RoomTypes = []RoomType{RoomTypeSingle, RoomTypeDouble, RoomTypeTwin, RoomTypeSuite, RoomTypeFamily}
+
+func (t RoomType) Valid() bool {
+	if t == "" {
+		return true
+	}
+	for _, valid := range RoomTypes {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Clone returns a copy of the room that shares no memory with it.
+func (r *Room) Clone() *Room {
+	clone := *r
+	if r.Amenities != nil {
+		clone.Amenities = append([]string(nil), r.Amenities...)
+	}
+	return &clone
+}
+
+// HasAmenity reports whether the room lists the amenity, ignoring case.
+func (r *Room) HasAmenity(amenity string) bool {
+	for _, a := range r.Amenities {
+		if strings.EqualFold(a, amenity) {
+			return true
+		}
+	}
+	return false
+}
+
+// Accessibility flags what a room offers guests with reduced mobility,
+// hearing or sight.
+type Accessibility struct {
+	Wheelchair         bool `json:"wheelchair"`
+	StepFree           bool `json:"step_free"`
+	AccessibleBathroom bool `json:"accessible_bathroom"`
+	HearingLoop        bool `json:"hearing_loop"`
+}
+
+// AccessibilityFlags are the names of the Accessibility fields, as used in
+// JSON and in query parameters.
+var AccessibilityFlags = []string{"wheelchair", "step_free", "accessible_bathroom", "hearing_loop"}
+
+// Has reports whether the flag is set. known is false for a name that is not
+// one of AccessibilityFlags.
+func (a Accessibility) Has(flag string) (has, known bool) {
+	switch flag {
+	case "wheelchair":
+		return a.Wheelchair, true
+	case "step_free":
+		return a.StepFree, true
+	case "accessible_bathroom":
+		return a.AccessibleBathroom, true
+	case "hearing_loop":
+		return a.HearingLoop, true
+	default:
+		return false, false
+	}
 }
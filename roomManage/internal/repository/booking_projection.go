@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"roomManage/internal/domain/model"
+	"sync"
+	"time"
+)
+
+// BookingProjection keeps a copy of the bookings published by the booking
+// service. It is written by the messaging consumer and read by the HTTP and
+// gRPC handlers.
+//
+// Booking events can arrive out of order or more than once, so every write
+// carries the booking's version and is ignored unless it is newer than what
+// is stored. Removed bookings leave a tombstone at their last version, so a
+// late event cannot bring them back.
+type BookingProjection interface {
+	// Upsert stores the period unless a newer or equal version is stored.
+	Upsert(period *model.BookedPeriod) error
+	// Remove marks the booking as no longer holding its room unless a newer
+	// version is stored.
+	Remove(period *model.BookedPeriod) error
+	// Overlapping returns the booked periods of a room that intersect the
+	// half-open range [start, end).
+	Overlapping(roomID string, start, end time.Time) ([]*model.BookedPeriod, error)
+}
+
+// InMemoryBookingProjection keeps the booked periods in a map. Nothing
+// survives a restart, so it is only meant for tests and local experiments.
+// Access is guarded by a mutex.
+type InMemoryBookingProjection struct {
+	mu       sync.RWMutex
+	bookings map[int64]*bookedPeriod
+}
+
+// bookedPeriod is a stored period, or the tombstone of a removed booking.
+type bookedPeriod struct {
+	model.BookedPeriod
+	removed bool
+}
+
+func NewInMemoryBookingProjection() *InMemoryBookingProjection {
+	return &InMemoryBookingProjection{
+		bookings: make(map[int64]*bookedPeriod),
+	}
+}
+
+func (p *InMemoryBookingProjection) Upsert(period *model.BookedPeriod) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if stored, ok := p.bookings[period.BookingID]; ok && stored.Version >= period.Version {
+		return nil
+	}
+	p.bookings[period.BookingID] = &bookedPeriod{BookedPeriod: *period}
+	return nil
+}
+
+func (p *InMemoryBookingProjection) Remove(period *model.BookedPeriod) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if stored, ok := p.bookings[period.BookingID]; ok && stored.Version > period.Version {
+		return nil
+	}
+	p.bookings[period.BookingID] = &bookedPeriod{BookedPeriod: *period, removed: true}
+	return nil
+}
+
+func (p *InMemoryBookingProjection) Overlapping(roomID string, start, end time.Time) ([]*model.BookedPeriod, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var periods []*model.BookedPeriod
+	for _, period := range p.bookings {
+		if period.removed || period.RoomID != roomID {
+			continue
+		}
+		if period.StartDate.Before(end) && period.EndDate.After(start) {
+			stored := period.BookedPeriod
+			periods = append(periods, &stored)
+		}
+	}
+	return periods, nil
+}
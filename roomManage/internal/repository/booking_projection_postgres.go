@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"roomManage/internal/domain/model"
+	"time"
+)
+
+// PostgresBookingProjection keeps the booked periods in the booked_periods
+// table, so availability survives a restart without replaying the booking
+// events.
+type PostgresBookingProjection struct {
+	DB *sql.DB
+}
+
+func NewPostgresBookingProjection(db *sql.DB) *PostgresBookingProjection {
+	return &PostgresBookingProjection{DB: db}
+}
+
+// Upsert inserts the period or, if an older version of the booking is
+// stored, overwrites it.
+func (p *PostgresBookingProjection) Upsert(period *model.BookedPeriod) error {
+	return p.write(period, false, `booked_periods.version < EXCLUDED.version`)
+}
+
+// Remove keeps the booking as a tombstone at period.Version, so that events
+// of older versions arriving later are ignored by Upsert.
+func (p *PostgresBookingProjection) Remove(period *model.BookedPeriod) error {
+	return p.write(period, true, `booked_periods.version <= EXCLUDED.version`)
+}
+
+func (p *PostgresBookingProjection) write(period *model.BookedPeriod, removed bool, newer string) error {
+	query := `
+		INSERT INTO booked_periods (booking_id, room_id, start_date, end_date, status, version, removed)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (booking_id) DO UPDATE
+		SET room_id = EXCLUDED.room_id,
+		    start_date = EXCLUDED.start_date,
+		    end_date = EXCLUDED.end_date,
+		    status = EXCLUDED.status,
+		    version = EXCLUDED.version,
+		    removed = EXCLUDED.removed,
+		    updated_at = NOW()
+		WHERE ` + newer
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := p.DB.ExecContext(ctx, query,
+		period.BookingID, period.RoomID, period.StartDate, period.EndDate, period.Status, period.Version, removed)
+	return err
+}
+
+func (p *PostgresBookingProjection) Overlapping(roomID string, start, end time.Time) ([]*model.BookedPeriod, error) {
+	query := `
+		SELECT booking_id, room_id, start_date, end_date, status, version
+		FROM booked_periods
+		WHERE room_id = $1 AND start_date < $3 AND end_date > $2 AND NOT removed
+		ORDER BY start_date`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := p.DB.QueryContext(ctx, query, roomID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var periods []*model.BookedPeriod
+	for rows.Next() {
+		var period model.BookedPeriod
+		if err := rows.Scan(&period.BookingID, &period.RoomID, &period.StartDate, &period.EndDate, &period.Status, &period.Version); err != nil {
+			return nil, err
+		}
+		periods = append(periods, &period)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return periods, nil
+}
@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"fmt"
+	"math"
+	"roomManage/internal/domain/model"
+	"roomManage/internal/validator"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
+// roomSortFields holds the fields rooms can be sorted on and how to compare
+// them.
+var roomSortFields = map[string]func(a, b *model.Room) int{
+	"id":        func(a, b *model.Room) int { return strings.Compare(a.ID, b.ID) },
+	"name":      func(a, b *model.Room) int { return strings.Compare(a.Name, b.Name) },
+	"type":      func(a, b *model.Room) int { return strings.Compare(string(a.Type), string(b.Type)) },
+	"capacity":  func(a, b *model.Room) int { return compareInts(int64(a.Capacity), int64(b.Capacity)) },
+	"base_rate": func(a, b *model.Room) int { return compareInts(a.BaseRate, b.BaseRate) },
+	"floor":     func(a, b *model.Room) int { return compareInts(int64(a.Floor), int64(b.Floor)) },
+	"available": func(a, b *model.Room) int { return compareBools(a.Available, b.Available) },
+}
+
+// RoomQuery selects, orders and pages rooms. Zero values and nil pointers
+// mean no restriction.
+type RoomQuery struct {
+	// Search matches rooms whose name or description contains it, ignoring
+	// case.
+	Search      string
+	Available   *bool
+	Types       []model.RoomType
+	MinCapacity int
+	Floor       *int
+	Currency    string
+	MinRate     *int64
+	MaxRate     *int64
+	// Amenities and Accessibility must all be offered by a room.
+	Amenities     []string
+	Accessibility []string
+
+	// Sort is a comma separated list of fields, each optionally prefixed
+	// with "-" for descending order, e.g. "-capacity,base_rate". Rooms are
+	// sorted by name when it is empty, and by id last so paging is stable.
+	Sort     string
+	Page     int
+	PageSize int
+}
+
+// Validate checks the query and fills in the paging defaults. Problems are
+// recorded in v, like clientManage's data.ValidateFilters, keyed by the name
+// of the query parameter.
+func (q *RoomQuery) Validate(v *validator.Validator) {
+	if q.Page == 0 {
+		q.Page = 1
+	}
+	if q.PageSize == 0 {
+		q.PageSize = DefaultPageSize
+	}
+
+	for _, t := range q.Types {
+		v.Check(t != "" && t.Valid(), "type", fmt.Sprintf("unknown room type %q", t))
+	}
+	for _, flag := range q.Accessibility {
+		_, known := (model.Accessibility{}).Has(flag)
+		v.Check(known, "accessibility", fmt.Sprintf("unknown accessibility flag %q", flag))
+	}
+	v.Check(q.MinCapacity >= 0, "min_capacity", "must not be negative")
+	if q.MinRate != nil && q.MaxRate != nil {
+		v.Check(*q.MinRate <= *q.MaxRate, "min_rate", "must not be above max_rate")
+	}
+	if _, err := q.sortKeys(); err != nil {
+		v.AddError("sort", err.Error())
+	}
+	v.Check(q.Page > 0, "page", "must be greater than zero")
+	v.Check(q.Page <= 10_000_000, "page", "must be a maximum of 10 million")
+	v.Check(q.PageSize > 0, "page_size", "must be greater than zero")
+	v.Check(q.PageSize <= MaxPageSize, "page_size", fmt.Sprintf("must be a maximum of %d", MaxPageSize))
+}
+
+// Matches reports whether the room passes every filter of the query.
+func (q *RoomQuery) Matches(room *model.Room) bool {
+	if q.Search != "" && !containsFold(room.Name, q.Search) && !containsFold(room.Description, q.Search) {
+		return false
+	}
+	if q.Available != nil && room.Available != *q.Available {
+		return false
+	}
+	if len(q.Types) > 0 && !containsType(q.Types, room.Type) {
+		return false
+	}
+	if room.Capacity < q.MinCapacity {
+		return false
+	}
+	if q.Floor != nil && room.Floor != *q.Floor {
+		return false
+	}
+	if q.Currency != "" && !strings.EqualFold(room.Currency, q.Currency) {
+		return false
+	}
+	if q.MinRate != nil && room.BaseRate < *q.MinRate {
+		return false
+	}
+	if q.MaxRate != nil && room.BaseRate > *q.MaxRate {
+		return false
+	}
+	for _, amenity := range q.Amenities {
+		if !room.HasAmenity(amenity) {
+			return false
+		}
+	}
+	for _, flag := range q.Accessibility {
+		if has, _ := room.Accessibility.Has(flag); !has {
+			return false
+		}
+	}
+	return true
+}
+
+// sortKey is one field of Sort.
+type sortKey struct {
+	field string
+	desc  bool
+}
+
+// sortKeys parses Sort. The keys end with id, so no two rooms compare equal.
+func (q *RoomQuery) sortKeys() ([]sortKey, error) {
+	spec := q.Sort
+	if strings.TrimSpace(spec) == "" {
+		spec = "name"
+	}
+
+	var keys []sortKey
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		field := strings.TrimPrefix(part, "-")
+		if _, ok := roomSortFields[field]; !ok {
+			return nil, fmt.Errorf("unknown sort field %q", field)
+		}
+		if seen[field] {
+			return nil, fmt.Errorf("duplicate sort field %q", field)
+		}
+		seen[field] = true
+		keys = append(keys, sortKey{field: field, desc: strings.HasPrefix(part, "-")})
+	}
+	if !seen["id"] {
+		keys = append(keys, sortKey{field: "id"})
+	}
+	return keys, nil
+}
+
+// compare turns Sort into a less function.
+func (q *RoomQuery) compare() (func(a, b *model.Room) bool, error) {
+	keys, err := q.sortKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(a, b *model.Room) bool {
+		for _, k := range keys {
+			c := roomSortFields[k.field](a, b)
+			if k.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	}, nil
+}
+
+// order sorts rooms in place as Sort asks.
+func (q *RoomQuery) order(rooms []*model.Room) error {
+	less, err := q.compare()
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(rooms, func(i, j int) bool {
+		return less(rooms[i], rooms[j])
+	})
+	return nil
+}
+
+// page sorts the rooms that matched the query and cuts out the page it asks
+// for.
+func (q *RoomQuery) page(rooms []*model.Room) ([]*model.Room, Metadata, error) {
+	if err := q.order(rooms); err != nil {
+		return nil, Metadata{}, err
+	}
+	return paginate(rooms, q.Page, q.PageSize), q.metadata(len(rooms)), nil
+}
+
+// Metadata describes a page of rooms. It is empty when nothing matched.
+type Metadata struct {
+	CurrentPage  int `json:"current_page,omitempty"`
+	PageSize     int `json:"page_size,omitempty"`
+	FirstPage    int `json:"first_page,omitempty"`
+	LastPage     int `json:"last_page,omitempty"`
+	TotalRecords int `json:"total_records"`
+}
+
+// metadata describes the page of the query out of totalRecords matches.
+func (q *RoomQuery) metadata(totalRecords int) Metadata {
+	if totalRecords == 0 {
+		return Metadata{}
+	}
+	return Metadata{
+		CurrentPage:  q.Page,
+		PageSize:     q.PageSize,
+		FirstPage:    1,
+		LastPage:     int(math.Ceil(float64(totalRecords) / float64(q.PageSize))),
+		TotalRecords: totalRecords,
+	}
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func containsType(types []model.RoomType, t model.RoomType) bool {
+	for _, candidate := range types {
+		if candidate == t {
+			return true
+		}
+	}
+	return false
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	default:
+		return 1
+	}
+}
@@ -3,73 +3,112 @@ package repository
 import (
 	"errors"
 	"roomManage/internal/domain/model"
-	"sort"
+	"sync"
 )
 
-type RoomRepository struct {
+var ErrRoomNotFound = errors.New("room not found")
+
+type RoomRepository interface {
+	GetAll() ([]*model.Room, error)
+	GetByID(id string) (*model.Room, error)
+	Save(room *model.Room) error
+	// Delete deletes the room and calls announce, if it is not nil, before
+	// the deletion is final. If announce fails the room is kept. Deleting a
+	// room that does not exist fails with ErrRoomNotFound and announces
+	// nothing.
+	Delete(id string, announce func() error) error
+	Filter(predicate func(*model.Room) bool) ([]*model.Room, error)
+	// List returns the page of rooms the query selects, and where that page
+	// lies among all matches. The query must have been validated.
+	List(query *RoomQuery) ([]*model.Room, Metadata, error)
+}
+
+// InMemoryRoomRepository keeps rooms in a map. Nothing survives a restart, so
+// it is only meant for tests and local experiments. It is safe for concurrent
+// use: rooms are copied on the way in and out, so callers never share memory
+// with what is stored.
+type InMemoryRoomRepository struct {
+	mu    sync.RWMutex
 	rooms map[string]*model.Room
 }
 
-func NewRoomRepository() *RoomRepository {
-	return &RoomRepository{
+func NewInMemoryRoomRepository() *InMemoryRoomRepository {
+	return &InMemoryRoomRepository{
 		rooms: make(map[string]*model.Room),
 	}
 }
 
-func (r *RoomRepository) GetAll() ([]*model.Room, error) {
+func (r *InMemoryRoomRepository) GetAll() ([]*model.Room, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	var rooms []*model.Room
 	for _, room := range r.rooms {
-		rooms = append(rooms, room)
+		rooms = append(rooms, room.Clone())
 	}
 	return rooms, nil
 }
 
-func (r *RoomRepository) GetByID(id string) (*model.Room, error) {
+func (r *InMemoryRoomRepository) GetByID(id string) (*model.Room, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	if room, exists := r.rooms[id]; exists {
-		return room, nil
+		return room.Clone(), nil
 	}
-	return nil, errors.New("room not found")
+	return nil, ErrRoomNotFound
 }
 
-func (r *RoomRepository) Save(room *model.Room) error {
-	r.rooms[room.ID] = room
+func (r *InMemoryRoomRepository) Save(room *model.Room) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rooms[room.ID] = room.Clone()
 	return nil
 }
 
-func (r *RoomRepository) Delete(id string) error {
+func (r *InMemoryRoomRepository) Delete(id string, announce func() error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.rooms[id]; !exists {
+		return ErrRoomNotFound
+	}
+	if announce != nil {
+		if err := announce(); err != nil {
+			return err
+		}
+	}
 	delete(r.rooms, id)
 	return nil
 }
 
-func (r *RoomRepository) Filter(predicate func(*model.Room) bool) ([]*model.Room, error) {
+// Filter returns copies of the rooms predicate accepts. predicate runs with
+// the repository locked for reading, so it must not write to the repository.
+func (r *InMemoryRoomRepository) Filter(predicate func(*model.Room) bool) ([]*model.Room, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	var filteredRooms []*model.Room
 	for _, room := range r.rooms {
-		if predicate(room) {
-			filteredRooms = append(filteredRooms, room)
+		if clone := room.Clone(); predicate(clone) {
+			filteredRooms = append(filteredRooms, clone)
 		}
 	}
 	return filteredRooms, nil
 }
 
-func (r *RoomRepository) Sort(compare func(a, b *model.Room) bool) ([]*model.Room, error) {
-	rooms, err := r.GetAll()
+func (r *InMemoryRoomRepository) List(query *RoomQuery) ([]*model.Room, Metadata, error) {
+	rooms, err := r.Filter(query.Matches)
 	if err != nil {
-		return nil, err
+		return nil, Metadata{}, err
 	}
-	sort.Slice(rooms, func(i, j int) bool {
-		return compare(rooms[i], rooms[j])
-	})
-	return rooms, nil
+	return query.page(rooms)
 }
 
-func (r *RoomRepository) Paginate(rooms []*model.Room, page, pageSize int) ([]*model.Room, error) {
+func paginate(rooms []*model.Room, page, pageSize int) []*model.Room {
 	start := (page - 1) * pageSize
 	if start >= len(rooms) {
-		return nil, nil
+		return nil
 	}
 	end := start + pageSize
 	if end > len(rooms) {
 		end = len(rooms)
 	}
-	return rooms[start:end], nil
+	return rooms[start:end]
 }
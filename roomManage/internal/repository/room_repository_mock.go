@@ -1,7 +1,6 @@
 package repository
 
 import (
-	"errors"
 	"roomManage/internal/domain/model"
 	"sort"
 )
@@ -16,11 +15,13 @@ func NewMockRoomRepository() *MockRoomRepository {
 	}
 }
 
+// GetAll returns the rooms ordered by id, like PostgresRoomRepository.
 func (r *MockRoomRepository) GetAll() ([]*model.Room, error) {
 	var rooms []*model.Room
 	for _, room := range r.rooms {
 		rooms = append(rooms, room)
 	}
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].ID < rooms[j].ID })
 	return rooms, nil
 }
 
@@ -28,7 +29,7 @@ func (r *MockRoomRepository) GetByID(id string) (*model.Room, error) {
 	if room, exists := r.rooms[id]; exists {
 		return room, nil
 	}
-	return nil, errors.New("room not found")
+	return nil, ErrRoomNotFound
 }
 
 func (r *MockRoomRepository) Save(room *model.Room) error {
@@ -36,7 +37,15 @@ func (r *MockRoomRepository) Save(room *model.Room) error {
 	return nil
 }
 
-func (r *MockRoomRepository) Delete(id string) error {
+func (r *MockRoomRepository) Delete(id string, announce func() error) error {
+	if _, exists := r.rooms[id]; !exists {
+		return ErrRoomNotFound
+	}
+	if announce != nil {
+		if err := announce(); err != nil {
+			return err
+		}
+	}
 	delete(r.rooms, id)
 	return nil
 }
@@ -51,25 +60,10 @@ func (r *MockRoomRepository) Filter(predicate func(*model.Room) bool) ([]*model.
 	return filteredRooms, nil
 }
 
-func (r *MockRoomRepository) Sort(compare func(a, b *model.Room) bool) ([]*model.Room, error) {
-	rooms, err := r.GetAll()
+func (r *MockRoomRepository) List(query *RoomQuery) ([]*model.Room, Metadata, error) {
+	rooms, err := r.Filter(query.Matches)
 	if err != nil {
-		return nil, err
-	}
-	sort.Slice(rooms, func(i, j int) bool {
-		return compare(rooms[i], rooms[j])
-	})
-	return rooms, nil
-}
-
-func (r *MockRoomRepository) Paginate(rooms []*model.Room, page, pageSize int) ([]*model.Room, error) {
-	start := (page - 1) * pageSize
-	if start >= len(rooms) {
-		return nil, nil
-	}
-	end := start + pageSize
-	if end > len(rooms) {
-		end = len(rooms)
+		return nil, Metadata{}, err
 	}
-	return rooms[start:end], nil
+	return query.page(rooms)
 }
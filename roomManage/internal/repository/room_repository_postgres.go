@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"roomManage/internal/domain/model"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const roomColumns = `id, name, description, available, capacity, room_type, base_rate, currency,
+		floor, amenities, wheelchair, step_free, accessible_bathroom, hearing_loop`
+
+// roomFields returns the scan destinations for roomColumns.
+func roomFields(room *model.Room) []interface{} {
+	return []interface{}{
+		&room.ID, &room.Name, &room.Description, &room.Available, &room.Capacity, &room.Type,
+		&room.BaseRate, &room.Currency, &room.Floor, (*pq.StringArray)(&room.Amenities),
+		&room.Accessibility.Wheelchair, &room.Accessibility.StepFree,
+		&room.Accessibility.AccessibleBathroom, &room.Accessibility.HearingLoop,
+	}
+}
+
+type PostgresRoomRepository struct {
+	DB *sql.DB
+}
+
+func NewPostgresRoomRepository(db *sql.DB) *PostgresRoomRepository {
+	return &PostgresRoomRepository{DB: db}
+}
+
+func (r *PostgresRoomRepository) GetAll() ([]*model.Room, error) {
+	query := `
+		SELECT ` + roomColumns + `
+		FROM rooms
+		ORDER BY id`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rooms []*model.Room
+	for rows.Next() {
+		var room model.Room
+		if err := rows.Scan(roomFields(&room)...); err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, &room)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return rooms, nil
+}
+
+func (r *PostgresRoomRepository) GetByID(id string) (*model.Room, error) {
+	query := `
+		SELECT ` + roomColumns + `
+		FROM rooms
+		WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var room model.Room
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(roomFields(&room)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRoomNotFound
+		}
+		return nil, err
+	}
+	return &room, nil
+}
+
+// Save inserts the room or, if a room with the same ID already exists,
+// overwrites it.
+func (r *PostgresRoomRepository) Save(room *model.Room) error {
+	query := `
+		INSERT INTO rooms (` + roomColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (id) DO UPDATE
+		SET name = EXCLUDED.name,
+		    description = EXCLUDED.description,
+		    available = EXCLUDED.available,
+		    capacity = EXCLUDED.capacity,
+		    room_type = EXCLUDED.room_type,
+		    base_rate = EXCLUDED.base_rate,
+		    currency = EXCLUDED.currency,
+		    floor = EXCLUDED.floor,
+		    amenities = EXCLUDED.amenities,
+		    wheelchair = EXCLUDED.wheelchair,
+		    step_free = EXCLUDED.step_free,
+		    accessible_bathroom = EXCLUDED.accessible_bathroom,
+		    hearing_loop = EXCLUDED.hearing_loop,
+		    updated_at = NOW()`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := r.DB.ExecContext(ctx, query,
+		room.ID, room.Name, room.Description, room.Available, room.Capacity, room.Type,
+		room.BaseRate, room.Currency, room.Floor, pq.Array(amenities(room)),
+		room.Accessibility.Wheelchair, room.Accessibility.StepFree,
+		room.Accessibility.AccessibleBathroom, room.Accessibility.HearingLoop)
+	return err
+}
+
+// amenities never returns nil, as pq encodes a nil slice as NULL.
+func amenities(room *model.Room) []string {
+	if room.Amenities == nil {
+		return []string{}
+	}
+	return room.Amenities
+}
+
+// Delete deletes the room in a transaction that is only committed once
+// announce has succeeded.
+func (r *PostgresRoomRepository) Delete(id string, announce func() error) error {
+	query := `DELETE FROM rooms WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrRoomNotFound
+	}
+
+	if announce != nil {
+		if err := announce(); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresRoomRepository) Filter(predicate func(*model.Room) bool) ([]*model.Room, error) {
+	rooms, err := r.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	var filteredRooms []*model.Room
+	for _, room := range rooms {
+		if predicate(room) {
+			filteredRooms = append(filteredRooms, room)
+		}
+	}
+	return filteredRooms, nil
+}
+
+// List runs the query in the database, so only the requested page is read.
+// The number of all matches is counted alongside it.
+func (r *PostgresRoomRepository) List(query *RoomQuery) ([]*model.Room, Metadata, error) {
+	where, args := roomConditions(query)
+	orderBy, err := roomOrder(query)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	args = append(args, query.PageSize, (query.Page-1)*query.PageSize)
+	stmt := fmt.Sprintf(`
+		SELECT count(*) OVER(), %s
+		FROM rooms
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d`, roomColumns, where, orderBy, len(args)-1, len(args))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	totalRecords := 0
+	var rooms []*model.Room
+	for rows.Next() {
+		var room model.Room
+		if err := rows.Scan(append([]interface{}{&totalRecords}, roomFields(&room)...)...); err != nil {
+			return nil, Metadata{}, err
+		}
+		rooms = append(rooms, &room)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	// A page past the last one has no rows to carry the count.
+	if len(rooms) == 0 && query.Page > 1 {
+		countStmt := `SELECT count(*) FROM rooms WHERE ` + where
+		if err := r.DB.QueryRowContext(ctx, countStmt, args[:len(args)-2]...).Scan(&totalRecords); err != nil {
+			return nil, Metadata{}, err
+		}
+	}
+	return rooms, query.metadata(totalRecords), nil
+}
+
+// roomSortColumns maps the sort fields of RoomQuery to columns. Text is
+// compared byte by byte, like RoomQuery.compare does, so the order does not
+// depend on the database's collation.
+var roomSortColumns = map[string]string{
+	"id":        `id COLLATE "C"`,
+	"name":      `name COLLATE "C"`,
+	"type":      `room_type COLLATE "C"`,
+	"capacity":  "capacity",
+	"base_rate": "base_rate",
+	"floor":     "floor",
+	"available": "available",
+}
+
+// accessibilityColumns maps the accessibility flags to their columns.
+var accessibilityColumns = map[string]string{
+	"wheelchair":          "wheelchair",
+	"step_free":           "step_free",
+	"accessible_bathroom": "accessible_bathroom",
+	"hearing_loop":        "hearing_loop",
+}
+
+// likeEscaper escapes the wildcards of a LIKE pattern.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// roomConditions translates the filters of RoomQuery.Matches into a WHERE
+// condition and its arguments, numbered from $1.
+func roomConditions(q *RoomQuery) (string, []interface{}) {
+	conditions := []string{"TRUE"}
+	var args []interface{}
+	param := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if q.Search != "" {
+		pattern := param("%" + likeEscaper.Replace(q.Search) + "%")
+		conditions = append(conditions, fmt.Sprintf("(name ILIKE %[1]s OR description ILIKE %[1]s)", pattern))
+	}
+	if q.Available != nil {
+		conditions = append(conditions, "available = "+param(*q.Available))
+	}
+	if len(q.Types) > 0 {
+		types := make([]string, len(q.Types))
+		for i, t := range q.Types {
+			types[i] = string(t)
+		}
+		conditions = append(conditions, "room_type = ANY("+param(pq.Array(types))+"::text[])")
+	}
+	if q.MinCapacity > 0 {
+		conditions = append(conditions, "capacity >= "+param(q.MinCapacity))
+	}
+	if q.Floor != nil {
+		conditions = append(conditions, "floor = "+param(*q.Floor))
+	}
+	if q.Currency != "" {
+		conditions = append(conditions, "upper(currency) = upper("+param(q.Currency)+")")
+	}
+	if q.MinRate != nil {
+		conditions = append(conditions, "base_rate >= "+param(*q.MinRate))
+	}
+	if q.MaxRate != nil {
+		conditions = append(conditions, "base_rate <= "+param(*q.MaxRate))
+	}
+	if len(q.Amenities) > 0 {
+		amenities := make([]string, len(q.Amenities))
+		for i, amenity := range q.Amenities {
+			amenities[i] = strings.ToLower(amenity)
+		}
+		conditions = append(conditions,
+			"ARRAY(SELECT lower(a) FROM unnest(amenities) AS a) @> "+param(pq.Array(amenities))+"::text[]")
+	}
+	for _, flag := range q.Accessibility {
+		column, ok := accessibilityColumns[flag]
+		if !ok {
+			column = "FALSE"
+		}
+		conditions = append(conditions, column)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// roomOrder translates Sort into an ORDER BY list.
+func roomOrder(q *RoomQuery) (string, error) {
+	keys, err := q.sortKeys()
+	if err != nil {
+		return "", err
+	}
+	terms := make([]string, len(keys))
+	for i, k := range keys {
+		terms[i] = roomSortColumns[k.field]
+		if k.desc {
+			terms[i] += " DESC"
+		}
+	}
+	return strings.Join(terms, ", "), nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"roomManage/internal/domain/model"
+	"roomManage/internal/repository"
+	"sort"
+	"time"
+)
+
+var ErrInvalidRange = errors.New("end must be after start")
+
+type AvailabilityService struct {
+	rooms    repository.RoomRepository
+	bookings repository.BookingProjection
+}
+
+func NewAvailabilityService(rooms repository.RoomRepository, bookings repository.BookingProjection) *AvailabilityService {
+	return &AvailabilityService{
+		rooms:    rooms,
+		bookings: bookings,
+	}
+}
+
+// FindAvailableRooms returns every room that has at least one free slot in
+// [start, end), together with the free slots themselves. Rooms that are
+// marked unavailable in the catalogue are never offered.
+func (s *AvailabilityService) FindAvailableRooms(start, end time.Time) ([]*model.RoomAvailability, error) {
+	if !end.After(start) {
+		return nil, ErrInvalidRange
+	}
+
+	rooms, err := s.rooms.Filter(func(room *model.Room) bool {
+		return room.Available
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].Name < rooms[j].Name
+	})
+
+	var result []*model.RoomAvailability
+	for _, room := range rooms {
+		booked, err := s.bookings.Overlapping(room.ID, start, end)
+		if err != nil {
+			return nil, err
+		}
+		gaps := freeGaps(booked, start, end)
+		if len(gaps) == 0 {
+			continue
+		}
+		result = append(result, &model.RoomAvailability{
+			Room:           room,
+			FullyAvailable: len(booked) == 0,
+			Gaps:           gaps,
+		})
+	}
+	return result, nil
+}
+
+// freeGaps walks the booked periods in start order and collects the ranges
+// inside [start, end) that none of them cover.
+func freeGaps(booked []*model.BookedPeriod, start, end time.Time) []model.TimeRange {
+	sort.Slice(booked, func(i, j int) bool {
+		return booked[i].StartDate.Before(booked[j].StartDate)
+	})
+
+	var gaps []model.TimeRange
+	cursor := start
+	for _, period := range booked {
+		if period.StartDate.After(cursor) {
+			gaps = append(gaps, model.TimeRange{Start: cursor, End: period.StartDate})
+		}
+		if period.EndDate.After(cursor) {
+			cursor = period.EndDate
+		}
+	}
+	if cursor.Before(end) {
+		gaps = append(gaps, model.TimeRange{Start: cursor, End: end})
+	}
+	return gaps
+}
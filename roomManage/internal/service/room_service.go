@@ -1,17 +1,79 @@
 package service
 
 import (
+	commonMessaging "Booking_System/common/messaging"
+	"errors"
+	"fmt"
+	"regexp"
 	"roomManage/internal/domain/model"
 	"roomManage/internal/repository"
+	"roomManage/internal/validator"
+	"sort"
+	"strings"
 )
 
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+var (
+	ErrRoomIDMismatch = errors.New("room id in the body does not match the room being updated")
+	ErrReadOnlyField  = errors.New("field cannot be changed by an update")
+	ErrInvalidRoom    = errors.New("invalid room")
+	// ErrEventNotPublished is returned when a change could not be announced
+	// to other services and was therefore not made.
+	ErrEventNotPublished = errors.New("event could not be published")
+	// ErrInvalidQuery is wrapped by the *ValidationError ListRooms returns
+	// for a query it rejects.
+	ErrInvalidQuery = errors.New("invalid room query")
+)
+
+// RoomQuery and Metadata are defined next to the repositories that run the
+// query.
+type (
+	RoomQuery = repository.RoomQuery
+	Metadata  = repository.Metadata
+)
+
+const (
+	DefaultPageSize = repository.DefaultPageSize
+	MaxPageSize     = repository.MaxPageSize
+)
+
+// ValidationError carries the field errors of a rejected room query, keyed
+// by the name of the offending parameter.
+type ValidationError struct {
+	Errors map[string]string
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidQuery
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Errors))
+	for field, message := range e.Errors {
+		fields = append(fields, field+": "+message)
+	}
+	sort.Strings(fields)
+	return "validation failed: " + strings.Join(fields, ", ")
+}
+
+// RoomEvents announces room changes that other services react to.
+type RoomEvents interface {
+	PublishRoomDeleted(roomID string) error
+}
+
 type RoomService struct {
-	repo *repository.RoomRepository
+	repo   repository.RoomRepository
+	events RoomEvents
 }
 
-func NewRoomService(repo *repository.RoomRepository) *RoomService {
+// NewRoomService returns a service that announces changes through events.
+// events may be nil where nobody needs to hear about them, e.g. in the
+// consumer that applies changes coming from elsewhere.
+func NewRoomService(repo repository.RoomRepository, events RoomEvents) *RoomService {
 	return &RoomService{
-		repo: repo,
+		repo:   repo,
+		events: events,
 	}
 }
 
@@ -23,30 +85,108 @@ func (s *RoomService) GetRoomByID(id string) (*model.Room, error) {
 	return s.repo.GetByID(id)
 }
 
+// ListRooms returns the page of rooms the query selects, and where that page
+// lies among all matches.
+func (s *RoomService) ListRooms(query RoomQuery) ([]*model.Room, Metadata, error) {
+	v := validator.New()
+	query.Validate(v)
+	if !v.Valid() {
+		return nil, Metadata{}, &ValidationError{Errors: v.Errors}
+	}
+	rooms, metadata, err := s.repo.List(&query)
+	if err != nil {
+		return nil, Metadata{}, fmt.Errorf("listing rooms: %w", err)
+	}
+	return rooms, metadata, nil
+}
+
 func (s *RoomService) CreateRoom(room *model.Room) error {
+	if err := validateRoom(room); err != nil {
+		return err
+	}
 	return s.repo.Save(room)
 }
 
+// UpdateRoom replaces the room with the given id. The body may leave out the
+// id, but must not name another room.
 func (s *RoomService) UpdateRoom(id string, room *model.Room) error {
+	if room.ID != "" && room.ID != id {
+		return ErrRoomIDMismatch
+	}
+	room.ID = id
+	if err := validateRoom(room); err != nil {
+		return err
+	}
 	return s.repo.Save(room)
 }
 
-func (s *RoomService) DeleteRoom(id string) error {
-	return s.repo.Delete(id)
-}
+// PatchRoom loads the room, lets apply change it and saves the result. The id
+// cannot be changed.
+func (s *RoomService) PatchRoom(id string, apply func(room *model.Room) error) (*model.Room, error) {
+	current, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *RoomService) FilterRooms(predicate func(*model.Room) bool) ([]*model.Room, error) {
-	return s.repo.Filter(predicate)
+	room := *current
+	if err := apply(&room); err != nil {
+		return nil, err
+	}
+	if room.ID != current.ID {
+		return nil, fmt.Errorf("%w: id", ErrReadOnlyField)
+	}
+	if err := validateRoom(&room); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Save(&room); err != nil {
+		return nil, err
+	}
+	return &room, nil
 }
 
-func (s *RoomService) SortRooms(compare func(a, b *model.Room) bool) ([]*model.Room, error) {
-	return s.repo.Sort(compare)
+// validateRoom checks the catalogue fields of a room. Everything but the ID
+// may be left at its zero value.
+func validateRoom(room *model.Room) error {
+	switch {
+	case room.ID == "":
+		return fmt.Errorf("%w: id must be provided", ErrInvalidRoom)
+	case room.Capacity < 0:
+		return fmt.Errorf("%w: capacity must not be negative", ErrInvalidRoom)
+	case !room.Type.Valid():
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidRoom, room.Type)
+	case room.BaseRate < 0:
+		return fmt.Errorf("%w: base_rate must not be negative", ErrInvalidRoom)
+	case room.Currency != "" && !currencyCode.MatchString(room.Currency):
+		return fmt.Errorf("%w: currency must be an ISO 4217 code such as EUR", ErrInvalidRoom)
+	case room.BaseRate > 0 && room.Currency == "":
+		return fmt.Errorf("%w: currency must be provided with base_rate", ErrInvalidRoom)
+	}
+	for _, amenity := range room.Amenities {
+		if strings.TrimSpace(amenity) == "" {
+			return fmt.Errorf("%w: amenities must not be blank", ErrInvalidRoom)
+		}
+	}
+	return nil
 }
 
-func (s *RoomService) PaginateRooms(page, pageSize int) ([]*model.Room, error) {
-	rooms, err := s.repo.GetAll()
-	if err != nil {
-		return nil, err
+// DeleteRoom deletes the room and publishes room.deleted before the deletion
+// is committed, like clientManage's DeleteClient, so the booking service
+// hears about every deleted room and only about deleted rooms. Publishing is
+// retried a few times; if it still fails the room is kept and
+// ErrEventNotPublished is returned, so the caller can try again.
+func (s *RoomService) DeleteRoom(id string) error {
+	var announce func() error
+	if s.events != nil {
+		announce = func() error {
+			err := commonMessaging.PublishWithRetry(0, 0, func() error {
+				return s.events.PublishRoomDeleted(id)
+			})
+			if err != nil {
+				return fmt.Errorf("%w: room.deleted for room %s: %v", ErrEventNotPublished, id, err)
+			}
+			return nil
+		}
 	}
-	return s.repo.Paginate(rooms, page, pageSize)
+	return s.repo.Delete(id, announce)
 }
@@ -1,85 +1,268 @@
 package grpc
 
 import (
+	"Booking_System/common/auth"
 	"context"
+	"errors"
 	"roomManage/internal/domain/model"
+	"roomManage/internal/repository"
 	"roomManage/internal/service"
 	"roomManage/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type RoomGRPCServer struct {
-	service *service.RoomService
+	service      *service.RoomService
+	availability *service.AvailabilityService
 	proto.UnimplementedRoomServiceServer
 }
 
-func NewRoomGRPCServer(service *service.RoomService) *RoomGRPCServer {
+func NewRoomGRPCServer(service *service.RoomService, availability *service.AvailabilityService) *RoomGRPCServer {
 	return &RoomGRPCServer{
-		service: service,
+		service:      service,
+		availability: availability,
+	}
+}
+
+func toProto(room *model.Room) *proto.Room {
+	return &proto.Room{
+		Id:          room.ID,
+		Name:        room.Name,
+		Description: room.Description,
+		Available:   room.Available,
+		Capacity:    int32(room.Capacity),
+		Type:        string(room.Type),
+		BaseRate:    room.BaseRate,
+		Currency:    room.Currency,
+		Floor:       int32(room.Floor),
+		Amenities:   room.Amenities,
+		Accessibility: &proto.Accessibility{
+			Wheelchair:         room.Accessibility.Wheelchair,
+			StepFree:           room.Accessibility.StepFree,
+			AccessibleBathroom: room.Accessibility.AccessibleBathroom,
+			HearingLoop:        room.Accessibility.HearingLoop,
+		},
+	}
+}
+
+func fromProto(room *proto.Room) *model.Room {
+	accessibility := room.GetAccessibility()
+	return &model.Room{
+		ID:          room.Id,
+		Name:        room.Name,
+		Description: room.Description,
+		Available:   room.Available,
+		Capacity:    int(room.Capacity),
+		Type:        model.RoomType(room.Type),
+		BaseRate:    room.BaseRate,
+		Currency:    room.Currency,
+		Floor:       int(room.Floor),
+		Amenities:   room.Amenities,
+		Accessibility: model.Accessibility{
+			Wheelchair:         accessibility.GetWheelchair(),
+			StepFree:           accessibility.GetStepFree(),
+			AccessibleBathroom: accessibility.GetAccessibleBathroom(),
+			HearingLoop:        accessibility.GetHearingLoop(),
+		},
+	}
+}
+
+// updateError maps the errors of creating or updating a room to gRPC status
+// errors.
+func updateError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrRoomNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidRoom), errors.Is(err, service.ErrReadOnlyField):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return err
 	}
 }
 
+// GetRooms lists one page of rooms; see GetRoomsRequest for the filters.
 func (s *RoomGRPCServer) GetRooms(ctx context.Context, req *proto.GetRoomsRequest) (*proto.GetRoomsResponse, error) {
-	rooms, err := s.service.GetAllRooms()
+	query := service.RoomQuery{
+		Search:        req.Search,
+		Available:     req.Available,
+		MinCapacity:   int(req.MinCapacity),
+		Currency:      req.Currency,
+		MinRate:       req.MinRate,
+		MaxRate:       req.MaxRate,
+		Amenities:     req.Amenities,
+		Accessibility: req.Accessibility,
+		Sort:          req.Sort,
+		Page:          int(req.Page),
+		PageSize:      int(req.PageSize),
+	}
+	if req.Floor != nil {
+		floor := int(*req.Floor)
+		query.Floor = &floor
+	}
+	for _, t := range req.Types {
+		query.Types = append(query.Types, model.RoomType(t))
+	}
+
+	rooms, metadata, err := s.service.ListRooms(query)
+	if errors.Is(err, service.ErrInvalidQuery) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
+
 	var protoRooms []*proto.Room
 	for _, room := range rooms {
-		protoRooms = append(protoRooms, &proto.Room{
-			Id:          room.ID,
-			Name:        room.Name,
-			Description: room.Description,
-			Available:   room.Available,
-		})
+		protoRooms = append(protoRooms, toProto(room))
 	}
-	return &proto.GetRoomsResponse{Rooms: protoRooms}, nil
+	return &proto.GetRoomsResponse{
+		Rooms: protoRooms,
+		Metadata: &proto.Metadata{
+			CurrentPage:  int32(metadata.CurrentPage),
+			PageSize:     int32(metadata.PageSize),
+			FirstPage:    int32(metadata.FirstPage),
+			LastPage:     int32(metadata.LastPage),
+			TotalRecords: int32(metadata.TotalRecords),
+		},
+	}, nil
 }
 
 func (s *RoomGRPCServer) GetRoomByID(ctx context.Context, req *proto.GetRoomByIDRequest) (*proto.GetRoomResponse, error) {
 	room, err := s.service.GetRoomByID(req.Id)
+	if errors.Is(err, repository.ErrRoomNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
-	return &proto.GetRoomResponse{Room: &proto.Room{
-		Id:          room.ID,
-		Name:        room.Name,
-		Description: room.Description,
-		Available:   room.Available,
-	}}, nil
+	return &proto.GetRoomResponse{Room: toProto(room)}, nil
 }
 
 func (s *RoomGRPCServer) CreateRoom(ctx context.Context, req *proto.CreateRoomRequest) (*proto.RoomResponse, error) {
-	room := &model.Room{
-		ID:          req.Room.Id,
-		Name:        req.Room.Name,
-		Description: req.Room.Description,
-		Available:   req.Room.Available,
+	if _, err := auth.Require(ctx, auth.RoleAdmin, auth.RoleOperator); err != nil {
+		return nil, auth.StatusError(err)
+	}
+	if req.Room == nil {
+		return nil, status.Error(codes.InvalidArgument, "room is required")
 	}
+	room := fromProto(req.Room)
 	err := s.service.CreateRoom(room)
 	if err != nil {
-		return nil, err
+		return nil, updateError(err)
 	}
 	return &proto.RoomResponse{Room: req.Room}, nil
 }
 
 func (s *RoomGRPCServer) UpdateRoom(ctx context.Context, req *proto.UpdateRoomRequest) (*proto.RoomResponse, error) {
-	room := &model.Room{
-		ID:          req.Room.Id,
-		Name:        req.Room.Name,
-		Description: req.Room.Description,
-		Available:   req.Room.Available,
+	if _, err := auth.Require(ctx, auth.RoleAdmin, auth.RoleOperator); err != nil {
+		return nil, auth.StatusError(err)
+	}
+	if req.Room == nil || req.Room.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "room.id is required")
+	}
+	if len(req.GetUpdateMask().GetPaths()) > 0 {
+		return s.patchRoom(req)
 	}
+	room := fromProto(req.Room)
 	err := s.service.UpdateRoom(req.Room.Id, room)
 	if err != nil {
-		return nil, err
+		return nil, updateError(err)
 	}
 	return &proto.RoomResponse{Room: req.Room}, nil
 }
 
+// patchRoom changes only the fields named in the request's update mask.
+func (s *RoomGRPCServer) patchRoom(req *proto.UpdateRoomRequest) (*proto.RoomResponse, error) {
+	paths := req.UpdateMask.Paths
+	for _, path := range paths {
+		switch path {
+		case "name", "description", "available", "capacity", "type", "base_rate",
+			"currency", "floor", "amenities", "accessibility":
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "update_mask: %q cannot be updated", path)
+		}
+	}
+
+	update := fromProto(req.Room)
+	room, err := s.service.PatchRoom(req.Room.Id, func(room *model.Room) error {
+		for _, path := range paths {
+			switch path {
+			case "name":
+				room.Name = update.Name
+			case "description":
+				room.Description = update.Description
+			case "available":
+				room.Available = update.Available
+			case "capacity":
+				room.Capacity = update.Capacity
+			case "type":
+				room.Type = update.Type
+			case "base_rate":
+				room.BaseRate = update.BaseRate
+			case "currency":
+				room.Currency = update.Currency
+			case "floor":
+				room.Floor = update.Floor
+			case "amenities":
+				room.Amenities = update.Amenities
+			case "accessibility":
+				room.Accessibility = update.Accessibility
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, updateError(err)
+	}
+	return &proto.RoomResponse{Room: toProto(room)}, nil
+}
+
 func (s *RoomGRPCServer) DeleteRoom(ctx context.Context, req *proto.DeleteRoomRequest) (*proto.DeleteRoomResponse, error) {
+	if _, err := auth.Require(ctx, auth.RoleAdmin, auth.RoleOperator); err != nil {
+		return nil, auth.StatusError(err)
+	}
 	err := s.service.DeleteRoom(req.Id)
-	if err != nil {
+	switch {
+	case errors.Is(err, repository.ErrRoomNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrEventNotPublished):
+		return nil, status.Error(codes.Unavailable, err.Error())
+	case err != nil:
 		return nil, err
 	}
 	return &proto.DeleteRoomResponse{Success: true}, nil
 }
+
+func (s *RoomGRPCServer) GetAvailability(ctx context.Context, req *proto.GetAvailabilityRequest) (*proto.GetAvailabilityResponse, error) {
+	if req.Start == nil || req.End == nil {
+		return nil, status.Error(codes.InvalidArgument, "start and end must be provided")
+	}
+
+	availability, err := s.availability.FindAvailableRooms(req.Start.AsTime(), req.End.AsTime())
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRange) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, err
+	}
+
+	var protoRooms []*proto.RoomAvailability
+	for _, item := range availability {
+		var gaps []*proto.TimeRange
+		for _, gap := range item.Gaps {
+			gaps = append(gaps, &proto.TimeRange{
+				Start: timestamppb.New(gap.Start),
+				End:   timestamppb.New(gap.End),
+			})
+		}
+		protoRooms = append(protoRooms, &proto.RoomAvailability{
+			Room:           toProto(item.Room),
+			FullyAvailable: item.FullyAvailable,
+			Gaps:           gaps,
+		})
+	}
+	return &proto.GetAvailabilityResponse{Rooms: protoRooms}, nil
+}
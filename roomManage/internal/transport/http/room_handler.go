@@ -1,53 +1,140 @@
 package http
 
 import (
+	"Booking_System/common/auth"
+	"Booking_System/common/patch"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/gorilla/mux"
+	"io"
 	"net/http"
+	"net/url"
 	"roomManage/internal/domain/model"
+	"roomManage/internal/repository"
 	"roomManage/internal/service"
+	"roomManage/internal/validator"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// RoomHandler serves the room API. Reads are public; creating, changing and
+// deleting rooms is reserved for admins and operators.
 type RoomHandler struct {
-	service *service.RoomService
+	service      *service.RoomService
+	availability *service.AvailabilityService
 }
 
-func NewRoomHandler(service *service.RoomService) *RoomHandler {
+func NewRoomHandler(service *service.RoomService, availability *service.AvailabilityService) *RoomHandler {
 	return &RoomHandler{
-		service: service,
+		service:      service,
+		availability: availability,
 	}
 }
 
+// GetRooms lists one page of rooms. The query string can search names and
+// descriptions (search) and filter on available, type (a comma separated
+// list), min_capacity (or capacity_min), floor, currency, min_rate and
+// max_rate, and on the amenities and accessibility flags a room must all
+// offer, e.g. ?amenities=wifi,minibar&accessibility=wheelchair. sort takes
+// a list such as "-capacity,base_rate"; page and page_size select the page.
+// The body holds the rooms and, like clientManage's lists, a metadata object
+// telling where the page lies among all matches.
 func (h *RoomHandler) GetRooms(w http.ResponseWriter, r *http.Request) {
-	// Пример фильтрации: только доступные комнаты
-	predicate := func(room *model.Room) bool {
-		return room.Available
+	v := validator.New()
+	query := parseRoomQuery(r.URL.Query(), v)
+	if !v.Valid() {
+		failedValidation(w, v.Errors)
+		return
 	}
-	rooms, err := h.service.FilterRooms(predicate)
+
+	rooms, metadata, err := h.service.ListRooms(query)
 	if err != nil {
+		var validationErr *service.ValidationError
+		if errors.As(err, &validationErr) {
+			failedValidation(w, validationErr.Errors)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if rooms == nil {
+		rooms = []*model.Room{}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"rooms": rooms, "metadata": metadata})
+}
 
-	// Пример сортировки: по имени комнаты
-	compare := func(a, b *model.Room) bool {
-		return a.Name < b.Name
+// parseRoomQuery reads the GetRooms query string. Values that do not parse
+// are reported on v.
+func parseRoomQuery(qs url.Values, v *validator.Validator) service.RoomQuery {
+	capacityKey := "min_capacity"
+	if qs.Get(capacityKey) == "" && qs.Get("capacity_min") != "" {
+		capacityKey = "capacity_min"
 	}
-	rooms, err = h.service.SortRooms(compare)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	query := service.RoomQuery{
+		Search:        qs.Get("search"),
+		Available:     readOptional(qs, "available", strconv.ParseBool, "must be true or false", v),
+		MinCapacity:   readInt(qs, capacityKey, 0, v),
+		Floor:         readOptional(qs, "floor", strconv.Atoi, "must be an integer value", v),
+		Currency:      qs.Get("currency"),
+		MinRate:       readOptional(qs, "min_rate", parseInt64, "must be an integer value", v),
+		MaxRate:       readOptional(qs, "max_rate", parseInt64, "must be an integer value", v),
+		Amenities:     readCSV(qs, "amenities"),
+		Accessibility: readCSV(qs, "accessibility"),
+		Sort:          qs.Get("sort"),
+		Page:          readInt(qs, "page", 1, v),
+		PageSize:      readInt(qs, "page_size", service.DefaultPageSize, v),
+	}
+	for _, t := range readCSV(qs, "type") {
+		query.Types = append(query.Types, model.RoomType(t))
 	}
+	return query
+}
 
-	// Пример пагинации: 1 страница, 10 комнат на странице
-	page, pageSize := 1, 10
-	rooms, err = h.service.PaginateRooms(page, pageSize)
+func readInt(qs url.Values, key string, defaultValue int, v *validator.Validator) int {
+	value := readOptional(qs, key, strconv.Atoi, "must be an integer value", v)
+	if value == nil {
+		return defaultValue
+	}
+	return *value
+}
+
+// readOptional parses the query parameter key, or returns nil if it is
+// absent or does not parse.
+func readOptional[T any](qs url.Values, key string, parse func(string) (T, error), message string, v *validator.Validator) *T {
+	s := qs.Get(key)
+	if s == "" {
+		return nil
+	}
+	value, err := parse(s)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		v.AddError(key, message)
+		return nil
+	}
+	return &value
+}
+
+func readCSV(qs url.Values, key string) []string {
+	var items []string
+	for _, item := range strings.Split(qs.Get(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
+}
 
-	json.NewEncoder(w).Encode(rooms)
+func parseInt64(s string) (int64, error) {
+	return strconv.ParseInt(s, 10, 64)
+}
+
+// failedValidation answers 422 with the field errors as JSON.
+func failedValidation(w http.ResponseWriter, errors map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]map[string]string{"error": errors})
 }
 
 func (h *RoomHandler) GetRoomByID(w http.ResponseWriter, r *http.Request) {
@@ -62,19 +149,27 @@ func (h *RoomHandler) GetRoomByID(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *RoomHandler) CreateRoom(w http.ResponseWriter, r *http.Request) {
+	if _, err := auth.Require(r.Context(), auth.RoleAdmin, auth.RoleOperator); err != nil {
+		http.Error(w, err.Error(), auth.HTTPStatus(err))
+		return
+	}
 	var room model.Room
 	if err := json.NewDecoder(r.Body).Decode(&room); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	if err := h.service.CreateRoom(&room); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), updateErrorStatus(err))
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
 }
 
 func (h *RoomHandler) UpdateRoom(w http.ResponseWriter, r *http.Request) {
+	if _, err := auth.Require(r.Context(), auth.RoleAdmin, auth.RoleOperator); err != nil {
+		http.Error(w, err.Error(), auth.HTTPStatus(err))
+		return
+	}
 	vars := mux.Vars(r)
 	id := vars["room_id"]
 	var room model.Room
@@ -83,18 +178,118 @@ func (h *RoomHandler) UpdateRoom(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if err := h.service.UpdateRoom(id, &room); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), updateErrorStatus(err))
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
 
+// PatchRoom applies a JSON Merge Patch to a room; members that are left out
+// keep their value.
+func (h *RoomHandler) PatchRoom(w http.ResponseWriter, r *http.Request) {
+	if _, err := auth.Require(r.Context(), auth.RoleAdmin, auth.RoleOperator); err != nil {
+		http.Error(w, err.Error(), auth.HTTPStatus(err))
+		return
+	}
+	if err := patch.CheckContentType(r); err != nil {
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+	vars := mux.Vars(r)
+	id := vars["room_id"]
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	room, err := h.service.PatchRoom(id, func(room *model.Room) error {
+		return patch.Apply(room, body)
+	})
+	if err != nil {
+		http.Error(w, err.Error(), updateErrorStatus(err))
+		return
+	}
+	json.NewEncoder(w).Encode(room)
+}
+
+// updateErrorStatus maps the errors of creating or updating a room to HTTP
+// status codes.
+func updateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrRoomNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrRoomIDMismatch), errors.Is(err, patch.ErrInvalidPatch):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrReadOnlyField), errors.Is(err, service.ErrInvalidRoom):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// deleteErrorStatus maps the errors of deleting a room to HTTP status codes.
+func deleteErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrRoomNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrEventNotPublished):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 func (h *RoomHandler) DeleteRoom(w http.ResponseWriter, r *http.Request) {
+	if _, err := auth.Require(r.Context(), auth.RoleAdmin, auth.RoleOperator); err != nil {
+		http.Error(w, err.Error(), auth.HTTPStatus(err))
+		return
+	}
 	vars := mux.Vars(r)
 	id := vars["room_id"]
 	if err := h.service.DeleteRoom(id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), deleteErrorStatus(err))
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 }
+
+func (h *RoomHandler) GetAvailability(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	start, err := parseTime(query.Get("start"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid start: %v", err), http.StatusBadRequest)
+		return
+	}
+	end, err := parseTime(query.Get("end"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid end: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	availability, err := h.availability.FindAvailableRooms(start, end)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRange) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if availability == nil {
+		availability = []*model.RoomAvailability{}
+	}
+
+	json.NewEncoder(w).Encode(availability)
+}
+
+// parseTime accepts either a full RFC 3339 timestamp or a plain date, which
+// is interpreted as midnight UTC.
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, errors.New("must be provided")
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
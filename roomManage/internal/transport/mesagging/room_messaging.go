@@ -1,65 +1,102 @@
 package messaging
 
 import (
-	"encoding/json"
-	"log"
+	"errors"
 	"roomManage/internal/config"
 	"roomManage/internal/domain/model"
+	"roomManage/internal/repository"
 	"roomManage/internal/service"
+	"strconv"
+	"time"
 
-	"github.com/streadway/amqp"
+	commonMessaging "Booking_System/common/messaging"
 )
 
+const (
+	roomQueue = "room_queue"
+
+	roomExchange       = "room_exchange"
+	roomDeletedRouting = "room.deleted"
+
+	bookingExchange      = "booking_exchange"
+	bookingEventsQueue   = "room_booking_events"
+	bookingEventsRouting = "booking.#"
+	bookingDeletedEvent  = "booking.deleted"
+)
+
+// eventProducer names roomManage in the events it publishes.
+const eventProducer = "roomManage"
+
+// Event types and schema versions of what roomManage publishes, and the
+// newest booking event version it understands.
+const (
+	roomCreateEvent = "room.create"
+
+	RoomEventVersion    = 1
+	bookingEventVersion = 1
+)
+
+// RoomMessaging publishes room events and consumes what other services
+// publish. Its connection reconnects on its own after a broker outage, and
+// the consumers resume with it.
 type RoomMessaging struct {
-	service *service.RoomService
-	conn    *amqp.Connection
-	channel *amqp.Channel
+	service   *service.RoomService
+	bookings  repository.BookingProjection
+	rabbit    *commonMessaging.RabbitMQ
+	publisher *commonMessaging.Publisher
+}
+
+// bookingEvent mirrors the booking payload of the booking service's events.
+type bookingEvent struct {
+	ID        int64     `json:"id"`
+	RoomID    int64     `json:"room_id"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Status    string    `json:"status"`
+	Version   int       `json:"version"`
 }
 
-func NewRoomMessaging(cfg *config.Config, service *service.RoomService) (*RoomMessaging, error) {
-	conn, err := amqp.Dial(cfg.RabbitMQ.URL)
+func NewRoomMessaging(cfg *config.Config, service *service.RoomService, bookings repository.BookingProjection) (*RoomMessaging, error) {
+	rabbit, err := commonMessaging.NewRabbitMQ(cfg.RabbitMQ.URL)
 	if err != nil {
 		return nil, err
 	}
-	ch, err := conn.Channel()
+	publisher, err := commonMessaging.NewPublisher(rabbit, commonMessaging.PublisherConfig{
+		Exchanges: []string{roomExchange},
+		Mandatory: true,
+	})
 	if err != nil {
+		rabbit.Close()
 		return nil, err
 	}
 	return &RoomMessaging{
-		service: service,
-		conn:    conn,
-		channel: ch,
+		service:   service,
+		bookings:  bookings,
+		rabbit:    rabbit,
+		publisher: publisher,
 	}, nil
 }
 
+// ConsumeMessages applies the rooms published on room_queue by
+// PublishRoomMessage.
 func (m *RoomMessaging) ConsumeMessages() error {
-	msgs, err := m.channel.Consume(
-		"room_queue", // queue
-		"",           // consumer
-		true,         // auto-ack
-		false,        // exclusive
-		false,        // no-local
-		false,        // no-wait
-		nil,          // args
-	)
-	if err != nil {
-		return err
-	}
-
-	for msg := range msgs {
-		var room model.Room
-		if err := json.Unmarshal(msg.Body, &room); err != nil {
-			log.Printf("Error unmarshalling message: %v", err)
-			continue
+	consumer := commonMessaging.NewConsumer(m.rabbit, commonMessaging.ConsumerConfig{
+		Queue: roomQueue,
+	}, commonMessaging.Deduplicate(commonMessaging.NewMemoryDeduplicator(0), func(d commonMessaging.Delivery) error {
+		event, err := commonMessaging.Decode[model.Room](d.Body)
+		if err == nil {
+			err = event.CheckVersion(RoomEventVersion)
 		}
-
-		// Process the room message
-		if err := m.processRoomMessage(&room); err != nil {
-			log.Printf("Error processing room message: %v", err)
+		if err != nil {
+			return commonMessaging.Permanent(err)
 		}
-	}
-
-	return nil
+		err = m.processRoomMessage(&event.Payload)
+		if errors.Is(err, service.ErrInvalidRoom) {
+			return commonMessaging.Permanent(err)
+		}
+		return err
+	}))
+	return consumer.Run()
 }
 
 func (m *RoomMessaging) processRoomMessage(room *model.Room) error {
@@ -73,25 +110,79 @@ func (m *RoomMessaging) processRoomMessage(room *model.Room) error {
 	return nil
 }
 
+// ConsumeBookingEvents keeps the local booking projection in sync with the
+// events the booking service publishes on booking_exchange.
+func (m *RoomMessaging) ConsumeBookingEvents() error {
+	consumer := commonMessaging.NewConsumer(m.rabbit, commonMessaging.ConsumerConfig{
+		Queue: bookingEventsQueue,
+		Bindings: []commonMessaging.Binding{
+			{Exchange: bookingExchange, RoutingKey: bookingEventsRouting},
+		},
+	}, commonMessaging.Deduplicate(commonMessaging.NewMemoryDeduplicator(0), func(d commonMessaging.Delivery) error {
+		event, err := commonMessaging.Decode[bookingEvent](d.Body)
+		if err == nil {
+			err = event.CheckVersion(bookingEventVersion)
+		}
+		if err != nil {
+			return commonMessaging.Permanent(err)
+		}
+		return m.processBookingEvent(event.Type, &event.Payload)
+	}))
+	return consumer.Run()
+}
+
+func (m *RoomMessaging) processBookingEvent(eventType string, event *bookingEvent) error {
+	period := &model.BookedPeriod{
+		BookingID: event.ID,
+		RoomID:    strconv.FormatInt(event.RoomID, 10),
+		StartDate: event.StartDate,
+		EndDate:   event.EndDate,
+		Status:    event.Status,
+		Version:   event.Version,
+	}
+	// Deleted and cancelled bookings, no-shows and expired holds no longer
+	// hold the room. booking.updated may have moved the booking to another
+	// room or other dates, which the upsert takes care of. The projection
+	// ignores events older than what it has.
+	if eventType == bookingDeletedEvent ||
+		event.Status == "cancelled" || event.Status == "no_show" || event.Status == "hold_expired" {
+		return m.bookings.Remove(period)
+	}
+	return m.bookings.Upsert(period)
+}
+
 func (m *RoomMessaging) PublishRoomMessage(room *model.Room) error {
-	body, err := json.Marshal(room)
+	msg, err := commonMessaging.NewEvent(roomCreateEvent, RoomEventVersion, eventProducer, room).Publishing()
+	if err != nil {
+		return err
+	}
+
+	return m.publisher.Publish(
+		"",        // exchange
+		roomQueue, // routing key
+		msg)
+}
+
+// RoomDeleted is the payload of room.deleted.
+type RoomDeleted struct {
+	ID string `json:"id"`
+}
+
+// PublishRoomDeleted announces a deleted room on room_exchange so that the
+// booking service can cancel what is still booked in it.
+func (m *RoomMessaging) PublishRoomDeleted(roomID string) error {
+	msg, err := commonMessaging.NewEvent(roomDeletedRouting, RoomEventVersion, eventProducer, RoomDeleted{ID: roomID}).Publishing()
 	if err != nil {
 		return err
 	}
 
-	err = m.channel.Publish(
-		"",           // exchange
-		"room_queue", // routing key
-		false,        // mandatory
-		false,        // immediate
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		})
-	return err
+	return m.publisher.Publish(
+		roomExchange,       // exchange
+		roomDeletedRouting, // routing key
+		msg)
 }
 
 func (m *RoomMessaging) Close() {
-	m.channel.Close()
-	m.conn.Close()
+	m.publisher.Close()
+	m.rabbit.Close()
 }
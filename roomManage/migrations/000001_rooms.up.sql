@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS rooms (
+                                     id text PRIMARY KEY,
+                                     name varchar(255) NOT NULL,
+                                     description text NOT NULL DEFAULT '',
+                                     available bool NOT NULL DEFAULT true,
+                                     created_at timestamp(0) with time zone NOT NULL DEFAULT NOW(),
+                                     updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
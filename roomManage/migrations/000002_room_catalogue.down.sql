@@ -0,0 +1,11 @@
+ALTER TABLE rooms
+    DROP COLUMN IF EXISTS capacity,
+    DROP COLUMN IF EXISTS room_type,
+    DROP COLUMN IF EXISTS base_rate,
+    DROP COLUMN IF EXISTS currency,
+    DROP COLUMN IF EXISTS floor,
+    DROP COLUMN IF EXISTS amenities,
+    DROP COLUMN IF EXISTS wheelchair,
+    DROP COLUMN IF EXISTS step_free,
+    DROP COLUMN IF EXISTS accessible_bathroom,
+    DROP COLUMN IF EXISTS hearing_loop;
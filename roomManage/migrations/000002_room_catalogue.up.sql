@@ -0,0 +1,11 @@
+ALTER TABLE rooms
+    ADD COLUMN IF NOT EXISTS capacity integer NOT NULL DEFAULT 0 CHECK (capacity >= 0),
+    ADD COLUMN IF NOT EXISTS room_type text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS base_rate bigint NOT NULL DEFAULT 0 CHECK (base_rate >= 0),
+    ADD COLUMN IF NOT EXISTS currency text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS floor integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS amenities text[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS wheelchair bool NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS step_free bool NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS accessible_bathroom bool NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS hearing_loop bool NOT NULL DEFAULT false;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS booked_periods (
+    booking_id bigint PRIMARY KEY,
+    room_id text NOT NULL,
+    start_date timestamp(0) with time zone NOT NULL,
+    end_date timestamp(0) with time zone NOT NULL,
+    status text NOT NULL DEFAULT '',
+    updated_at timestamp(0) with time zone NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS booked_periods_room_dates_idx ON booked_periods (room_id, start_date, end_date);
@@ -0,0 +1,5 @@
+DELETE FROM booked_periods WHERE removed;
+
+ALTER TABLE booked_periods
+    DROP COLUMN IF EXISTS removed,
+    DROP COLUMN IF EXISTS version;
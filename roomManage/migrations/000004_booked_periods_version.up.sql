@@ -0,0 +1,3 @@
+ALTER TABLE booked_periods
+    ADD COLUMN IF NOT EXISTS version integer NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS removed boolean NOT NULL DEFAULT false;
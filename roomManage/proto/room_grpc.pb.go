@@ -19,11 +19,12 @@ import (
 const _ = grpc.SupportPackageIsVersion8
 
 const (
-	RoomService_GetRooms_FullMethodName    = "/proto.RoomService/GetRooms"
-	RoomService_GetRoomByID_FullMethodName = "/proto.RoomService/GetRoomByID"
-	RoomService_CreateRoom_FullMethodName  = "/proto.RoomService/CreateRoom"
-	RoomService_UpdateRoom_FullMethodName  = "/proto.RoomService/UpdateRoom"
-	RoomService_DeleteRoom_FullMethodName  = "/proto.RoomService/DeleteRoom"
+	RoomService_GetRooms_FullMethodName        = "/proto.RoomService/GetRooms"
+	RoomService_GetRoomByID_FullMethodName     = "/proto.RoomService/GetRoomByID"
+	RoomService_CreateRoom_FullMethodName      = "/proto.RoomService/CreateRoom"
+	RoomService_UpdateRoom_FullMethodName      = "/proto.RoomService/UpdateRoom"
+	RoomService_DeleteRoom_FullMethodName      = "/proto.RoomService/DeleteRoom"
+	RoomService_GetAvailability_FullMethodName = "/proto.RoomService/GetAvailability"
 )
 
 // RoomServiceClient is the client API for RoomService service.
@@ -35,6 +36,7 @@ type RoomServiceClient interface {
 	CreateRoom(ctx context.Context, in *CreateRoomRequest, opts ...grpc.CallOption) (*RoomResponse, error)
 	UpdateRoom(ctx context.Context, in *UpdateRoomRequest, opts ...grpc.CallOption) (*RoomResponse, error)
 	DeleteRoom(ctx context.Context, in *DeleteRoomRequest, opts ...grpc.CallOption) (*DeleteRoomResponse, error)
+	GetAvailability(ctx context.Context, in *GetAvailabilityRequest, opts ...grpc.CallOption) (*GetAvailabilityResponse, error)
 }
 
 type roomServiceClient struct {
@@ -95,6 +97,16 @@ func (c *roomServiceClient) DeleteRoom(ctx context.Context, in *DeleteRoomReques
 	return out, nil
 }
 
+func (c *roomServiceClient) GetAvailability(ctx context.Context, in *GetAvailabilityRequest, opts ...grpc.CallOption) (*GetAvailabilityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAvailabilityResponse)
+	err := c.cc.Invoke(ctx, RoomService_GetAvailability_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RoomServiceServer is the server API for RoomService service.
 // All implementations must embed UnimplementedRoomServiceServer
 // for forward compatibility
@@ -104,6 +116,7 @@ type RoomServiceServer interface {
 	CreateRoom(context.Context, *CreateRoomRequest) (*RoomResponse, error)
 	UpdateRoom(context.Context, *UpdateRoomRequest) (*RoomResponse, error)
 	DeleteRoom(context.Context, *DeleteRoomRequest) (*DeleteRoomResponse, error)
+	GetAvailability(context.Context, *GetAvailabilityRequest) (*GetAvailabilityResponse, error)
 	mustEmbedUnimplementedRoomServiceServer()
 }
 
@@ -126,6 +139,9 @@ func (UnimplementedRoomServiceServer) UpdateRoom(context.Context, *UpdateRoomReq
 func (UnimplementedRoomServiceServer) DeleteRoom(context.Context, *DeleteRoomRequest) (*DeleteRoomResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteRoom not implemented")
 }
+func (UnimplementedRoomServiceServer) GetAvailability(context.Context, *GetAvailabilityRequest) (*GetAvailabilityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAvailability not implemented")
+}
 func (UnimplementedRoomServiceServer) mustEmbedUnimplementedRoomServiceServer() {}
 
 // UnsafeRoomServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -229,6 +245,24 @@ func _RoomService_DeleteRoom_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RoomService_GetAvailability_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAvailabilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RoomServiceServer).GetAvailability(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RoomService_GetAvailability_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RoomServiceServer).GetAvailability(ctx, req.(*GetAvailabilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // RoomService_ServiceDesc is the grpc.ServiceDesc for RoomService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -256,6 +290,10 @@ var RoomService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteRoom",
 			Handler:    _RoomService_DeleteRoom_Handler,
 		},
+		{
+			MethodName: "GetAvailability",
+			Handler:    _RoomService_GetAvailability_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "booking_system/roomManage/proto/room.proto",
@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
 
+	"Booking_System/common/auth"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/gorilla/mux"
 	"roomManage/internal/domain/model"
 	"roomManage/internal/repository"
@@ -14,23 +18,43 @@ import (
 	httpHandler "roomManage/internal/transport/http"
 )
 
-func setupRouter() *mux.Router {
-	roomRepo := repository.NewRoomRepository()
-	roomService := service.NewRoomService(roomRepo)
-	roomHandler := httpHandler.NewRoomHandler(roomService)
+const testJWTSecret = "integration-test-secret"
+
+// authorize signs req with an access token for the given role, the way
+// clientManage would.
+func authorize(t *testing.T, req *http.Request, role string) {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss":  auth.Issuer,
+		"sub":  "1",
+		"role": role,
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+}
+
+func setupRouter() (http.Handler, *repository.InMemoryRoomRepository) {
+	roomRepo := repository.NewInMemoryRoomRepository()
+	roomService := service.NewRoomService(roomRepo, nil)
+	availabilityService := service.NewAvailabilityService(roomRepo, repository.NewInMemoryBookingProjection())
+	roomHandler := httpHandler.NewRoomHandler(roomService, availabilityService)
 
 	r := mux.NewRouter()
 	r.HandleFunc("/rooms", roomHandler.GetRooms).Methods("GET")
+	r.HandleFunc("/rooms/availability", roomHandler.GetAvailability).Methods("GET")
 	r.HandleFunc("/rooms/{room_id}", roomHandler.GetRoomByID).Methods("GET")
 	r.HandleFunc("/rooms", roomHandler.CreateRoom).Methods("POST")
 	r.HandleFunc("/rooms/{room_id}", roomHandler.UpdateRoom).Methods("PUT")
+	r.HandleFunc("/rooms/{room_id}", roomHandler.PatchRoom).Methods("PATCH")
 	r.HandleFunc("/rooms/{room_id}", roomHandler.DeleteRoom).Methods("DELETE")
 
-	return r
+	return auth.NewVerifier(testJWTSecret).Middleware(r), roomRepo
 }
 
 func TestCreateRoom(t *testing.T) {
-	router := setupRouter()
+	router, _ := setupRouter()
 
 	room := model.Room{
 		ID:          "1",
@@ -41,6 +65,7 @@ func TestCreateRoom(t *testing.T) {
 	body, _ := json.Marshal(room)
 
 	req, _ := http.NewRequest("POST", "/rooms", bytes.NewBuffer(body))
+	authorize(t, req, auth.RoleAdmin)
 	req.Header.Set("Content-Type", "application/json")
 	response := httptest.NewRecorder()
 	router.ServeHTTP(response, req)
@@ -50,8 +75,29 @@ func TestCreateRoom(t *testing.T) {
 	}
 }
 
+func TestCreateRoomRequiresStaff(t *testing.T) {
+	router, _ := setupRouter()
+
+	body, _ := json.Marshal(model.Room{ID: "1", Name: "Room 1"})
+
+	req, _ := http.NewRequest("POST", "/rooms", bytes.NewBuffer(body))
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, req)
+	if response.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status code %d without a token, but got %d", http.StatusUnauthorized, response.Code)
+	}
+
+	req, _ = http.NewRequest("POST", "/rooms", bytes.NewBuffer(body))
+	authorize(t, req, auth.RoleClient)
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, req)
+	if response.Code != http.StatusForbidden {
+		t.Errorf("Expected status code %d for a client, but got %d", http.StatusForbidden, response.Code)
+	}
+}
+
 func TestGetRoom(t *testing.T) {
-	router := setupRouter()
+	router, roomRepo := setupRouter()
 
 	room := model.Room{
 		ID:          "1",
@@ -59,7 +105,6 @@ func TestGetRoom(t *testing.T) {
 		Description: "Description 1",
 		Available:   true,
 	}
-	roomRepo := repository.NewRoomRepository()
 	roomRepo.Save(&room)
 
 	req, _ := http.NewRequest("GET", "/rooms/1", nil)
@@ -78,7 +123,7 @@ func TestGetRoom(t *testing.T) {
 }
 
 func TestUpdateRoom(t *testing.T) {
-	router := setupRouter()
+	router, roomRepo := setupRouter()
 
 	room := model.Room{
 		ID:          "1",
@@ -86,7 +131,6 @@ func TestUpdateRoom(t *testing.T) {
 		Description: "Description 1",
 		Available:   true,
 	}
-	roomRepo := repository.NewRoomRepository()
 	roomRepo.Save(&room)
 
 	updatedRoom := model.Room{
@@ -98,6 +142,7 @@ func TestUpdateRoom(t *testing.T) {
 	body, _ := json.Marshal(updatedRoom)
 
 	req, _ := http.NewRequest("PUT", "/rooms/1", bytes.NewBuffer(body))
+	authorize(t, req, auth.RoleAdmin)
 	req.Header.Set("Content-Type", "application/json")
 	response := httptest.NewRecorder()
 	router.ServeHTTP(response, req)
@@ -118,7 +163,7 @@ func TestUpdateRoom(t *testing.T) {
 }
 
 func TestDeleteRoom(t *testing.T) {
-	router := setupRouter()
+	router, roomRepo := setupRouter()
 
 	room := model.Room{
 		ID:          "1",
@@ -126,10 +171,10 @@ func TestDeleteRoom(t *testing.T) {
 		Description: "Description 1",
 		Available:   true,
 	}
-	roomRepo := repository.NewRoomRepository()
 	roomRepo.Save(&room)
 
 	req, _ := http.NewRequest("DELETE", "/rooms/1", nil)
+	authorize(t, req, auth.RoleAdmin)
 	response := httptest.NewRecorder()
 	router.ServeHTTP(response, req)
 
@@ -146,14 +191,19 @@ func TestDeleteRoom(t *testing.T) {
 	}
 }
 
+// roomList is the body of GET /rooms.
+type roomList struct {
+	Rooms    []model.Room     `json:"rooms"`
+	Metadata service.Metadata `json:"metadata"`
+}
+
 func TestGetRooms(t *testing.T) {
-	router := setupRouter()
+	router, roomRepo := setupRouter()
 
 	rooms := []model.Room{
 		{ID: "1", Name: "Room 1", Description: "Description 1", Available: true},
 		{ID: "2", Name: "Room 2", Description: "Description 2", Available: false},
 	}
-	roomRepo := repository.NewRoomRepository()
 	for _, room := range rooms {
 		roomRepo.Save(&room)
 	}
@@ -166,9 +216,196 @@ func TestGetRooms(t *testing.T) {
 		t.Errorf("Expected status code %d, but got %d", http.StatusOK, response.Code)
 	}
 
-	var returnedRooms []model.Room
-	json.NewDecoder(response.Body).Decode(&returnedRooms)
-	if len(returnedRooms) != len(rooms) {
-		t.Errorf("Expected %d rooms, but got %d", len(rooms), len(returnedRooms))
+	var list roomList
+	json.NewDecoder(response.Body).Decode(&list)
+	if len(list.Rooms) != len(rooms) {
+		t.Errorf("Expected %d rooms, but got %d", len(rooms), len(list.Rooms))
+	}
+	want := service.Metadata{CurrentPage: 1, PageSize: service.DefaultPageSize, FirstPage: 1, LastPage: 1, TotalRecords: 2}
+	if list.Metadata != want {
+		t.Errorf("Expected metadata %+v, but got %+v", want, list.Metadata)
+	}
+}
+
+func TestGetRoomsFilterAndSort(t *testing.T) {
+	router, roomRepo := setupRouter()
+
+	rooms := []model.Room{
+		{ID: "1", Name: "Blue", Available: true, Capacity: 2, Type: model.RoomTypeDouble, Floor: 1, Amenities: []string{"wifi"}},
+		{ID: "2", Name: "Green", Available: true, Capacity: 4, Type: model.RoomTypeFamily, Floor: 1, Amenities: []string{"wifi", "crib"},
+			Accessibility: model.Accessibility{StepFree: true}},
+		{ID: "3", Name: "Red", Available: true, Capacity: 3, Type: model.RoomTypeSuite, Floor: 2, Amenities: []string{"wifi"}},
+	}
+	for i := range rooms {
+		roomRepo.Save(&rooms[i])
+	}
+
+	req, _ := http.NewRequest("GET", "/rooms?available=true&amenities=wifi&min_capacity=2&sort=-capacity", nil)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, req)
+
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, but got %d: %s", http.StatusOK, response.Code, response.Body)
+	}
+
+	var list roomList
+	json.NewDecoder(response.Body).Decode(&list)
+	var ids []string
+	for _, room := range list.Rooms {
+		ids = append(ids, room.ID)
+	}
+	if want := []string{"2", "3", "1"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Expected rooms %v, but got %v", want, ids)
+	}
+
+	// capacity_min is accepted as well, and sort takes several keys.
+	req, _ = http.NewRequest("GET", "/rooms?capacity_min=3&sort=floor,-capacity", nil)
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, req)
+
+	list = roomList{}
+	json.NewDecoder(response.Body).Decode(&list)
+	ids = nil
+	for _, room := range list.Rooms {
+		ids = append(ids, room.ID)
+	}
+	if want := []string{"2", "3"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Expected rooms %v, but got %v", want, ids)
+	}
+
+	req, _ = http.NewRequest("GET", "/rooms?floor=1&accessibility=step_free&search=GREEN", nil)
+	response = httptest.NewRecorder()
+	router.ServeHTTP(response, req)
+
+	list = roomList{}
+	json.NewDecoder(response.Body).Decode(&list)
+	if len(list.Rooms) != 1 || !reflect.DeepEqual(list.Rooms[0], rooms[1]) {
+		t.Errorf("Expected only room %+v, but got %+v", rooms[1], list.Rooms)
+	}
+}
+
+func TestGetRoomsInvalidQuery(t *testing.T) {
+	router, _ := setupRouter()
+
+	queries := map[string]string{
+		"available=maybe": "available",
+		"page=first":      "page",
+		"sort=price":      "sort",
+		"type=penthouse":  "type",
+		"page_size=1000":  "page_size",
+		"page=-1":         "page",
+		"min_capacity=-1": "min_capacity",
+	}
+	for query, field := range queries {
+		req, _ := http.NewRequest("GET", "/rooms?"+query, nil)
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, req)
+
+		if response.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: Expected status code %d, but got %d", query, http.StatusUnprocessableEntity, response.Code)
+			continue
+		}
+		var body struct {
+			Error map[string]string `json:"error"`
+		}
+		json.NewDecoder(response.Body).Decode(&body)
+		if _, ok := body.Error[field]; !ok {
+			t.Errorf("%s: Expected an error for %s, but got %v", query, field, body.Error)
+		}
+	}
+}
+
+func TestCreateRoomRejectsInvalidCatalogue(t *testing.T) {
+	router, _ := setupRouter()
+
+	body, _ := json.Marshal(model.Room{ID: "1", Name: "Room 1", BaseRate: 9900, Currency: "euros"})
+
+	req, _ := http.NewRequest("POST", "/rooms", bytes.NewBuffer(body))
+	authorize(t, req, auth.RoleAdmin)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, req)
+
+	if response.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status code %d, but got %d", http.StatusUnprocessableEntity, response.Code)
+	}
+}
+
+func TestGetAvailabilityInvalidRange(t *testing.T) {
+	router, _ := setupRouter()
+
+	req, _ := http.NewRequest("GET", "/rooms/availability?start=2024-06-10&end=2024-06-01", nil)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, req)
+
+	if response.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, but got %d", http.StatusBadRequest, response.Code)
+	}
+}
+
+func TestGetAvailabilityMissingStart(t *testing.T) {
+	router, _ := setupRouter()
+
+	req, _ := http.NewRequest("GET", "/rooms/availability?end=2024-06-01", nil)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, req)
+
+	if response.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, but got %d", http.StatusBadRequest, response.Code)
+	}
+}
+
+func TestPatchRoom(t *testing.T) {
+	router, roomRepo := setupRouter()
+
+	room := model.Room{
+		ID:          "1",
+		Name:        "Room 1",
+		Description: "Description 1",
+		Available:   true,
+	}
+	roomRepo.Save(&room)
+
+	req, _ := http.NewRequest("PATCH", "/rooms/1", bytes.NewBufferString(`{"available": false}`))
+	authorize(t, req, auth.RoleAdmin)
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, req)
+
+	if response.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, but got %d: %s", http.StatusOK, response.Code, response.Body)
+	}
+
+	var returnedRoom model.Room
+	json.NewDecoder(response.Body).Decode(&returnedRoom)
+	expected := model.Room{ID: "1", Name: "Room 1", Description: "Description 1", Available: false}
+	if !reflect.DeepEqual(returnedRoom, expected) {
+		t.Errorf("Expected room %+v, but got %+v", expected, returnedRoom)
+	}
+}
+
+func TestPatchRoomRejectsUnknownField(t *testing.T) {
+	router, roomRepo := setupRouter()
+	roomRepo.Save(&model.Room{ID: "1", Name: "Room 1"})
+
+	req, _ := http.NewRequest("PATCH", "/rooms/1", bytes.NewBufferString(`{"colour": "blue"}`))
+	authorize(t, req, auth.RoleAdmin)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, req)
+
+	if response.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, but got %d", http.StatusBadRequest, response.Code)
+	}
+}
+
+func TestPatchMissingRoom(t *testing.T) {
+	router, _ := setupRouter()
+
+	req, _ := http.NewRequest("PATCH", "/rooms/42", bytes.NewBufferString(`{"name": "Blue"}`))
+	authorize(t, req, auth.RoleAdmin)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, req)
+
+	if response.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, but got %d", http.StatusNotFound, response.Code)
 	}
 }
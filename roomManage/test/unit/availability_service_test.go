@@ -0,0 +1,167 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"roomManage/internal/domain/model"
+	"roomManage/internal/repository"
+	"roomManage/internal/service"
+)
+
+func setupAvailability() (*repository.InMemoryRoomRepository, *repository.InMemoryBookingProjection, *service.AvailabilityService) {
+	roomRepo := repository.NewInMemoryRoomRepository()
+	bookings := repository.NewInMemoryBookingProjection()
+	return roomRepo, bookings, service.NewAvailabilityService(roomRepo, bookings)
+}
+
+func day(d int) time.Time {
+	return time.Date(2024, time.June, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestFindAvailableRooms_NoBookings(t *testing.T) {
+	roomRepo, _, svc := setupAvailability()
+	roomRepo.Save(&model.Room{ID: "1", Name: "Room 1", Available: true})
+	roomRepo.Save(&model.Room{ID: "2", Name: "Room 2", Available: false})
+
+	availability, err := svc.FindAvailableRooms(day(1), day(5))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(availability) != 1 {
+		t.Fatalf("Expected 1 room, got %d", len(availability))
+	}
+	if !availability[0].FullyAvailable {
+		t.Fatalf("Expected room to be fully available")
+	}
+	if len(availability[0].Gaps) != 1 || !availability[0].Gaps[0].Start.Equal(day(1)) || !availability[0].Gaps[0].End.Equal(day(5)) {
+		t.Fatalf("Expected a single gap covering the whole range, got %v", availability[0].Gaps)
+	}
+}
+
+func TestFindAvailableRooms_Gaps(t *testing.T) {
+	roomRepo, bookings, svc := setupAvailability()
+	roomRepo.Save(&model.Room{ID: "1", Name: "Room 1", Available: true})
+	bookings.Upsert(&model.BookedPeriod{BookingID: 1, RoomID: "1", StartDate: day(2), EndDate: day(3)})
+	bookings.Upsert(&model.BookedPeriod{BookingID: 2, RoomID: "1", StartDate: day(4), EndDate: day(6)})
+
+	availability, err := svc.FindAvailableRooms(day(1), day(8))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(availability) != 1 {
+		t.Fatalf("Expected 1 room, got %d", len(availability))
+	}
+	if availability[0].FullyAvailable {
+		t.Fatalf("Expected room to be partially available")
+	}
+
+	expected := []model.TimeRange{
+		{Start: day(1), End: day(2)},
+		{Start: day(3), End: day(4)},
+		{Start: day(6), End: day(8)},
+	}
+	gaps := availability[0].Gaps
+	if len(gaps) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, gaps)
+	}
+	for i := range expected {
+		if !gaps[i].Start.Equal(expected[i].Start) || !gaps[i].End.Equal(expected[i].End) {
+			t.Fatalf("Expected %v, got %v", expected, gaps)
+		}
+	}
+}
+
+func TestFindAvailableRooms_FullyBooked(t *testing.T) {
+	roomRepo, bookings, svc := setupAvailability()
+	roomRepo.Save(&model.Room{ID: "1", Name: "Room 1", Available: true})
+	bookings.Upsert(&model.BookedPeriod{BookingID: 1, RoomID: "1", StartDate: day(1), EndDate: day(10)})
+
+	availability, err := svc.FindAvailableRooms(day(2), day(5))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(availability) != 0 {
+		t.Fatalf("Expected no rooms, got %d", len(availability))
+	}
+}
+
+func TestFindAvailableRooms_InvalidRange(t *testing.T) {
+	_, _, svc := setupAvailability()
+
+	_, err := svc.FindAvailableRooms(day(5), day(1))
+	if err != service.ErrInvalidRange {
+		t.Fatalf("Expected %v, got %v", service.ErrInvalidRange, err)
+	}
+}
+
+// Booking events can arrive out of order through the retry queues, or twice.
+// Whatever the order, a booking that was cancelled must not hold its room.
+func TestBookingProjectionIgnoresStaleEvents(t *testing.T) {
+	created := &model.BookedPeriod{BookingID: 1, RoomID: "1", StartDate: day(2), EndDate: day(4), Status: "pending", Version: 1}
+	moved := &model.BookedPeriod{BookingID: 1, RoomID: "1", StartDate: day(5), EndDate: day(7), Status: "pending", Version: 2}
+	cancelled := &model.BookedPeriod{BookingID: 1, RoomID: "1", StartDate: day(5), EndDate: day(7), Status: "cancelled", Version: 3}
+
+	tests := []struct {
+		name   string
+		events []func(p *repository.InMemoryBookingProjection) error
+		booked []model.TimeRange
+	}{
+		{"in order", []func(p *repository.InMemoryBookingProjection) error{
+			upsert(created), upsert(moved),
+		}, []model.TimeRange{{Start: day(5), End: day(7)}}},
+		{"update before create", []func(p *repository.InMemoryBookingProjection) error{
+			upsert(moved), upsert(created),
+		}, []model.TimeRange{{Start: day(5), End: day(7)}}},
+		{"create after cancel", []func(p *repository.InMemoryBookingProjection) error{
+			remove(cancelled), upsert(created), upsert(moved),
+		}, nil},
+		{"redelivered cancel", []func(p *repository.InMemoryBookingProjection) error{
+			upsert(created), remove(cancelled), upsert(moved), remove(cancelled),
+		}, nil},
+	}
+
+	for _, tt := range tests {
+		roomRepo, bookings, svc := setupAvailability()
+		roomRepo.Save(&model.Room{ID: "1", Name: "Room 1", Available: true})
+		for _, event := range tt.events {
+			if err := event(bookings); err != nil {
+				t.Fatalf("%s: Expected no error, got %v", tt.name, err)
+			}
+		}
+
+		periods, err := bookings.Overlapping("1", day(1), day(10))
+		if err != nil {
+			t.Fatalf("%s: Expected no error, got %v", tt.name, err)
+		}
+		var booked []model.TimeRange
+		for _, period := range periods {
+			booked = append(booked, model.TimeRange{Start: period.StartDate, End: period.EndDate})
+		}
+		if len(booked) != len(tt.booked) {
+			t.Errorf("%s: Expected booked %v, got %v", tt.name, tt.booked, booked)
+			continue
+		}
+		for i := range booked {
+			if !booked[i].Start.Equal(tt.booked[i].Start) || !booked[i].End.Equal(tt.booked[i].End) {
+				t.Errorf("%s: Expected booked %v, got %v", tt.name, tt.booked, booked)
+			}
+		}
+
+		availability, err := svc.FindAvailableRooms(day(1), day(10))
+		if err != nil {
+			t.Fatalf("%s: Expected no error, got %v", tt.name, err)
+		}
+		if want := tt.booked == nil; len(availability) != 1 || availability[0].FullyAvailable != want {
+			t.Errorf("%s: Expected fully available = %v, got %+v", tt.name, want, availability)
+		}
+	}
+}
+
+func upsert(period *model.BookedPeriod) func(p *repository.InMemoryBookingProjection) error {
+	return func(p *repository.InMemoryBookingProjection) error { return p.Upsert(period) }
+}
+
+func remove(period *model.BookedPeriod) func(p *repository.InMemoryBookingProjection) error {
+	return func(p *repository.InMemoryBookingProjection) error { return p.Remove(period) }
+}
@@ -0,0 +1,76 @@
+package unit
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"roomManage/internal/domain/model"
+	"roomManage/internal/repository"
+)
+
+// Run with -race: the repository is shared by the HTTP and gRPC handlers and
+// the messaging consumer.
+func TestInMemoryRoomRepositoryConcurrentAccess(t *testing.T) {
+	repo := repository.NewInMemoryRoomRepository()
+
+	const workers, iterations = 8, 200
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				id := strconv.Itoa(i % 20)
+				switch (w + i) % 4 {
+				case 0:
+					repo.Save(&model.Room{ID: id, Name: "Room " + id, Available: i%2 == 0, Amenities: []string{"wifi"}})
+				case 1:
+					repo.Delete(id, nil)
+				case 2:
+					rooms, _ := repo.Filter(func(room *model.Room) bool { return room.Available })
+					for _, room := range rooms {
+						room.Amenities[0] = "changed"
+					}
+				case 3:
+					if room, err := repo.GetByID(id); err == nil {
+						room.Name = "changed"
+					}
+					repo.GetAll()
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	rooms, err := repo.GetAll()
+	if err != nil {
+		t.Fatalf("GetAll() error = %v", err)
+	}
+	for _, room := range rooms {
+		if room.Name == "changed" || room.Amenities[0] == "changed" {
+			t.Errorf("stored room %s was changed through a returned copy: %+v", room.ID, room)
+		}
+	}
+}
+
+func TestInMemoryRoomRepositoryReturnsCopies(t *testing.T) {
+	repo := repository.NewInMemoryRoomRepository()
+
+	room := &model.Room{ID: "1", Name: "Blue", Amenities: []string{"wifi"}}
+	repo.Save(room)
+	room.Name = "Red"
+	room.Amenities[0] = "minibar"
+
+	stored, _ := repo.GetByID("1")
+	if stored.Name != "Blue" || stored.Amenities[0] != "wifi" {
+		t.Fatalf("changing the saved room changed the stored one: %+v", stored)
+	}
+
+	stored.Name = "Green"
+	stored.Amenities[0] = "balcony"
+	again, _ := repo.GetByID("1")
+	if again.Name != "Blue" || again.Amenities[0] != "wifi" {
+		t.Errorf("changing a returned room changed the stored one: %+v", again)
+	}
+}
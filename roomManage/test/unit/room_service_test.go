@@ -1,6 +1,7 @@
 package unit
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
@@ -11,7 +12,7 @@ import (
 
 func setup() *service.RoomService {
 	roomRepo := repository.NewMockRoomRepository()
-	return service.NewRoomService((*repository.RoomRepository)(roomRepo))
+	return service.NewRoomService(roomRepo, nil)
 }
 
 func TestCreateRoom(t *testing.T) {
@@ -91,6 +92,56 @@ func TestUpdateRoom(t *testing.T) {
 	}
 }
 
+func TestUpdateRoomUsesPathID(t *testing.T) {
+	svc := setup()
+
+	err := svc.UpdateRoom("2", &model.Room{Name: "Room 2"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := svc.GetRoomByID("2"); err != nil {
+		t.Errorf("Expected room 2 to be saved, got %v", err)
+	}
+
+	err = svc.UpdateRoom("2", &model.Room{ID: "3", Name: "Room 3"})
+	if !errors.Is(err, service.ErrRoomIDMismatch) {
+		t.Errorf("Expected ErrRoomIDMismatch, got %v", err)
+	}
+	if _, err := svc.GetRoomByID("3"); err == nil {
+		t.Errorf("Expected room 3 not to be saved")
+	}
+}
+
+func TestPatchRoom(t *testing.T) {
+	svc := setup()
+	svc.CreateRoom(&model.Room{ID: "1", Name: "Room 1", Description: "Description 1", Available: true})
+
+	room, err := svc.PatchRoom("1", func(room *model.Room) error {
+		room.Available = false
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := &model.Room{ID: "1", Name: "Room 1", Description: "Description 1", Available: false}
+	if !reflect.DeepEqual(room, expected) {
+		t.Errorf("Expected %v, got %v", expected, room)
+	}
+
+	_, err = svc.PatchRoom("1", func(room *model.Room) error {
+		room.ID = "2"
+		return nil
+	})
+	if !errors.Is(err, service.ErrReadOnlyField) {
+		t.Errorf("Expected ErrReadOnlyField, got %v", err)
+	}
+
+	_, err = svc.PatchRoom("42", func(room *model.Room) error { return nil })
+	if !errors.Is(err, repository.ErrRoomNotFound) {
+		t.Errorf("Expected ErrRoomNotFound, got %v", err)
+	}
+}
+
 func TestDeleteRoom(t *testing.T) {
 	svc := setup()
 
@@ -114,161 +165,212 @@ func TestDeleteRoom(t *testing.T) {
 	}
 }
 
-func TestGetAllRooms(t *testing.T) {
-	svc := setup()
+type recordingEvents struct {
+	deleted []string
+}
 
-	rooms := []*model.Room{
-		{ID: "1", Name: "Room 1", Description: "Description 1", Available: true},
-		{ID: "2", Name: "Room 2", Description: "Description 2", Available: false},
-	}
+func (e *recordingEvents) PublishRoomDeleted(roomID string) error {
+	e.deleted = append(e.deleted, roomID)
+	return nil
+}
 
-	for _, room := range rooms {
-		svc.CreateRoom(room)
-	}
+func TestDeleteRoomPublishesEvent(t *testing.T) {
+	events := &recordingEvents{}
+	svc := service.NewRoomService(repository.NewMockRoomRepository(), events)
 
-	storedRooms, err := svc.GetAllRooms()
-	if err != nil {
+	svc.CreateRoom(&model.Room{ID: "1", Name: "Room 1", Available: true})
+
+	if err := svc.DeleteRoom("1"); err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
-	if !reflect.DeepEqual(storedRooms, rooms) {
-		t.Fatalf("Expected %v, got %v", rooms, storedRooms)
-	}
-}
-
-func TestFilterRooms(t *testing.T) {
-	svc := setup()
-
-	rooms := []*model.Room{
-		{ID: "1", Name: "Room 1", Description: "Description 1", Available: true},
-		{ID: "2", Name: "Room 2", Description: "Description 2", Available: false},
+	if !reflect.DeepEqual(events.deleted, []string{"1"}) {
+		t.Errorf("Expected room.deleted for room 1, got %v", events.deleted)
 	}
 
-	for _, room := range rooms {
-		svc.CreateRoom(room)
+	// Nothing is announced for a room that is already gone.
+	if err := svc.DeleteRoom("1"); !errors.Is(err, repository.ErrRoomNotFound) {
+		t.Errorf("Expected ErrRoomNotFound, got %v", err)
 	}
-
-	predicate := func(room *model.Room) bool {
-		return room.Available
+	if len(events.deleted) != 1 {
+		t.Errorf("Expected no event for a missing room, got %v", events.deleted)
 	}
+}
 
-	filteredRooms, err := svc.FilterRooms(predicate)
-	if err != nil {
-		t.Fatalf("Expected no error, got %v", err)
-	}
+type failingEvents struct {
+	calls int
+}
 
-	expectedRooms := []*model.Room{rooms[0]}
-	if !reflect.DeepEqual(filteredRooms, expectedRooms) {
-		t.Fatalf("Expected %v, got %v", expectedRooms, filteredRooms)
-	}
+func (e *failingEvents) PublishRoomDeleted(roomID string) error {
+	e.calls++
+	return errors.New("broker unreachable")
 }
 
-func TestSortRooms(t *testing.T) {
-	svc := setup()
+func TestDeleteRoomKeepsRoomWhenEventFails(t *testing.T) {
+	events := &failingEvents{}
+	svc := service.NewRoomService(repository.NewMockRoomRepository(), events)
 
-	rooms := []*model.Room{
-		{ID: "2", Name: "Room 2", Description: "Description 2", Available: false},
-		{ID: "1", Name: "Room 1", Description: "Description 1", Available: true},
-	}
+	svc.CreateRoom(&model.Room{ID: "1", Name: "Room 1", Available: true})
 
-	for _, room := range rooms {
-		svc.CreateRoom(room)
+	err := svc.DeleteRoom("1")
+	if !errors.Is(err, service.ErrEventNotPublished) {
+		t.Fatalf("Expected ErrEventNotPublished, got %v", err)
 	}
-
-	compare := func(a, b *model.Room) bool {
-		return a.Name < b.Name
+	if events.calls < 2 {
+		t.Errorf("Expected publishing to be retried, got %d attempts", events.calls)
 	}
-
-	sortedRooms, err := svc.SortRooms(compare)
-	if err != nil {
-		t.Fatalf("Expected no error, got %v", err)
+	if _, err := svc.GetRoomByID("1"); err != nil {
+		t.Errorf("Expected the room to be kept, got %v", err)
 	}
 
-	expectedRooms := []*model.Room{rooms[1], rooms[0]}
-	if !reflect.DeepEqual(sortedRooms, expectedRooms) {
-		t.Fatalf("Expected %v, got %v", expectedRooms, sortedRooms)
+	if err := svc.DeleteRoom("2"); !errors.Is(err, repository.ErrRoomNotFound) {
+		t.Errorf("Expected ErrRoomNotFound for an unknown room, got %v", err)
 	}
 }
 
-func TestPaginateRooms(t *testing.T) {
+func TestGetAllRooms(t *testing.T) {
 	svc := setup()
 
 	rooms := []*model.Room{
 		{ID: "1", Name: "Room 1", Description: "Description 1", Available: true},
 		{ID: "2", Name: "Room 2", Description: "Description 2", Available: false},
-		{ID: "3", Name: "Room 3", Description: "Description 3", Available: true},
-		{ID: "4", Name: "Room 4", Description: "Description 4", Available: false},
 	}
 
 	for _, room := range rooms {
 		svc.CreateRoom(room)
 	}
 
-	paginatedRooms, err := svc.PaginateRooms(1, 2)
+	storedRooms, err := svc.GetAllRooms()
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
-
-	expectedRooms := []*model.Room{rooms[0], rooms[1]}
-	if !reflect.DeepEqual(paginatedRooms, expectedRooms) {
-		t.Fatalf("Expected %v, got %v", expectedRooms, paginatedRooms)
+	if !reflect.DeepEqual(storedRooms, rooms) {
+		t.Fatalf("Expected %v, got %v", rooms, storedRooms)
 	}
 }
 
-func TestPaginateRooms_Page2(t *testing.T) {
+func TestListRooms(t *testing.T) {
 	svc := setup()
 
 	rooms := []*model.Room{
-		{ID: "1", Name: "Room 1", Description: "Description 1", Available: true},
-		{ID: "2", Name: "Room 2", Description: "Description 2", Available: false},
-		{ID: "3", Name: "Room 3", Description: "Description 3", Available: true},
-		{ID: "4", Name: "Room 4", Description: "Description 4", Available: false},
+		{ID: "1", Name: "Blue", Description: "Quiet, faces the garden", Available: true, Capacity: 2, Type: model.RoomTypeDouble,
+			BaseRate: 12000, Currency: "EUR", Amenities: []string{"wifi", "minibar"}},
+		{ID: "2", Name: "Green", Available: true, Capacity: 4, Type: model.RoomTypeFamily, BaseRate: 18000, Currency: "EUR",
+			Amenities: []string{"WiFi"}, Accessibility: model.Accessibility{Wheelchair: true, StepFree: true}},
+		{ID: "3", Name: "Red", Available: false, Capacity: 4, Type: model.RoomTypeSuite, BaseRate: 30000, Currency: "EUR",
+			Amenities: []string{"wifi"}},
+		{ID: "4", Name: "Amber", Description: "Garden view", Available: true, Capacity: 1, Type: model.RoomTypeSingle,
+			BaseRate: 8000, Currency: "EUR"},
 	}
-
 	for _, room := range rooms {
-		svc.CreateRoom(room)
-	}
-
-	paginatedRooms, err := svc.PaginateRooms(2, 2)
-	if err != nil {
-		t.Fatalf("Expected no error, got %v", err)
-	}
-
-	expectedRooms := []*model.Room{rooms[2], rooms[3]}
-	if !reflect.DeepEqual(paginatedRooms, expectedRooms) {
-		t.Fatalf("Expected %v, got %v", expectedRooms, paginatedRooms)
+		if err := svc.CreateRoom(room); err != nil {
+			t.Fatalf("CreateRoom(%s) error = %v", room.ID, err)
+		}
+	}
+
+	available := true
+	maxRate := int64(20000)
+	tests := []struct {
+		name  string
+		query service.RoomQuery
+		want  []string
+	}{
+		{"default sort by name", service.RoomQuery{}, []string{"4", "1", "2", "3"}},
+		{"available by capacity", service.RoomQuery{Available: &available, Sort: "-capacity"}, []string{"2", "1", "4"}},
+		{"search names and descriptions", service.RoomQuery{Search: "GARDEN"}, []string{"4", "1"}},
+		{"amenities ignore case", service.RoomQuery{Amenities: []string{"wifi"}, MaxRate: &maxRate}, []string{"1", "2"}},
+		{"accessibility", service.RoomQuery{Accessibility: []string{"wheelchair"}}, []string{"2"}},
+		{"types and capacity", service.RoomQuery{Types: []model.RoomType{model.RoomTypeFamily, model.RoomTypeSuite}, MinCapacity: 3,
+			Sort: "-base_rate"}, []string{"3", "2"}},
+		{"several sort keys", service.RoomQuery{Sort: "-capacity,base_rate"}, []string{"2", "3", "1", "4"}},
+		{"ties broken by id", service.RoomQuery{Sort: "capacity", Page: 2, PageSize: 2}, []string{"2", "3"}},
+	}
+
+	for _, tt := range tests {
+		got, _, err := svc.ListRooms(tt.query)
+		if err != nil {
+			t.Errorf("%s: ListRooms() error = %v", tt.name, err)
+			continue
+		}
+		var ids []string
+		for _, room := range got {
+			ids = append(ids, room.ID)
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("%s: ListRooms() = %v, want %v", tt.name, ids, tt.want)
+		}
 	}
 }
 
-func TestSortAndPaginateRooms(t *testing.T) {
+func TestListRoomsMetadata(t *testing.T) {
 	svc := setup()
-
-	rooms := []*model.Room{
-		{ID: "3", Name: "Room 3", Description: "Description 3", Available: true},
-		{ID: "1", Name: "Room 1", Description: "Description 1", Available: true},
-		{ID: "4", Name: "Room 4", Description: "Description 4", Available: false},
-		{ID: "2", Name: "Room 2", Description: "Description 2", Available: false},
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		svc.CreateRoom(&model.Room{ID: id, Name: "Room " + id})
 	}
 
-	for _, room := range rooms {
-		svc.CreateRoom(room)
+	_, metadata, err := svc.ListRooms(service.RoomQuery{Page: 2, PageSize: 2})
+	if err != nil {
+		t.Fatalf("ListRooms() error = %v", err)
 	}
-
-	compare := func(a, b *model.Room) bool {
-		return a.Name < b.Name
+	want := service.Metadata{CurrentPage: 2, PageSize: 2, FirstPage: 1, LastPage: 3, TotalRecords: 5}
+	if metadata != want {
+		t.Errorf("metadata = %+v, want %+v", metadata, want)
 	}
 
-	sortedRooms, err := svc.SortRooms(compare)
-	if err != nil {
-		t.Fatalf("Expected no error, got %v", err)
+	if _, metadata, _ = svc.ListRooms(service.RoomQuery{Search: "penthouse"}); metadata != (service.Metadata{}) {
+		t.Errorf("metadata without matches = %+v, want it empty", metadata)
 	}
+}
 
-	paginatedRooms, err := svc.PaginateRooms(1, 2)
-	if err != nil {
-		t.Fatalf("Expected no error, got %v", err)
+func TestListRoomsInvalidQuery(t *testing.T) {
+	svc := setup()
+
+	minRate, maxRate := int64(20000), int64(10000)
+	tests := []struct {
+		name  string
+		query service.RoomQuery
+		field string
+	}{
+		{"unknown sort", service.RoomQuery{Sort: "price"}, "sort"},
+		{"duplicate sort", service.RoomQuery{Sort: "name,-name"}, "sort"},
+		{"page size too large", service.RoomQuery{PageSize: service.MaxPageSize + 1}, "page_size"},
+		{"negative page", service.RoomQuery{Page: -1}, "page"},
+		{"unknown type", service.RoomQuery{Types: []model.RoomType{"penthouse"}}, "type"},
+		{"unknown flag", service.RoomQuery{Accessibility: []string{"elevator"}}, "accessibility"},
+		{"negative capacity", service.RoomQuery{MinCapacity: -1}, "min_capacity"},
+		{"rates out of order", service.RoomQuery{MinRate: &minRate, MaxRate: &maxRate}, "min_rate"},
+	}
+
+	for _, tt := range tests {
+		_, _, err := svc.ListRooms(tt.query)
+
+		var validationErr *service.ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Errorf("%s: expected a validation error, got %v", tt.name, err)
+			continue
+		}
+		if _, ok := validationErr.Errors[tt.field]; !ok {
+			t.Errorf("%s: expected an error for %s, got %v", tt.name, tt.field, validationErr.Errors)
+		}
+		if !errors.Is(err, service.ErrInvalidQuery) {
+			t.Errorf("%s: expected the error to wrap ErrInvalidQuery", tt.name)
+		}
 	}
+}
+
+func TestCreateRoomValidatesCatalogue(t *testing.T) {
+	svc := setup()
 
-	expectedRooms := []*model.Room{sortedRooms[0], sortedRooms[1]}
-	if !reflect.DeepEqual(paginatedRooms, expectedRooms) {
-		t.Fatalf("Expected %v, got %v", expectedRooms, paginatedRooms)
+	rooms := map[string]*model.Room{
+		"missing id":        {Name: "Blue"},
+		"negative capacity": {ID: "1", Capacity: -2},
+		"unknown type":      {ID: "1", Type: "penthouse"},
+		"bad currency":      {ID: "1", BaseRate: 100, Currency: "euro"},
+		"rate without code": {ID: "1", BaseRate: 100},
+		"blank amenity":     {ID: "1", Amenities: []string{"wifi", " "}},
+	}
+	for name, room := range rooms {
+		if err := svc.CreateRoom(room); !errors.Is(err, service.ErrInvalidRoom) {
+			t.Errorf("%s: CreateRoom() error = %v, want ErrInvalidRoom", name, err)
+		}
 	}
 }